@@ -0,0 +1,502 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
+type AccountDeletionResponse struct {
+	Message             string     `json:"message" example:"Account scheduled for deletion"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+}
+
+type OwnedProjectSummary struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title" example:"Protein Folding"`
+}
+
+type LedGroupSummary struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name" example:"Computational Biology Lab"`
+}
+
+type AccountDeletionConflictResponse struct {
+	Error    string                `json:"error" example:"Transfer or delete the projects you own and make another member PI of the groups you lead before deleting your account"`
+	Projects []OwnedProjectSummary `json:"projects"`
+	Groups   []LedGroupSummary     `json:"groups"`
+}
+
+// files of a data export
+type exportAccount struct {
+	ID                  uint               `json:"id"`
+	Email               string             `json:"email"`
+	CreatedAt           time.Time          `json:"created_at"`
+	DeletionScheduledAt *time.Time         `json:"deletion_scheduled_at"`
+	Profile             models.UserProfile `json:"profile"`
+}
+
+type exportCollaboration struct {
+	ProjectID    uint                      `json:"project_id"`
+	ProjectTitle string                    `json:"project_title"`
+	Role         string                    `json:"role"`
+	Status       models.CollaboratorStatus `json:"status"`
+	JoinedAt     time.Time                 `json:"joined_at"`
+	LeftAt       *time.Time                `json:"left_at"`
+}
+
+type exportInvitations struct {
+	Sent     []models.Invitation `json:"sent"`
+	Received []models.Invitation `json:"received"`
+}
+
+type exportConversation struct {
+	ID             uint             `json:"id"`
+	Title          string           `json:"title"`
+	IsGroup        bool             `json:"is_group"`
+	ParticipantIDs []uint           `json:"participant_ids"`
+	Messages       []models.Message `json:"messages"` // oldest first
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"password123"`
+	NewPassword     string `json:"new_password" binding:"required" example:"a long unusual passphrase"`
+}
+
+type ChangePasswordResponse struct {
+	Message string `json:"message" example:"Password changed successfully"`
+	// replaces the token used for the request, which is revoked along with every other session
+	Token string `json:"token"`
+}
+
+// ChangePassword godoc
+// @Summary      Change your password
+// @Description  Changes the authenticated user's password after verifying the current one. The new password must be at least 8 characters long (configurable), differ from your email and not appear in the breached password list. Every other session is ended and previously issued tokens are revoked; use the returned token from now on.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ChangePasswordRequest true "Current and new password"
+// @Success      200 {object} ChangePasswordResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/password [post]
+func ChangePassword(c *gin.Context) {
+	var request ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, utils.InferUserID(c)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+	if !utils.CheckPassword(user.Password, request.CurrentPassword) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Incorrect password"})
+		return
+	}
+	if request.NewPassword == request.CurrentPassword {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "New password must differ from the current one"})
+		return
+	}
+	if err := utils.ValidatePassword(request.NewPassword, user.Email); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(request.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to hash password"})
+		return
+	}
+	sessionID := c.GetUint(utils.SessionIDKey)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.SetPassword(tx, user.ID, hashedPassword, sessionID, time.Now()); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{Action: models.AuditPasswordChanged, TargetType: "user", TargetID: user.ID})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to change password"})
+		return
+	}
+	if err := services.SendPasswordChangedEmail(user); err != nil {
+		log.Println("Failed to send password changed email:", err)
+	}
+
+	// keep the current client signed in
+	token, err := utils.GenerateSessionJWT(user.ID, user.Email, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ChangePasswordResponse{Message: "Password changed successfully", Token: token})
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email" example:"jane.doe@ufl.edu"`
+	Password string `json:"password" binding:"required" example:"password123"`
+}
+
+// ChangeEmail godoc
+// @Summary      Change your email address
+// @Description  Starts changing the authenticated user's email address. A confirmation link is sent to the new address and a notice to the current one. The current address keeps working until the change is confirmed.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ChangeEmailRequest true "New email and current password"
+// @Success      202 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/email [post]
+func ChangeEmail(c *gin.Context) {
+	var request ChangeEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, utils.InferUserID(c)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+	if !utils.CheckPassword(user.Password, request.Password) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Incorrect password"})
+		return
+	}
+	if request.NewEmail == user.Email {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "This is already your email address"})
+		return
+	}
+	if services.EmailInUse(database.DB, request.NewEmail) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email already registered"})
+		return
+	}
+
+	var token string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if token, err = services.IssueEmailChange(tx, user.ID, request.NewEmail, time.Now()); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditEmailChangeRequested, TargetType: "user", TargetID: user.ID,
+			After: gin.H{"new_email": request.NewEmail},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to change email address"})
+		return
+	}
+	if err := services.SendEmailChangeConfirmation(request.NewEmail, token); err != nil {
+		log.Println("Failed to send email change confirmation:", err)
+	}
+	if err := services.SendEmailChangeNotice(user.Email, request.NewEmail); err != nil {
+		log.Println("Failed to send email change notice:", err)
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "Check your new email address for a confirmation link"})
+}
+
+// RequestEmailVerification godoc
+// @Summary      Verify your email address
+// @Description  Emails a verification link to the authenticated user's address. Verified addresses that belong to a registered institution show a verified membership badge on the profile.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/verify-email [post]
+func RequestEmailVerification(c *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, utils.InferUserID(c)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Your email address is already verified"})
+		return
+	}
+
+	if err := services.SendEmailVerification(user, services.EmailVerificationToken(user, time.Now())); err != nil {
+		log.Println("Failed to send email verification:", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "Check your email for a verification link"})
+}
+
+// DeleteAccount godoc
+// @Summary      Delete your account
+// @Description  Schedules the authenticated user's account for deletion after a grace period (30 days by default) during which it can be restored. Projects you own must be transferred or deleted first, and groups you are the only PI of need another PI. Once the grace period ends your profile and personal data are removed, and content you contributed to projects is kept without your name.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body DeleteAccountRequest true "Current password"
+// @Success      202 {object} AccountDeletionResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      409 {object} AccountDeletionConflictResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me [delete]
+func DeleteAccount(c *gin.Context) {
+	var request DeleteAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, utils.InferUserID(c)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+	if !utils.CheckPassword(user.Password, request.Password) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Incorrect password"})
+		return
+	}
+	if user.DeletionScheduledAt != nil {
+		c.JSON(http.StatusAccepted, AccountDeletionResponse{Message: "Account already scheduled for deletion", DeletionScheduledAt: user.DeletionScheduledAt})
+		return
+	}
+
+	owned, err := services.OwnedProjects(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to schedule account deletion"})
+		return
+	}
+	led, err := services.LedGroups(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to schedule account deletion"})
+		return
+	}
+	if len(owned) > 0 || len(led) > 0 {
+		response := AccountDeletionConflictResponse{
+			Error:    "Transfer or delete the projects you own and make another member PI of the groups you lead before deleting your account",
+			Projects: make([]OwnedProjectSummary, len(owned)),
+			Groups:   make([]LedGroupSummary, len(led)),
+		}
+		for i, project := range owned {
+			response.Projects[i] = OwnedProjectSummary{ID: project.ID, Title: project.Title}
+		}
+		for i, group := range led {
+			response.Groups[i] = LedGroupSummary{ID: group.ID, Name: group.Name}
+		}
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	due := time.Now().Add(services.AccountDeletionGracePeriod())
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("deletion_scheduled_at", due).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAccountDeletionScheduled, TargetType: "user", TargetID: user.ID,
+			After: gin.H{"deletion_scheduled_at": due},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to schedule account deletion"})
+		return
+	}
+	if err := services.SendAccountDeletionEmail(user, due); err != nil {
+		log.Println("Failed to send account deletion email:", err)
+	}
+
+	c.JSON(http.StatusAccepted, AccountDeletionResponse{Message: "Account scheduled for deletion", DeletionScheduledAt: &due})
+}
+
+// CancelAccountDeletion godoc
+// @Summary      Cancel account deletion
+// @Description  Restores an account scheduled for deletion while its grace period is still running.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} AccountDeletionResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/cancel-deletion [post]
+func CancelAccountDeletion(c *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, utils.InferUserID(c)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+	if user.DeletionScheduledAt == nil {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Account is not scheduled for deletion"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		before := user.DeletionScheduledAt
+		if err := tx.Model(&user).Update("deletion_scheduled_at", nil).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAccountDeletionCancelled, TargetType: "user", TargetID: user.ID,
+			Before: gin.H{"deletion_scheduled_at": before},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to cancel account deletion"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AccountDeletionResponse{Message: "Account deletion cancelled"})
+}
+
+// ExportAccountData godoc
+// @Summary      Export your data
+// @Description  Downloads a ZIP archive of JSON files with the authenticated user's account and profile, owned projects, collaborations, sent and received invitations, and conversations with their messages.
+// @Tags         Users
+// @Produce      application/zip
+// @Security     BearerAuth
+// @Success      200 {file} file
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/export [get]
+func ExportAccountData(c *gin.Context) {
+	var user models.User
+	if err := database.DB.Preload("Profile").First(&user, utils.InferUserID(c)).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+
+	files, err := accountExportFiles(database.DB, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export data"})
+		return
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export data"})
+			return
+		}
+		entry, err := writer.Create(file.name)
+		if err == nil {
+			_, err = entry.Write(data)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export data"})
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export data"})
+		return
+	}
+
+	// exports aren't handed out unless they show up in the audit log
+	if err := recordAudit(c, database.DB, services.AuditEntry{Action: models.AuditDataExported, TargetType: "user", TargetID: user.ID}); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export data"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="the-grid-export-%d.zip"`, user.ID))
+	c.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+type exportFile struct {
+	name    string
+	content interface{}
+}
+
+// accountExportFiles gathers the data of a user's export, one entry per JSON file
+func accountExportFiles(db *gorm.DB, user models.User) ([]exportFile, error) {
+	var projects []models.Project
+	if err := db.Where("owner_id = ?", user.ID).Order("id").Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	var collaborators []models.Collaborator
+	if err := db.Where("user_id = ?", user.ID).Order("id").Find(&collaborators).Error; err != nil {
+		return nil, err
+	}
+	collaborations := make([]exportCollaboration, 0, len(collaborators))
+	for _, collaborator := range collaborators {
+		var project models.Project
+		if err := db.Unscoped().Select("id", "title").First(&project, collaborator.ProjectID).Error; err != nil {
+			continue
+		}
+		joinedAt := collaborator.CreatedAt
+		if collaborator.JoinedAt != nil {
+			joinedAt = *collaborator.JoinedAt
+		}
+		collaborations = append(collaborations, exportCollaboration{
+			ProjectID: project.ID, ProjectTitle: project.Title, Role: collaborator.Role, Status: collaborator.Status,
+			JoinedAt: joinedAt, LeftAt: collaborator.LeftAt,
+		})
+	}
+
+	invitations := exportInvitations{Sent: []models.Invitation{}, Received: []models.Invitation{}}
+	if err := db.Where("inviter_id = ?", user.ID).Order("id").Find(&invitations.Sent).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("email = ?", user.Email).Order("id").Find(&invitations.Received).Error; err != nil {
+		return nil, err
+	}
+
+	var conversations []models.Conversation
+	err := db.Preload("Participants").
+		Where("id IN (?)", db.Model(&models.ConversationParticipant{}).Select("conversation_id").Where("user_id = ?", user.ID)).
+		Order("id").Find(&conversations).Error
+	if err != nil {
+		return nil, err
+	}
+	exported := make([]exportConversation, len(conversations))
+	for i, conversation := range conversations {
+		exported[i] = exportConversation{ID: conversation.ID, Title: conversation.Title, IsGroup: conversation.IsGroup, ParticipantIDs: []uint{}}
+		for _, participant := range conversation.Participants {
+			exported[i].ParticipantIDs = append(exported[i].ParticipantIDs, participant.UserID)
+		}
+		if err := db.Where("conversation_id = ?", conversation.ID).Order("id").Find(&exported[i].Messages).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return []exportFile{
+		{"profile.json", exportAccount{
+			ID: user.ID, Email: user.Email, CreatedAt: user.CreatedAt, DeletionScheduledAt: user.DeletionScheduledAt, Profile: user.Profile,
+		}},
+		{"projects.json", projects},
+		{"collaborations.json", collaborations},
+		{"invitations.json", invitations},
+		{"messages.json", exported},
+	}, nil
+}
@@ -0,0 +1,281 @@
+package controllers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/email"
+	"backend/middleware"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+)
+
+type accountFixture struct {
+	router    *gin.Engine
+	mailer    *recordingMailer
+	user      models.User
+	colleague models.User
+	project   models.Project
+	token     string
+}
+
+func setupAccountTest(t *testing.T) accountFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.NotificationPreference{}, &models.PasswordResetToken{}, &models.EmailChangeRequest{}, &models.Task{}, &models.DiscussionThread{}, &models.Comment{},
+		&models.CommentEdit{}, &models.CommentMention{}, &models.Conversation{}, &models.ConversationParticipant{}, &models.Message{}, &models.Session{}, &models.LoginAttempt{})
+	for _, table := range []string{"login_attempts", "sessions", "messages", "conversation_participants", "conversations", "comment_mentions", "comment_edits", "comments", "discussion_threads", "tasks", "password_reset_tokens", "email_change_requests", "notification_preferences"} {
+		database.DB.Exec("DELETE FROM " + table)
+	}
+
+	mailer := &recordingMailer{}
+	previous := email.Sender
+	email.Sender = mailer
+	t.Cleanup(func() { email.Sender = previous })
+
+	hashed, _ := utils.HashPassword("password")
+	user := models.User{Email: "leaving@example.com", Password: hashed}
+	colleague := models.User{Email: "staying@example.com", Password: hashed}
+	database.DB.Create(&user)
+	database.DB.Create(&colleague)
+	database.DB.Create(&models.UserProfile{UserID: user.ID, FullName: "Leaving User", Location: "Gainesville"})
+
+	project := models.Project{Title: "Owned Project", OwnerID: user.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: user.ID, Role: "owner"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: colleague.ID, Role: "programmer"})
+	token, _ := utils.GenerateJWT(user.ID, user.Email)
+
+	router := gin.Default()
+	router.DELETE("/users/me", middleware.AuthRequired(), controllers.DeleteAccount)
+	router.POST("/users/me/email", middleware.AuthRequired(), controllers.ChangeEmail)
+	router.POST("/users/me/password", middleware.AuthRequired(), controllers.ChangePassword)
+	router.POST("/auth/confirm-email", controllers.ConfirmEmailChange)
+	router.POST("/users/me/cancel-deletion", middleware.AuthRequired(), controllers.CancelAccountDeletion)
+	router.GET("/users/me/export", middleware.AuthRequired(), controllers.ExportAccountData)
+	router.POST("/projects/:id/transfer", middleware.AuthRequired(), controllers.TransferProject)
+	return accountFixture{router: router, mailer: mailer, user: user, colleague: colleague, project: project, token: token}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	f := setupAccountTest(t)
+
+	w := projectRequest(f.router, "DELETE", "/users/me", f.token, map[string]string{"password": "wrong"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// owned projects have to be handed over first
+	w = projectRequest(f.router, "DELETE", "/users/me", f.token, map[string]string{"password": "password"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "Owned Project")
+
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/transfer", f.project.ID), f.token, map[string]uint{"user_id": f.colleague.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "DELETE", "/users/me", f.token, map[string]string{"password": "password"})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response controllers.AccountDeletionResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NotNil(t, response.DeletionScheduledAt)
+	assert.Len(t, f.mailer.sent, 1)
+
+	// the account can be restored during the grace period
+	w = projectRequest(f.router, "POST", "/users/me/cancel-deletion", f.token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	worker := services.NewAccountDeletionWorker(database.DB)
+	assert.NoError(t, worker.PurgeDue(context.Background(), time.Now().Add(services.AccountDeletionGracePeriod()+time.Hour)))
+	var restored models.User
+	assert.NoError(t, database.DB.First(&restored, f.user.ID).Error)
+	assert.Equal(t, f.user.Email, restored.Email)
+
+	// authored content stays, mentions and personal data don't
+	thread := models.DiscussionThread{ProjectID: f.project.ID, AuthorID: f.user.ID, Title: "Notes"}
+	database.DB.Create(&thread)
+	comment := models.Comment{ThreadID: thread.ID, AuthorID: f.colleague.ID, Body: "thanks @leaving@example.com"}
+	database.DB.Create(&comment)
+	database.DB.Create(&models.CommentMention{CommentID: comment.ID, UserID: f.user.ID})
+
+	projectRequest(f.router, "DELETE", "/users/me", f.token, map[string]string{"password": "password"})
+	assert.NoError(t, worker.PurgeDue(context.Background(), time.Now().Add(services.AccountDeletionGracePeriod()+time.Hour)))
+
+	var deleted models.User
+	assert.NoError(t, database.DB.Unscoped().First(&deleted, f.user.ID).Error)
+	assert.True(t, deleted.DeletedAt.Valid)
+	assert.NotEqual(t, f.user.Email, deleted.Email)
+
+	var profiles, collaborations int64
+	database.DB.Model(&models.UserProfile{}).Where("user_id = ?", f.user.ID).Count(&profiles)
+	database.DB.Model(&models.Collaborator{}).Where("user_id = ?", f.user.ID).Count(&collaborations)
+	assert.Zero(t, profiles)
+	assert.Zero(t, collaborations)
+
+	var remaining models.DiscussionThread
+	assert.NoError(t, database.DB.First(&remaining, thread.ID).Error)
+	var mentioned models.Comment
+	database.DB.First(&mentioned, comment.ID)
+	assert.Equal(t, "thanks @deleted-user", mentioned.Body)
+
+	// the old token is no longer accepted
+	w = projectRequest(f.router, "GET", "/users/me/export", f.token, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestDeleteAccountKeepsGroupPI(t *testing.T) {
+	f := setupAccountTest(t)
+	database.DB.Delete(&f.project)
+	group := models.Group{Name: "Leaving Lab"}
+	database.DB.Create(&group)
+	database.DB.Create(&models.GroupMember{GroupID: group.ID, UserID: f.user.ID, Role: models.GroupRolePI})
+	colleague := models.GroupMember{GroupID: group.ID, UserID: f.colleague.ID, Role: models.GroupRoleMember}
+	database.DB.Create(&colleague)
+
+	// the only PI of a group has to hand it over first
+	w := projectRequest(f.router, "DELETE", "/users/me", f.token, map[string]string{"password": "password"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict controllers.AccountDeletionConflictResponse
+	json.Unmarshal(w.Body.Bytes(), &conflict)
+	assert.Empty(t, conflict.Projects)
+	assert.Equal(t, []controllers.LedGroupSummary{{ID: group.ID, Name: "Leaving Lab"}}, conflict.Groups)
+
+	// accounts that became the only PI during the grace period are kept as well
+	due := time.Now()
+	database.DB.Model(&f.user).Update("deletion_scheduled_at", due)
+	worker := services.NewAccountDeletionWorker(database.DB)
+	assert.NoError(t, worker.PurgeDue(context.Background(), due.Add(time.Hour)))
+	var kept models.GroupMember
+	assert.NoError(t, database.DB.Where("group_id = ? AND user_id = ?", group.ID, f.user.ID).First(&kept).Error)
+
+	database.DB.Model(&colleague).Update("role", models.GroupRolePI)
+	assert.NoError(t, worker.PurgeDue(context.Background(), due.Add(time.Hour)))
+	var deleted models.User
+	assert.NoError(t, database.DB.Unscoped().First(&deleted, f.user.ID).Error)
+	assert.True(t, deleted.DeletedAt.Valid)
+}
+
+func TestExportAccountData(t *testing.T) {
+	f := setupAccountTest(t)
+	database.DB.Create(&models.Invitation{ProjectID: f.project.ID, InviterID: f.colleague.ID, Email: f.user.Email, Role: "editor", Status: models.InvitationStatusPending})
+	conversation := models.Conversation{CreatorID: f.colleague.ID, Participants: []models.ConversationParticipant{{UserID: f.user.ID}, {UserID: f.colleague.ID}}}
+	database.DB.Create(&conversation)
+	database.DB.Create(&models.Message{ConversationID: conversation.ID, SenderID: f.colleague.ID, Body: "Welcome aboard"})
+
+	w := projectRequest(f.router, "GET", "/users/me/export", f.token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, _ := file.Open()
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		files[file.Name] = string(content)
+	}
+
+	assert.Len(t, files, 5)
+	assert.Contains(t, files["profile.json"], "Leaving User")
+	assert.NotContains(t, files["profile.json"], "password")
+	assert.Contains(t, files["projects.json"], "Owned Project")
+	assert.Contains(t, files["collaborations.json"], `"role": "owner"`)
+	assert.Contains(t, files["invitations.json"], `"role": "editor"`)
+	assert.Contains(t, files["messages.json"], "Welcome aboard")
+}
+
+func TestChangeEmail(t *testing.T) {
+	f := setupAccountTest(t)
+	database.DB.Create(&models.Invitation{ProjectID: f.project.ID, InviterID: f.colleague.ID, Email: f.user.Email, Role: "editor", Status: models.InvitationStatusPending})
+
+	w := projectRequest(f.router, "POST", "/users/me/email", f.token, map[string]string{"new_email": "moved@example.com", "password": "wrong"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", "/users/me/email", f.token, map[string]string{"new_email": f.colleague.Email, "password": "password"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "POST", "/users/me/email", f.token, map[string]string{"new_email": "moved@example.com", "password": "password"})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// the new address gets the confirmation link, the old one a notice
+	assert.Len(t, f.mailer.sent, 2)
+	assert.Equal(t, "moved@example.com", f.mailer.sent[0].To)
+	assert.Equal(t, f.user.Email, f.mailer.sent[1].To)
+	token := regexp.MustCompile(`token=([^\s]+)`).FindStringSubmatch(f.mailer.sent[0].Text)[1]
+	token, _ = url.QueryUnescape(token)
+
+	// nothing changes until the change is confirmed
+	var user models.User
+	database.DB.First(&user, f.user.ID)
+	assert.Equal(t, f.user.Email, user.Email)
+
+	w = projectRequest(f.router, "POST", "/auth/confirm-email", "", map[string]string{"token": token})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var changed models.User
+	database.DB.First(&changed, f.user.ID)
+	assert.Equal(t, "moved@example.com", changed.Email)
+	var invitation models.Invitation
+	database.DB.First(&invitation)
+	assert.Equal(t, "moved@example.com", invitation.Email)
+
+	// links are single use
+	w = projectRequest(f.router, "POST", "/auth/confirm-email", "", map[string]string{"token": token})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestChangePassword(t *testing.T) {
+	f := setupAccountTest(t)
+	database.DB.Create(&models.PasswordResetToken{UserID: f.user.ID, TokenHash: "pending", ExpiresAt: time.Now().Add(time.Hour)})
+
+	// a session that logged in before the change
+	claims := utils.Claims{
+		UserID: f.user.ID,
+		Email:  f.user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}
+	oldToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(utils.GetJWTKey())
+
+	w := projectRequest(f.router, "POST", "/users/me/password", oldToken, map[string]string{"current_password": "wrong", "new_password": "a long unusual passphrase"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	for _, rejected := range []string{"short", "password1", f.user.Email, "password"} {
+		w = projectRequest(f.router, "POST", "/users/me/password", oldToken, map[string]string{"current_password": "password", "new_password": rejected})
+		assert.Equal(t, http.StatusBadRequest, w.Code, rejected)
+	}
+
+	w = projectRequest(f.router, "POST", "/users/me/password", oldToken, map[string]string{"current_password": "password", "new_password": "a long unusual passphrase"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response controllers.ChangePasswordResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NotEmpty(t, response.Token)
+	assert.Len(t, f.mailer.sent, 1)
+
+	var user models.User
+	database.DB.First(&user, f.user.ID)
+	assert.True(t, utils.CheckPassword(user.Password, "a long unusual passphrase"))
+	assert.NotNil(t, user.PasswordChangedAt)
+	var resets int64
+	database.DB.Model(&models.PasswordResetToken{}).Where("user_id = ?", f.user.ID).Count(&resets)
+	assert.Zero(t, resets)
+
+	// earlier sessions are signed out, the returned token keeps working
+	w = projectRequest(f.router, "GET", "/users/me/export", oldToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = projectRequest(f.router, "GET", "/users/me/export", response.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AdminUserDetail struct {
+	ID                    uint       `json:"id"`
+	Email                 string     `json:"email" example:"jane@example.com"`
+	FullName              string     `json:"full_name" example:"Jane Doe"`
+	IsAdmin               bool       `json:"is_admin"`
+	DisabledAt            *time.Time `json:"disabled_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+type AdminUserListResponse struct {
+	Users    []AdminUserDetail `json:"users"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Total    int64             `json:"total"`
+}
+
+type AdminModerationRequest struct {
+	Reason string `json:"reason" example:"Spam"`
+}
+
+type UserStats struct {
+	Total           int64 `json:"total"`
+	Active          int64 `json:"active"`
+	Disabled        int64 `json:"disabled"`
+	Admins          int64 `json:"admins"`
+	RegisteredLast7 int64 `json:"registered_last_7_days"`
+}
+
+type ProjectStats struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+	Public   int64            `json:"public"`
+}
+
+type PlatformStatsResponse struct {
+	Users        UserStats    `json:"users"`
+	Projects     ProjectStats `json:"projects"`
+	Tasks        int64        `json:"tasks"`
+	StorageBytes int64        `json:"storage_bytes"`
+}
+
+func toAdminUserDetail(user models.User) AdminUserDetail {
+	return AdminUserDetail{
+		ID:                    user.ID,
+		Email:                 user.Email,
+		FullName:              user.Profile.FullName,
+		IsAdmin:               user.IsAdmin,
+		DisabledAt:            user.DisabledAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+	}
+}
+
+// findModeratedUser loads the user targeted by an admin action, refusing actions on the acting admin's own account
+func findModeratedUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return user, false
+	}
+	if user.ID == utils.InferUserID(c) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Administrators cannot moderate their own account"})
+		return user, false
+	}
+	return user, true
+}
+
+// ListUsers godoc
+// @Summary      List and search users
+// @Description  Lists all accounts, newest first. Only administrators can list users.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q query string false "Search by email or full name"
+// @Param        status query string false "Only active or disabled accounts" Enums(active, disabled)
+// @Param        institution_id query int false "Only verified members of this institution"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Users per page (max 100)" default(20)
+// @Success      200 {object} AdminUserListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/users [get]
+func ListUsers(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.User{})
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR id IN (SELECT user_id FROM user_profiles WHERE LOWER(full_name) LIKE ?)", pattern, pattern)
+	}
+	if institution := c.Query("institution_id"); institution != "" {
+		institutionID, err := strconv.ParseUint(institution, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid institution ID"})
+			return
+		}
+		query = query.Where("id IN (SELECT user_id FROM user_profiles WHERE institution_id = ?)", institutionID)
+	}
+	switch c.Query("status") {
+	case "":
+	case "active":
+		query = query.Where("disabled_at IS NULL")
+	case "disabled":
+		query = query.Where("disabled_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Status must be active or disabled"})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch users"})
+		return
+	}
+
+	var users []models.User
+	if err := query.Preload("Profile").Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch users"})
+		return
+	}
+
+	response := AdminUserListResponse{Users: make([]AdminUserDetail, len(users)), Page: page, PageSize: pageSize, Total: total}
+	for i, user := range users {
+		response.Users[i] = toAdminUserDetail(user)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DisableUser godoc
+// @Summary      Disable an account
+// @Description  Blocks an account from logging in and revokes access for its existing tokens. Only administrators can disable accounts.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        request body AdminModerationRequest false "Reason recorded in the audit log"
+// @Success      200 {object} AdminUserDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/users/{id}/disable [post]
+func DisableUser(c *gin.Context) {
+	var request AdminModerationRequest
+	c.ShouldBindJSON(&request)
+
+	user, ok := findModeratedUser(c)
+	if !ok {
+		return
+	}
+
+	if user.DisabledAt == nil {
+		now := time.Now()
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Update("disabled_at", now).Error; err != nil {
+				return err
+			}
+			return recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditUserDisabled, TargetType: "user", TargetID: user.ID,
+				Before: gin.H{"disabled": false}, After: gin.H{"disabled": true, "reason": request.Reason},
+			})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to disable user"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, toAdminUserDetail(user))
+}
+
+// EnableUser godoc
+// @Summary      Re-enable an account
+// @Description  Lifts a previous suspension so the user can log in again. Only administrators can enable accounts.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Success      200 {object} AdminUserDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/users/{id}/enable [post]
+func EnableUser(c *gin.Context) {
+	user, ok := findModeratedUser(c)
+	if !ok {
+		return
+	}
+
+	if user.DisabledAt != nil {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Update("disabled_at", nil).Error; err != nil {
+				return err
+			}
+			return recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditUserEnabled, TargetType: "user", TargetID: user.ID,
+				Before: gin.H{"disabled": true}, After: gin.H{"disabled": false},
+			})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to enable user"})
+			return
+		}
+		user.DisabledAt = nil
+	}
+
+	c.JSON(http.StatusOK, toAdminUserDetail(user))
+}
+
+// ForcePasswordReset godoc
+// @Summary      Force a password reset
+// @Description  Requires the user to choose a new password before they can log in again and emails them a reset link. Existing tokens stop working until the password is reset. Only administrators can force a reset.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Success      200 {object} AdminUserDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/users/{id}/force-password-reset [post]
+func ForcePasswordReset(c *gin.Context) {
+	user, ok := findModeratedUser(c)
+	if !ok {
+		return
+	}
+
+	var token string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		var err error
+		if token, err = services.IssuePasswordReset(tx, user.ID, time.Now()); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{Action: models.AuditPasswordResetForced, TargetType: "user", TargetID: user.ID})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to force password reset"})
+		return
+	}
+
+	// the reset stays in force when the email fails, the administrator can trigger it again to resend the link
+	if err := services.SendPasswordResetEmail(user, token, "An administrator has asked you to choose a new password for your account."); err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Password reset required but the reset email could not be sent"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAdminUserDetail(user))
+}
+
+// AdminDeleteProject godoc
+// @Summary      Delete a project
+// @Description  Removes an abusive project together with its pending invitations and webhooks. Only administrators can delete projects.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body AdminModerationRequest false "Reason recorded in the audit log"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/projects/{id} [delete]
+func AdminDeleteProject(c *gin.Context) {
+	var request AdminModerationRequest
+	c.ShouldBindJSON(&request)
+
+	var project models.Project
+	if err := database.DB.First(&project, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := removeProject(tx, project); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectDeleted, TargetType: "project", TargetID: project.ID, ProjectID: auditProject(project.ID),
+			Before: gin.H{"title": project.Title, "owner_id": project.OwnerID}, After: gin.H{"reason": request.Reason},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Project deleted successfully"})
+}
+
+// GetPlatformStats godoc
+// @Summary      Get platform statistics
+// @Description  Returns user, project, task and storage totals. Only administrators can view platform statistics.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} PlatformStatsResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/stats [get]
+func GetPlatformStats(c *gin.Context) {
+	stats := PlatformStatsResponse{Projects: ProjectStats{ByStatus: map[string]int64{}}}
+
+	var statusCounts []struct {
+		Status string
+		Count  int64
+	}
+	users := database.DB.Model(&models.User{})
+	projects := database.DB.Model(&models.Project{})
+	err := errors.Join(
+		users.Session(&gorm.Session{}).Count(&stats.Users.Total).Error,
+		users.Session(&gorm.Session{}).Where("disabled_at IS NOT NULL").Count(&stats.Users.Disabled).Error,
+		users.Session(&gorm.Session{}).Where("is_admin = ?", true).Count(&stats.Users.Admins).Error,
+		users.Session(&gorm.Session{}).Where("created_at >= ?", time.Now().AddDate(0, 0, -7)).Count(&stats.Users.RegisteredLast7).Error,
+		projects.Session(&gorm.Session{}).Count(&stats.Projects.Total).Error,
+		projects.Session(&gorm.Session{}).Where("visibility = ?", models.ProjectVisibilityPublic).Count(&stats.Projects.Public).Error,
+		projects.Session(&gorm.Session{}).Select("status, COUNT(*) AS count").Group("status").Scan(&statusCounts).Error,
+		database.DB.Model(&models.Task{}).Count(&stats.Tasks).Error,
+		database.DB.Model(&models.Attachment{}).Select("COALESCE(SUM(size), 0)").Scan(&stats.StorageBytes).Error,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute statistics"})
+		return
+	}
+
+	stats.Users.Active = stats.Users.Total - stats.Users.Disabled
+	for _, row := range statusCounts {
+		stats.Projects.ByStatus[row.Status] = row.Count
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
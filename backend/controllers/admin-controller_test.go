@@ -0,0 +1,181 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/email"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type recordingMailer struct {
+	sent []email.Message
+}
+
+func (m *recordingMailer) Send(message email.Message) error {
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+type adminFixture struct {
+	router     *gin.Engine
+	mailer     *recordingMailer
+	admin      models.User
+	member     models.User
+	adminToken string
+	token      string
+}
+
+func setupAdminTest(t *testing.T) adminFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.PasswordResetToken{}, &models.Milestone{}, &models.Task{}, &models.Attachment{}, &models.Session{}, &models.LoginAttempt{})
+	database.DB.Exec("DELETE FROM password_reset_tokens")
+	database.DB.Exec("DELETE FROM tasks")
+	database.DB.Exec("DELETE FROM attachments")
+
+	mailer := &recordingMailer{}
+	previous := email.Sender
+	email.Sender = mailer
+	t.Cleanup(func() { email.Sender = previous })
+
+	hashed, _ := utils.HashPassword("password")
+	admin := models.User{Email: "admin@example.com", Password: hashed, IsAdmin: true}
+	member := models.User{Email: "member@example.com", Password: hashed}
+	database.DB.Create(&admin)
+	database.DB.Create(&member)
+	database.DB.Create(&models.UserProfile{UserID: member.ID, FullName: "Grace Hopper"})
+
+	adminToken, _ := utils.GenerateJWT(admin.ID, admin.Email)
+	token, _ := utils.GenerateJWT(member.ID, member.Email)
+
+	router := gin.Default()
+	router.POST("/auth/login", controllers.LoginUser)
+	router.POST("/auth/reset-password", controllers.ResetPassword)
+	router.GET("/notifications/unread-count", middleware.AuthRequired(), controllers.GetUnreadNotificationCount)
+	router.GET("/projects/:id", middleware.AuthRequired(), controllers.RetrieveProject)
+	adminGroup := router.Group("/admin", middleware.AuthRequired(), middleware.AdminOnly())
+	adminGroup.GET("/stats", controllers.GetPlatformStats)
+	adminGroup.GET("/users", controllers.ListUsers)
+	adminGroup.POST("/users/:id/disable", controllers.DisableUser)
+	adminGroup.POST("/users/:id/enable", controllers.EnableUser)
+	adminGroup.POST("/users/:id/force-password-reset", controllers.ForcePasswordReset)
+	adminGroup.DELETE("/projects/:id", controllers.AdminDeleteProject)
+
+	return adminFixture{router, mailer, admin, member, adminToken, token}
+}
+
+func login(router *gin.Engine, email, password string) int {
+	return projectRequest(router, "POST", "/auth/login", "", map[string]string{"email": email, "password": password}).Code
+}
+
+func TestAdminRoutesRequireAdmin(t *testing.T) {
+	f := setupAdminTest(t)
+
+	w := projectRequest(f.router, "GET", "/admin/users", f.token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "GET", "/admin/users?q=hopper", f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list controllers.AdminUserListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, "member@example.com", list.Users[0].Email)
+	assert.NotContains(t, w.Body.String(), "password\":\"")
+}
+
+func TestDisableAndEnableUser(t *testing.T) {
+	f := setupAdminTest(t)
+	userPath := fmt.Sprintf("/admin/users/%d", f.member.ID)
+
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/admin/users/%d/disable", f.admin.ID), f.adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "POST", userPath+"/disable", f.adminToken, map[string]string{"reason": "Spam"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// existing tokens stop working and logging in is refused
+	w = projectRequest(f.router, "GET", "/notifications/unread-count", f.token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, http.StatusForbidden, login(f.router, f.member.Email, "password"))
+
+	w = projectRequest(f.router, "GET", "/admin/users?status=disabled", f.adminToken, nil)
+	var list controllers.AdminUserListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, int64(1), list.Total)
+
+	w = projectRequest(f.router, "POST", userPath+"/enable", f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "GET", "/notifications/unread-count", f.token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	database.DB.Model(&models.AuditEvent{}).Where("target_id = ? AND action IN ?", f.member.ID, []models.AuditAction{models.AuditUserDisabled, models.AuditUserEnabled}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	f := setupAdminTest(t)
+
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/admin/users/%d/force-password-reset", f.member.ID), f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusForbidden, login(f.router, f.member.Email, "password"))
+
+	assert.Len(t, f.mailer.sent, 1)
+	assert.Equal(t, f.member.Email, f.mailer.sent[0].To)
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(f.mailer.sent[0].Text)
+	assert.Len(t, match, 2)
+	token, _ := url.QueryUnescape(match[1])
+
+	w = projectRequest(f.router, "POST", "/auth/reset-password", "", map[string]string{"token": "bogus", "password": "new password"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "POST", "/auth/reset-password", "", map[string]string{"token": token, "password": "new password"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusUnauthorized, login(f.router, f.member.Email, "password"))
+	assert.Equal(t, http.StatusOK, login(f.router, f.member.Email, "new password"))
+
+	// reset links are single use
+	w = projectRequest(f.router, "POST", "/auth/reset-password", "", map[string]string{"token": token, "password": "another password"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminDeleteProjectAndStats(t *testing.T) {
+	f := setupAdminTest(t)
+	project := models.Project{Title: "Spam", OwnerID: f.member.ID, Visibility: "public", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Invitation{ProjectID: project.ID, InviterID: f.member.ID, Email: "victim@example.com", Role: "programmer", Status: models.InvitationStatusPending})
+
+	w := projectRequest(f.router, "GET", "/admin/stats", f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats controllers.PlatformStatsResponse
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, int64(2), stats.Users.Total)
+	assert.Equal(t, int64(1), stats.Users.Admins)
+	assert.Equal(t, int64(1), stats.Projects.Total)
+	assert.Equal(t, int64(1), stats.Projects.ByStatus["open"])
+
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/admin/projects/%d", project.ID), f.adminToken, map[string]string{"reason": "Spam"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d", project.ID), f.token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var invitations int64
+	database.DB.Model(&models.Invitation{}).Where("project_id = ?", project.ID).Count(&invitations)
+	assert.Equal(t, int64(0), invitations)
+
+	w = projectRequest(f.router, "GET", "/admin/stats", f.adminToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, int64(0), stats.Projects.Total)
+}
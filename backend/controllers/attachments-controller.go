@@ -0,0 +1,396 @@
+package controllers
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/storage"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AttachmentDetail struct {
+	ID          uint      `json:"id"`
+	ProjectID   uint      `json:"project_id"`
+	UploaderID  uint      `json:"uploader_id"`
+	Filename    string    `json:"filename" example:"draft.pdf"`
+	Version     int       `json:"version" example:"1"`
+	ContentType string    `json:"content_type" example:"application/pdf"`
+	Size        int64     `json:"size" example:"52341"`
+	Checksum    string    `json:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AttachmentListResponse struct {
+	Attachments []AttachmentDetail `json:"attachments"`
+	UsedBytes   int64              `json:"used_bytes"`
+	QuotaBytes  int64              `json:"quota_bytes"`
+}
+
+type AttachmentUploadResponse struct {
+	Message    string           `json:"message" example:"File uploaded successfully"`
+	Attachment AttachmentDetail `json:"attachment"`
+}
+
+// maxAttachmentSize returns the largest accepted single upload in bytes
+func maxAttachmentSize() int64 {
+	return utils.GetEnvInt64("ATTACHMENT_MAX_SIZE_MB", 25) << 20
+}
+
+// projectStorageQuota returns the total number of bytes a project may store across all file versions
+func projectStorageQuota() int64 {
+	return utils.GetEnvInt64("PROJECT_STORAGE_QUOTA_MB", 100) << 20
+}
+
+// errQuotaExceeded signals that storing a file would exceed the project's storage quota
+var errQuotaExceeded = errors.New("project storage quota exceeded")
+
+// projectStorageUsage sums the size of every stored file version of a project
+func projectStorageUsage(db *gorm.DB, projectID uint) (int64, error) {
+	var used int64
+	err := db.Model(&models.Attachment{}).
+		Where("project_id = ?", projectID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&used).Error
+	return used, err
+}
+
+// sanitizeFilename strips directories and control characters from a client supplied filename
+func sanitizeFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == "/" {
+		return ""
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
+
+func toAttachmentDetail(attachment models.Attachment) AttachmentDetail {
+	return AttachmentDetail{
+		ID:          attachment.ID,
+		ProjectID:   attachment.ProjectID,
+		UploaderID:  attachment.UploaderID,
+		Filename:    attachment.Filename,
+		Version:     attachment.Version,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Checksum:    attachment.Checksum,
+		CreatedAt:   attachment.CreatedAt,
+	}
+}
+
+// UploadProjectAttachment godoc
+// @Summary      Upload a project file
+// @Description  Upload a file to a project. Uploading a file with an existing name stores a new version. Requires the upload_files capability. An optional SHA-256 checksum can be sent to verify the upload.
+// @Tags         Project Files
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        file formData file true "File to upload"
+// @Param        checksum formData string false "Expected hex encoded SHA-256 of the file"
+// @Success      201 {object} AttachmentUploadResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      413 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/attachments [post]
+func UploadProjectAttachment(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityUploadFiles, "Your role doesn't allow uploading files")
+	if !ok {
+		return
+	}
+	userID := utils.InferUserID(c)
+	maxSize := maxAttachmentSize()
+
+	// cap the request body, leaving room for multipart headers and form fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+64<<10)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("Files must be %d MB or smaller", maxSize>>20)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "File is required"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: fmt.Sprintf("Files must be %d MB or smaller", maxSize>>20)})
+		return
+	}
+
+	filename := sanitizeFilename(header.Filename)
+	if filename == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid filename"})
+		return
+	}
+
+	// enforce the per-project quota before storing anything, and again once the upload is stored
+	used, err := projectStorageUsage(database.DB, project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check storage usage"})
+		return
+	}
+	if used+header.Size > projectStorageQuota() {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Project storage quota exceeded"})
+		return
+	}
+
+	// detect the content type from the file contents
+	reader := bufio.NewReader(file)
+	head, _ := reader.Peek(512)
+	contentType := http.DetectContentType(head)
+
+	// hash the contents while streaming them to storage
+	token, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to store file"})
+		return
+	}
+	storageKey := fmt.Sprintf("projects/%d/attachments/%s", project.ID, token)
+	hasher := sha256.New()
+	if err := storage.Store.Put(c.Request.Context(), storageKey, io.TeeReader(reader, hasher), header.Size, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to store file"})
+		return
+	}
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+
+	if expected := strings.ToLower(strings.TrimSpace(c.PostForm("checksum"))); expected != "" && expected != checksum {
+		storage.Store.Delete(c.Request.Context(), storageKey)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Checksum mismatch, the file was corrupted during upload"})
+		return
+	}
+
+	attachment := models.Attachment{
+		ProjectID:   project.ID,
+		UploaderID:  userID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        header.Size,
+		Checksum:    checksum,
+		StorageKey:  storageKey,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// concurrent uploads may have used up the quota since it was checked
+		used, err := projectStorageUsage(tx, project.ID)
+		if err != nil {
+			return err
+		}
+		if used+attachment.Size > projectStorageQuota() {
+			return errQuotaExceeded
+		}
+
+		// determine the next version number for this filename
+		var latestVersion int
+		err = tx.Model(&models.Attachment{}).
+			Where("project_id = ? AND filename = ?", project.ID, filename).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latestVersion).Error
+		if err != nil {
+			return err
+		}
+		attachment.Version = latestVersion + 1
+
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAttachmentUploaded, TargetType: "attachment", TargetID: attachment.ID,
+			ProjectID: auditProject(project.ID), After: toAttachmentDetail(attachment),
+		})
+	})
+	if err != nil {
+		storage.Store.Delete(c.Request.Context(), storageKey)
+		if errors.Is(err, errQuotaExceeded) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Project storage quota exceeded"})
+			return
+		}
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Failed to save file, another version was uploaded concurrently"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AttachmentUploadResponse{
+		Message:    "File uploaded successfully",
+		Attachment: toAttachmentDetail(attachment),
+	})
+}
+
+// ListProjectAttachments godoc
+// @Summary      List project files
+// @Description  Lists the latest version of every file in a project. When a filename is given, all versions of that file are returned instead. Only collaborators can list files.
+// @Tags         Project Files
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        filename query string false "List all versions of this file"
+// @Success      200 {object} AttachmentListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/attachments [get]
+func ListProjectAttachments(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can view project files")
+	if !ok {
+		return
+	}
+
+	query := database.DB.Where("project_id = ?", project.ID)
+	if filename := c.Query("filename"); filename != "" {
+		query = query.Where("filename = ?", filename).Order("version DESC")
+	} else {
+		// the highest ID of each filename is its latest version
+		latest := database.DB.Model(&models.Attachment{}).
+			Select("MAX(id)").
+			Where("project_id = ?", project.ID).
+			Group("filename")
+		query = query.Where("id IN (?)", latest).Order("filename")
+	}
+
+	var attachments []models.Attachment
+	if err := query.Find(&attachments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch files"})
+		return
+	}
+
+	used, err := projectStorageUsage(database.DB, project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check storage usage"})
+		return
+	}
+
+	response := make([]AttachmentDetail, len(attachments))
+	for i, attachment := range attachments {
+		response[i] = toAttachmentDetail(attachment)
+	}
+
+	c.JSON(http.StatusOK, AttachmentListResponse{
+		Attachments: response,
+		UsedBytes:   used,
+		QuotaBytes:  projectStorageQuota(),
+	})
+}
+
+// DownloadProjectAttachment godoc
+// @Summary      Download a project file
+// @Description  Downloads a specific file version. The SHA-256 checksum is returned in the ETag and X-Checksum-SHA256 headers. Only collaborators can download files.
+// @Tags         Project Files
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        attachmentId path int true "Attachment ID"
+// @Success      200 {file} binary
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/attachments/{attachmentId} [get]
+func DownloadProjectAttachment(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can download project files")
+	if !ok {
+		return
+	}
+
+	var attachment models.Attachment
+	if err := database.DB.Where("id = ? AND project_id = ?", c.Param("attachmentId"), project.ID).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+		return
+	}
+
+	reader, _, err := storage.Store.Get(c.Request.Context(), attachment.StorageKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load file"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, reader, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + attachment.Checksum + `"`,
+		"X-Checksum-SHA256":      attachment.Checksum,
+	})
+}
+
+// DeleteProjectAttachment godoc
+// @Summary      Delete a project file
+// @Description  Deletes a file together with all of its versions. Requires the delete_files capability.
+// @Tags         Project Files
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        attachmentId path int true "Attachment ID of any version of the file"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/attachments/{attachmentId} [delete]
+func DeleteProjectAttachment(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityDeleteFiles, "Your role doesn't allow deleting files")
+	if !ok {
+		return
+	}
+
+	var attachment models.Attachment
+	if err := database.DB.Where("id = ? AND project_id = ?", c.Param("attachmentId"), project.ID).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "File not found"})
+		return
+	}
+
+	var versions []models.Attachment
+	if err := database.DB.Where("project_id = ? AND filename = ?", project.ID, attachment.Filename).Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch file versions"})
+		return
+	}
+
+	// records are removed permanently since their contents are deleted from storage as well
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("project_id = ? AND filename = ?", project.ID, attachment.Filename).Delete(&models.Attachment{}).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAttachmentDeleted, TargetType: "attachment", TargetID: attachment.ID,
+			ProjectID: auditProject(project.ID), Before: gin.H{"filename": attachment.Filename, "versions": len(versions)},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete file"})
+		return
+	}
+	for _, version := range versions {
+		storage.Store.Delete(c.Request.Context(), version.StorageKey)
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "File deleted successfully"})
+}
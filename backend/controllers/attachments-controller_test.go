@@ -0,0 +1,180 @@
+package controllers_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/storage"
+	"backend/utils"
+)
+
+type attachmentsFixture struct {
+	router          *gin.Engine
+	project         models.Project
+	ownerToken      string
+	programmerToken string
+	outsiderToken   string
+}
+
+func setupAttachmentsTest(t *testing.T) attachmentsFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Attachment{})
+	database.DB.Exec("DELETE FROM attachments")
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	storage.Store = store
+
+	owner := models.User{Email: "files_owner@example.com", Password: "password"}
+	programmer := models.User{Email: "files_programmer@example.com", Password: "password"}
+	outsider := models.User{Email: "files_outsider@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&programmer)
+	database.DB.Create(&outsider)
+
+	project := models.Project{Title: "Files Project", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: owner.ID, Role: "owner"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: programmer.ID, Role: "programmer"})
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	programmerToken, _ := utils.GenerateJWT(programmer.ID, programmer.Email)
+	outsiderToken, _ := utils.GenerateJWT(outsider.ID, outsider.Email)
+
+	router := gin.Default()
+	router.GET("/projects/:id/attachments", middleware.AuthRequired(), controllers.ListProjectAttachments)
+	router.POST("/projects/:id/attachments", middleware.AuthRequired(), controllers.UploadProjectAttachment)
+	router.GET("/projects/:id/attachments/:attachmentId", middleware.AuthRequired(), controllers.DownloadProjectAttachment)
+	router.DELETE("/projects/:id/attachments/:attachmentId", middleware.AuthRequired(), controllers.DeleteProjectAttachment)
+
+	return attachmentsFixture{router, project, ownerToken, programmerToken, outsiderToken}
+}
+
+func uploadAttachment(t *testing.T, f attachmentsFixture, token, filename, content, checksum string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	part.Write([]byte(content))
+	if checksum != "" {
+		writer.WriteField("checksum", checksum)
+	}
+	writer.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/projects/%d/attachments", f.project.ID), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestProjectAttachmentLifecycle(t *testing.T) {
+	f := setupAttachmentsTest(t)
+
+	// programmer uploads a file twice, creating two versions
+	w := uploadAttachment(t, f, f.programmerToken, "notes.txt", "first draft", "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	sum := sha256.Sum256([]byte("second draft"))
+	w = uploadAttachment(t, f, f.programmerToken, "notes.txt", "second draft", hex.EncodeToString(sum[:]))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var uploadResponse controllers.AttachmentUploadResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploadResponse))
+	assert.Equal(t, 2, uploadResponse.Attachment.Version)
+	assert.Equal(t, hex.EncodeToString(sum[:]), uploadResponse.Attachment.Checksum)
+
+	// listing shows only the latest version
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/attachments", f.project.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.ownerToken)
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var listResponse controllers.AttachmentListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	assert.Len(t, listResponse.Attachments, 1)
+	assert.Equal(t, 2, listResponse.Attachments[0].Version)
+	assert.Equal(t, int64(len("first draft")+len("second draft")), listResponse.UsedBytes)
+
+	// listing by filename shows the version history
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/projects/%d/attachments?filename=notes.txt", f.project.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.ownerToken)
+	f.router.ServeHTTP(w, req)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	assert.Len(t, listResponse.Attachments, 2)
+
+	// download the latest version
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/projects/%d/attachments/%d", f.project.ID, uploadResponse.Attachment.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.programmerToken)
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "second draft", w.Body.String())
+	assert.Equal(t, hex.EncodeToString(sum[:]), w.Header().Get("X-Checksum-SHA256"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "notes.txt")
+
+	// programmers cannot delete files
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/projects/%d/attachments/%d", f.project.ID, uploadResponse.Attachment.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.programmerToken)
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// owners delete the file with all versions
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/projects/%d/attachments/%d", f.project.ID, uploadResponse.Attachment.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.ownerToken)
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	database.DB.Model(&models.Attachment{}).Where("project_id = ?", f.project.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestProjectAttachmentAccessControl(t *testing.T) {
+	f := setupAttachmentsTest(t)
+
+	w := uploadAttachment(t, f, f.outsiderToken, "notes.txt", "not a collaborator", "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/projects/%d/attachments", f.project.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+f.outsiderToken)
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestProjectAttachmentChecksumMismatch(t *testing.T) {
+	f := setupAttachmentsTest(t)
+
+	w := uploadAttachment(t, f, f.ownerToken, "data.csv", "a,b,c", "0000")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Checksum mismatch")
+}
+
+func TestProjectAttachmentQuota(t *testing.T) {
+	f := setupAttachmentsTest(t)
+	t.Setenv("PROJECT_STORAGE_QUOTA_MB", "0")
+
+	w := uploadAttachment(t, f, f.ownerToken, "data.csv", "a,b,c", "")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "quota")
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordAudit appends an audit event for the current request. Pass the transaction performing the change when
+// there is one and abort it on error; outside a transaction the change already happened, so failures are only logged.
+func recordAudit(c *gin.Context, db *gorm.DB, entry services.AuditEntry) error {
+	err := services.RecordAudit(db, services.AuditContext{
+		ActorID:   utils.InferUserID(c),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}, entry)
+	if err != nil {
+		log.Printf("Failed to record audit event %s: %v", entry.Action, err)
+	}
+	return err
+}
+
+// auditProject returns a project ID for AuditEntry.ProjectID
+func auditProject(projectID uint) *uint {
+	return &projectID
+}
+
+type ActivityEntry struct {
+	ID         uint                            `json:"id"`
+	ActorID    *uint                           `json:"actor_id"`
+	ActorEmail string                          `json:"actor_email"`
+	Action     string                          `json:"action" example:"project.status_changed"`
+	TargetType string                          `json:"target_type" example:"project"`
+	TargetID   uint                            `json:"target_id"`
+	ProjectID  *uint                           `json:"project_id"`
+	Changes    map[string]services.AuditChange `json:"changes"`
+	CreatedAt  time.Time                       `json:"created_at"`
+}
+
+type ActivityFeedResponse struct {
+	Events   []ActivityEntry `json:"events"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Total    int64           `json:"total"`
+}
+
+type AuditEventDetail struct {
+	ActivityEntry
+	IP        string `json:"ip" example:"203.0.113.7"`
+	UserAgent string `json:"user_agent"`
+}
+
+type AuditEventListResponse struct {
+	Events   []AuditEventDetail `json:"events"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Total    int64              `json:"total"`
+}
+
+func toActivityEntry(event models.AuditEvent) ActivityEntry {
+	entry := ActivityEntry{
+		ID:         event.ID,
+		ActorID:    event.ActorID,
+		Action:     string(event.Action),
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		ProjectID:  event.ProjectID,
+		Changes:    map[string]services.AuditChange{},
+		CreatedAt:  event.CreatedAt,
+	}
+	if event.Actor != nil {
+		entry.ActorEmail = event.Actor.Email
+	}
+	if event.Changes != "" {
+		json.Unmarshal([]byte(event.Changes), &entry.Changes)
+	}
+	return entry
+}
+
+// ListProjectActivity godoc
+// @Summary      Get a project's activity feed
+// @Description  Lists what happened on a project, newest first: invitations, role and status changes, tasks, wiki edits and more.
+// @Tags         Projects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        action query string false "Only return events with this action, e.g. task.created"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Events per page (max 100)" default(20)
+// @Success      200 {object} ActivityFeedResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/activity [get]
+func ListProjectActivity(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can view project activity")
+	if !ok {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.AuditEvent{}).Where("project_id = ?", project.ID)
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch activity"})
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := query.Preload("Actor").Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch activity"})
+		return
+	}
+
+	response := ActivityFeedResponse{Events: make([]ActivityEntry, len(events)), Page: page, PageSize: pageSize, Total: total}
+	for i, event := range events {
+		response.Events[i] = toActivityEntry(event)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// QueryAuditEvents godoc
+// @Summary      Query the audit log
+// @Description  Searches audit events across the whole platform, newest first. Only administrators can query the audit log.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        actor_id query int false "Only events performed by this user"
+// @Param        project_id query int false "Only events on this project"
+// @Param        action query string false "Only events with this action"
+// @Param        target_type query string false "Only events on this kind of target, e.g. task"
+// @Param        target_id query int false "Only events on this target"
+// @Param        since query string false "Only events at or after this time (RFC 3339)"
+// @Param        until query string false "Only events before this time (RFC 3339)"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Events per page (max 100)" default(20)
+// @Success      200 {object} AuditEventListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/audit-events [get]
+func QueryAuditEvents(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.AuditEvent{})
+	for param, column := range map[string]string{"actor_id": "actor_id", "project_id": "project_id", "target_id": "target_id"} {
+		if value := c.Query(param); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + param})
+				return
+			}
+			query = query.Where(column+" = ?", id)
+		}
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	for param, condition := range map[string]string{"since": "created_at >= ?", "until": "created_at < ?"} {
+		if value := c.Query(param); value != "" {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + param + ", expected an RFC 3339 timestamp"})
+				return
+			}
+			query = query.Where(condition, at)
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch audit events"})
+		return
+	}
+
+	var events []models.AuditEvent
+	if err := query.Preload("Actor").Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch audit events"})
+		return
+	}
+
+	response := AuditEventListResponse{Events: make([]AuditEventDetail, len(events)), Page: page, PageSize: pageSize, Total: total}
+	for i, event := range events {
+		response.Events[i] = AuditEventDetail{ActivityEntry: toActivityEntry(event), IP: event.IP, UserAgent: event.UserAgent}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,134 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type auditFixture struct {
+	router          *gin.Engine
+	project         models.Project
+	owner           models.User
+	ownerToken      string
+	programmerToken string
+	outsiderToken   string
+	adminToken      string
+}
+
+func setupAuditTest(t *testing.T) auditFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+
+	owner := models.User{Email: "audit_owner@example.com", Password: "password"}
+	programmer := models.User{Email: "audit_programmer@example.com", Password: "password"}
+	outsider := models.User{Email: "audit_outsider@example.com", Password: "password"}
+	admin := models.User{Email: "audit_admin@example.com", Password: "password", IsAdmin: true}
+	database.DB.Create(&owner)
+	database.DB.Create(&programmer)
+	database.DB.Create(&outsider)
+	database.DB.Create(&admin)
+	project := models.Project{Title: "Audit Project", OwnerID: owner.ID, Visibility: "public", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: programmer.ID, Role: "programmer"})
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	programmerToken, _ := utils.GenerateJWT(programmer.ID, programmer.Email)
+	outsiderToken, _ := utils.GenerateJWT(outsider.ID, outsider.Email)
+	adminToken, _ := utils.GenerateJWT(admin.ID, admin.Email)
+
+	router := gin.Default()
+	router.PUT("/projects/:id/status", middleware.AuthRequired(), controllers.UpdateProjectStatus)
+	router.POST("/projects/:id/tasks", middleware.AuthRequired(), controllers.CreateTask)
+	router.GET("/projects/:id/activity", middleware.AuthRequired(), controllers.ListProjectActivity)
+	router.GET("/admin/audit-events", middleware.AuthRequired(), middleware.AdminOnly(), controllers.QueryAuditEvents)
+	return auditFixture{router, project, owner, ownerToken, programmerToken, outsiderToken, adminToken}
+}
+
+func TestProjectActivityFeed(t *testing.T) {
+	f := setupAuditTest(t)
+
+	w := projectRequest(f.router, "PUT", fmt.Sprintf("/projects/%d/status", f.project.ID), f.ownerToken, map[string]string{"status": "in-progress"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/tasks", f.project.ID), f.programmerToken, map[string]string{"title": "Collect data"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	activityPath := fmt.Sprintf("/projects/%d/activity", f.project.ID)
+
+	// public projects still keep their activity to collaborators
+	w = projectRequest(f.router, "GET", activityPath, f.outsiderToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "GET", activityPath, f.programmerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var feed controllers.ActivityFeedResponse
+	json.Unmarshal(w.Body.Bytes(), &feed)
+	assert.Equal(t, int64(2), feed.Total)
+	assert.Equal(t, "task.created", feed.Events[0].Action)
+	assert.Equal(t, "Collect data", feed.Events[0].Changes["title"].After)
+
+	status := feed.Events[1]
+	assert.Equal(t, "project.status_changed", status.Action)
+	assert.Equal(t, "audit_owner@example.com", status.ActorEmail)
+	assert.Equal(t, "open", status.Changes["status"].Before)
+	assert.Equal(t, "in-progress", status.Changes["status"].After)
+
+	// client details are only exposed to administrators
+	assert.NotContains(t, w.Body.String(), "user_agent")
+
+	w = projectRequest(f.router, "GET", activityPath+"?action=task.created", f.programmerToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &feed)
+	assert.Equal(t, int64(1), feed.Total)
+}
+
+func TestQueryAuditEvents(t *testing.T) {
+	f := setupAuditTest(t)
+
+	w := projectRequest(f.router, "PUT", fmt.Sprintf("/projects/%d/status", f.project.ID), f.ownerToken, map[string]string{"status": "completed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", "/admin/audit-events", f.ownerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/admin/audit-events?actor_id=%d&action=project.status_changed", f.owner.ID), f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response controllers.AuditEventListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, f.project.ID, *response.Events[0].ProjectID)
+	assert.Contains(t, w.Body.String(), "user_agent")
+
+	w = projectRequest(f.router, "GET", "/admin/audit-events?target_type=task", f.adminToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(0), response.Total)
+
+	w = projectRequest(f.router, "GET", "/admin/audit-events?since=yesterday", f.adminToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	f := setupAuditTest(t)
+	projectRequest(f.router, "PUT", fmt.Sprintf("/projects/%d/status", f.project.ID), f.ownerToken, map[string]string{"status": "completed"})
+
+	var event models.AuditEvent
+	assert.NoError(t, database.DB.First(&event).Error)
+
+	event.Action = "project.created"
+	assert.ErrorIs(t, database.DB.Save(&event).Error, models.ErrAuditEventImmutable)
+	assert.ErrorIs(t, database.DB.Delete(&event).Error, models.ErrAuditEventImmutable)
+
+	var count int64
+	database.DB.Model(&models.AuditEvent{}).Where("action = ?", models.AuditProjectStatusChanged).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
@@ -3,10 +3,17 @@ package controllers
 import (
 	"backend/database"
 	"backend/models"
+	"backend/services"
 	"backend/utils"
+	_ "embed"
+	"errors"
+	"html/template"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type UserRegistrationRequest struct {
@@ -23,6 +30,9 @@ type ErrorResponse struct {
 	Error string `json:"error" example:"Invalid request"`
 }
 
+// errEmailRegistered signals that a sign up failed because the email address is taken
+var errEmailRegistered = errors.New("email already registered")
+
 // RegisterUser godoc
 // @Summary      Register a new user
 // @Description  Create a new user account using user credentials. The provided password is hashed before storing to database. A blank user profile is created.
@@ -48,6 +58,12 @@ func RegisterUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid email format"})
 	}
 
+	// enforce the password policy
+	if err := utils.ValidatePassword(requestBody.Password, requestBody.Email); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	// hash password
 	hashedPassword, err := utils.HashPassword(requestBody.Password)
 	if err != nil {
@@ -55,17 +71,26 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	// create new user
+	// create new user with a blank profile
 	user := models.User{Email: requestBody.Email, Password: hashedPassword}
-	// ensure email does not already exist in user database
-	if err := database.DB.Create(&user).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// ensure email does not already exist in user database
+		if err := tx.Create(&user).Error; err != nil {
+			return errEmailRegistered
+		}
+		if err := tx.Create(&models.UserProfile{UserID: user.ID}).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditUserRegistered, TargetType: "user", TargetID: user.ID,
+			After: gin.H{"email": user.Email},
+		})
+	})
+	if errors.Is(err, errEmailRegistered) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email already registered"})
 		return
 	}
-
-	// create blank user profile
-	userProfile := models.UserProfile{UserID: user.ID}
-	if err := database.DB.Create(&userProfile).Error; err != nil {
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create user profile"})
 		return
 	}
@@ -90,7 +115,7 @@ type UserLoginResponse struct {
 
 // LoginUser godoc
 // @Summary      Login user
-// @Description  Authenticate user login with email and password, returns JWT token on success.
+// @Description  Authenticate user login with email and password, returns JWT token on success. Each login starts a session that can be reviewed and ended from GET /users/me/sessions.
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
@@ -118,12 +143,53 @@ func LoginUser(c *gin.Context) {
 
 	// verify password
 	if !utils.CheckPassword(user.Password, requestBody.Password) {
+		recordFailedLogin(c, user.ID, models.LoginFailureInvalidPassword)
 		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Invalid password"})
 		return
 	}
 
+	// upgrade hashes made with an older algorithm or weaker parameters while the plaintext is at hand
+	if utils.PasswordNeedsRehash(user.Password) {
+		if hashed, err := utils.HashPassword(requestBody.Password); err != nil {
+			log.Println("Failed to rehash password:", err)
+		} else if err := database.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("password", hashed).Error; err != nil {
+			log.Println("Failed to store rehashed password:", err)
+		}
+	}
+
+	// blocked accounts are only reported once the password is known to be correct
+	if user.DisabledAt != nil {
+		recordFailedLogin(c, user.ID, models.LoginFailureAccountDisabled)
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "This account has been disabled"})
+		return
+	}
+	if user.PasswordResetRequired {
+		recordFailedLogin(c, user.ID, models.LoginFailurePasswordResetRequired)
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "A password reset is required, check your email for a reset link"})
+		return
+	}
+
+	// warn the user about logins from devices or networks they haven't used before
+	attempt, err := services.RecordSuccessfulLogin(database.DB, user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Println("Failed to record login:", err)
+	} else if attempt.Unfamiliar {
+		notification, err := services.NotifyUnfamiliarLogin(database.DB, user, attempt)
+		services.PublishNotifications(notification)
+		if err != nil {
+			log.Printf("Failed to notify user %d about an unfamiliar login: %v", user.ID, err)
+		}
+	}
+
+	// remember the device so the user can review and end the session later
+	session, err := services.StartSession(database.DB, user.ID, c.Request.UserAgent(), c.ClientIP(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to start session"})
+		return
+	}
+
 	// generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email)
+	token, err := utils.GenerateSessionJWT(user.ID, user.Email, session.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate token"})
 		return
@@ -136,3 +202,263 @@ func LoginUser(c *gin.Context) {
 		Token:   token,
 	})
 }
+
+// recordFailedLogin adds a failed login to the account's login history; failing to record it doesn't affect the response
+func recordFailedLogin(c *gin.Context, userID uint, reason models.LoginFailureReason) {
+	if err := services.RecordFailedLogin(database.DB, userID, c.Request.UserAgent(), c.ClientIP(), reason); err != nil {
+		log.Println("Failed to record login:", err)
+	}
+}
+
+type PasswordResetRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Sets a new password using the single use token from a password reset email. The password must follow the password policy. Lifts a password reset required by an administrator and signs out existing sessions.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        requestBody body PasswordResetRequest true "Reset token and new password"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /auth/reset-password [post]
+func ResetPassword(c *gin.Context) {
+	var request PasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	if err := utils.ValidatePassword(request.Password, ""); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(request.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to hash password"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		userID, err := services.RedeemPasswordReset(tx, request.Token, now)
+		if err != nil {
+			return err
+		}
+		if err := services.SetPassword(tx, userID, hashedPassword, 0, now); err != nil {
+			return err
+		}
+		// the reset link was mailed to the account's address, so following it verifies the address
+		if err := services.MarkEmailVerified(tx, userID, now); err != nil {
+			return err
+		}
+		// the user isn't logged in, attribute the change to them
+		c.Set(utils.UserIDKey, userID)
+		return recordAudit(c, tx, services.AuditEntry{Action: models.AuditPasswordReset, TargetType: "user", TargetID: userID})
+	})
+	if errors.Is(err, services.ErrInvalidResetToken) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "This reset link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Password reset successfully, you can now log in"})
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Confirm a new email address
+// @Description  Completes an email change using the single use token sent to the new address. Pending project invitations addressed to the old email are moved to the new one.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        requestBody body ConfirmEmailRequest true "Confirmation token"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /auth/confirm-email [post]
+func ConfirmEmailChange(c *gin.Context) {
+	var request ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		user, newEmail, err := services.ConfirmEmailChange(tx, request.Token, time.Now())
+		if err != nil {
+			return err
+		}
+		// the link may be opened without logging in, attribute the change to the account owner
+		c.Set(utils.UserIDKey, user.ID)
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditEmailChanged, TargetType: "user", TargetID: user.ID,
+			Before: gin.H{"email": user.Email}, After: gin.H{"email": newEmail},
+		})
+	})
+	if errors.Is(err, services.ErrInvalidEmailChangeToken) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "This confirmation link is invalid or has expired"})
+		return
+	}
+	if errors.Is(err, services.ErrEmailTaken) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Email already registered"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to confirm email address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Email address updated, use it to log in from now on"})
+}
+
+//go:embed templates/login-report.html
+var loginReportPage string
+
+var loginReportTemplate = template.Must(template.New("login-report").Parse(loginReportPage))
+
+// respondToLoginReport answers browsers following the emailed link with a page and API clients with JSON
+func respondToLoginReport(c *gin.Context, status int, message string) {
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		c.Status(status)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		loginReportTemplate.Execute(c.Writer, gin.H{"Message": message})
+		return
+	}
+	if status == http.StatusOK {
+		c.JSON(status, MessageResponse{Message: message})
+	} else {
+		c.JSON(status, ErrorResponse{Error: message})
+	}
+}
+
+// ConfirmLoginReport godoc
+// @Summary      Confirm reporting a login that wasn't you
+// @Description  Target of the "this wasn't me" link from an unfamiliar login email. Shows a page asking to confirm the report, which submits it to POST /auth/not-me. Opening the link changes nothing, so mail scanners prefetching it can't lock users out.
+// @Tags         Authentication
+// @Produce      html
+// @Param        token query string true "Signed token from the email"
+// @Success      200 {string} string "Confirmation page"
+// @Failure      400 {string} string "Invalid link page"
+// @Router       /auth/not-me [get]
+func ConfirmLoginReport(c *gin.Context) {
+	token := c.Query("token")
+	if _, _, err := services.ParseLoginReportToken(token, time.Now()); err != nil {
+		c.Status(http.StatusBadRequest)
+		token = ""
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	loginReportTemplate.Execute(c.Writer, gin.H{"Token": token, "Message": "This link is invalid or has expired"})
+}
+
+// ReportLogin godoc
+// @Summary      Report a login that wasn't you
+// @Description  Submits the report confirmed on the page behind the "this wasn't me" link. Signs out every session, requires a password reset and emails a reset link. No login is required; links expire after 7 days. Browsers get a page, other clients JSON.
+// @Tags         Authentication
+// @Accept       x-www-form-urlencoded
+// @Produce      json,html
+// @Param        token formData string true "Signed token from the email, may also be passed in the query"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /auth/not-me [post]
+func ReportLogin(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		token = c.Query("token")
+	}
+	now := time.Now()
+	attemptID, userID, err := services.ParseLoginReportToken(token, now)
+	if err != nil {
+		respondToLoginReport(c, http.StatusBadRequest, "This link is invalid or has expired")
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		respondToLoginReport(c, http.StatusBadRequest, "This link is invalid or has expired")
+		return
+	}
+
+	var resetToken string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if resetToken, err = services.LockAccountAfterReport(tx, attemptID, user.ID, now); err != nil || resetToken == "" {
+			return err
+		}
+		// the link is opened without logging in, attribute the report to the account owner
+		c.Set(utils.UserIDKey, user.ID)
+		return recordAudit(c, tx, services.AuditEntry{Action: models.AuditLoginReported, TargetType: "login_attempt", TargetID: attemptID})
+	})
+	if err != nil {
+		respondToLoginReport(c, http.StatusInternalServerError, "Failed to secure account")
+		return
+	}
+
+	// reporting the login again doesn't send another reset email
+	if resetToken != "" {
+		reason := "You reported a login to your account that wasn't you, so every session was signed out and a new password is required."
+		if err := services.SendPasswordResetEmail(user, resetToken, reason); err != nil {
+			log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}
+
+	respondToLoginReport(c, http.StatusOK, "Your account has been secured, check your email to choose a new password")
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// VerifyEmail godoc
+// @Summary      Confirm an email address
+// @Description  Marks the account's email address as verified using the token from a verification email and links the account to the institution owning the address, if any. Links stop working once the account uses another address.
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        requestBody body VerifyEmailRequest true "Verification token"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /auth/verify-email [post]
+func VerifyEmail(c *gin.Context) {
+	var request VerifyEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		user, err := services.VerifyEmail(tx, request.Token, time.Now())
+		if err != nil {
+			return err
+		}
+		// the link may be opened without logging in, attribute the verification to the account owner
+		c.Set(utils.UserIDKey, user.ID)
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditEmailVerified, TargetType: "user", TargetID: user.ID,
+			After: gin.H{"email": user.Email},
+		})
+	})
+	if errors.Is(err, services.ErrInvalidEmailVerification) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "This verification link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to verify email address"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Email address verified"})
+}
@@ -14,6 +14,7 @@ import (
 	"backend/controllers"
 	"backend/database"
 	"backend/models"
+	"backend/utils"
 )
 
 func setupAuthTest(t *testing.T) {
@@ -24,7 +25,7 @@ func setupAuthTest(t *testing.T) {
 	}
 
 	// Run migrations or setup test data here if needed
-	database.DB.AutoMigrate(&models.User{}, &models.UserProfile{})
+	database.DB.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Session{}, &models.LoginAttempt{}, &models.AuditEvent{})
 }
 
 func TestRegisterUser(t *testing.T) {
@@ -60,3 +61,39 @@ func TestLoginUser(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "Login successful")
 }
+
+func TestLoginRehashesPassword(t *testing.T) {
+	setupAuthTest(t)
+
+	router := gin.Default()
+	router.POST("/auth/login", controllers.LoginUser)
+
+	// an account created while passwords were hashed with bcrypt
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	legacy, _ := utils.HashPassword("legacy password")
+	user := models.User{Email: "legacy@example.com", Password: legacy}
+	database.DB.Create(&user)
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "argon2id")
+
+	// a failed login leaves the hash alone
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"email": "legacy@example.com", "password": "wrong"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var unchanged models.User
+	database.DB.First(&unchanged, user.ID)
+	assert.Equal(t, legacy, unchanged.Password)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"email": "legacy@example.com", "password": "legacy password"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var upgraded models.User
+	database.DB.First(&upgraded, user.ID)
+	assert.Contains(t, upgraded.Password, "$argon2id$")
+	assert.False(t, utils.PasswordNeedsRehash(upgraded.Password))
+	assert.True(t, utils.CheckPassword(upgraded.Password, "legacy password"))
+}
@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/storage"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// default thumbnail size served when no size is requested
+const defaultAvatarSize = 128
+
+type AvatarUploadResponse struct {
+	Message   string `json:"message" example:"Avatar updated successfully"`
+	AvatarURL string `json:"avatar_url" example:"/users/1/avatar?v=1700000000"`
+}
+
+// avatarURL returns the public URL of a user's avatar, versioned for cache busting
+func avatarURL(profile models.UserProfile) string {
+	if profile.AvatarKey == "" || profile.AvatarUpdatedAt == nil {
+		return ""
+	}
+	return fmt.Sprintf("/users/%d/avatar?v=%d", profile.UserID, profile.AvatarUpdatedAt.Unix())
+}
+
+// deleteAvatarBlobs removes every thumbnail stored under prefix
+func deleteAvatarBlobs(c *gin.Context, prefix string) {
+	for _, size := range utils.AvatarSizes {
+		storage.Store.Delete(c.Request.Context(), utils.AvatarBlobKey(prefix, size))
+	}
+}
+
+// UploadUserAvatar godoc
+// @Summary      Upload user avatar
+// @Description  Upload a profile picture (JPEG, PNG, GIF or WebP, max 5 MB). The image is cropped to a square and stored as thumbnails in several sizes.
+// @Tags         Users
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "User ID"
+// @Param        avatar formData file true "Avatar image"
+// @Success      200 {object} AvatarUploadResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse "Unauthorized - Missing or invalid JWT token"
+// @Failure      403 {object} ErrorResponse "Forbidden - Cannot modify another user's profile"
+// @Failure      413 {object} ErrorResponse
+// @Failure      415 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/avatar [post]
+func UploadUserAvatar(c *gin.Context) {
+	userID := c.Param("id")
+
+	// cap the request body, leaving room for multipart headers
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, utils.MaxAvatarUploadSize+64<<10)
+
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Avatar must be 5 MB or smaller"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Avatar file is required"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > utils.MaxAvatarUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Avatar must be 5 MB or smaller"})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, utils.MaxAvatarUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Failed to read avatar file"})
+		return
+	}
+	if len(data) > utils.MaxAvatarUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Avatar must be 5 MB or smaller"})
+		return
+	}
+
+	// check the actual file contents rather than the client supplied content type
+	if _, ok := utils.SniffImageType(data); !ok {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "Avatar must be a JPEG, PNG, GIF or WebP image"})
+		return
+	}
+
+	thumbnails, err := utils.GenerateAvatarThumbnails(data)
+	if errors.Is(err, utils.ErrImageTooLarge) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Avatar dimensions are too large"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "Failed to decode avatar image"})
+		return
+	}
+
+	var profile models.UserProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+
+	// store thumbnails under a fresh prefix so cached copies of the old avatar are never served as the new one
+	now := time.Now()
+	prefix := fmt.Sprintf("avatars/%d/%d", profile.UserID, now.UnixNano())
+	for _, size := range utils.AvatarSizes {
+		thumbnail := thumbnails[size]
+		err := storage.Store.Put(c.Request.Context(), utils.AvatarBlobKey(prefix, size), bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg")
+		if err != nil {
+			deleteAvatarBlobs(c, prefix)
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to store avatar"})
+			return
+		}
+	}
+
+	oldPrefix := profile.AvatarKey
+	before := gin.H{"avatar_url": avatarURL(profile)}
+	profile.AvatarKey = prefix
+	profile.AvatarUpdatedAt = &now
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&profile).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAvatarUploaded, TargetType: "user", TargetID: profile.UserID,
+			Before: before, After: gin.H{"avatar_url": avatarURL(profile)},
+		})
+	})
+	if err != nil {
+		deleteAvatarBlobs(c, prefix)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update profile"})
+		return
+	}
+
+	// remove the previous avatar once the new one is in place
+	if oldPrefix != "" {
+		deleteAvatarBlobs(c, oldPrefix)
+	}
+
+	c.JSON(http.StatusOK, AvatarUploadResponse{
+		Message:   "Avatar updated successfully",
+		AvatarURL: avatarURL(profile),
+	})
+}
+
+// GetUserAvatar godoc
+// @Summary      Get user avatar
+// @Description  Serve a user's avatar as a square JPEG thumbnail. Responses carry caching headers and support conditional requests.
+// @Tags         Users
+// @Produce      image/jpeg
+// @Param        id path string true "User ID"
+// @Param        size query int false "Thumbnail size in pixels (64, 128 or 256)" default(128)
+// @Success      200 {file} binary
+// @Success      304 "Not modified"
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/avatar [get]
+func GetUserAvatar(c *gin.Context) {
+	userID := c.Param("id")
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(defaultAvatarSize)))
+	if err != nil || !isAvatarSize(size) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid avatar size"})
+		return
+	}
+
+	var profile models.UserProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	if profile.AvatarKey == "" || profile.AvatarUpdatedAt == nil || profile.HiddenAt != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Avatar not found"})
+		return
+	}
+
+	// the storage prefix changes on every upload, so it doubles as a strong validator
+	etag := fmt.Sprintf(`"%d-%d"`, profile.AvatarUpdatedAt.UnixNano(), size)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("Last-Modified", profile.AvatarUpdatedAt.UTC().Format(http.TimeFormat))
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	reader, info, err := storage.Store.Get(c.Request.Context(), utils.AvatarBlobKey(profile.AvatarKey, size))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Avatar not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to load avatar"})
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, info.Size, "image/jpeg", reader, nil)
+}
+
+// DeleteUserAvatar godoc
+// @Summary      Delete user avatar
+// @Description  Remove the authenticated user's profile picture.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "User ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse "Unauthorized - Missing or invalid JWT token"
+// @Failure      403 {object} ErrorResponse "Forbidden - Cannot modify another user's profile"
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/avatar [delete]
+func DeleteUserAvatar(c *gin.Context) {
+	userID := c.Param("id")
+
+	var profile models.UserProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	if profile.AvatarKey == "" {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Avatar not found"})
+		return
+	}
+
+	oldPrefix := profile.AvatarKey
+	before := gin.H{"avatar_url": avatarURL(profile)}
+	profile.AvatarKey = ""
+	profile.AvatarUpdatedAt = nil
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&profile).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAvatarDeleted, TargetType: "user", TargetID: profile.UserID,
+			Before: before, After: gin.H{"avatar_url": avatarURL(profile)},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update profile"})
+		return
+	}
+	deleteAvatarBlobs(c, oldPrefix)
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Avatar deleted successfully"})
+}
+
+// isAvatarSize reports whether size is one of the generated thumbnail sizes
+func isAvatarSize(size int) bool {
+	for _, s := range utils.AvatarSizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
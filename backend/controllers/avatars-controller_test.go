@@ -0,0 +1,150 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/storage"
+	"backend/utils"
+)
+
+func setupAvatarsTest(t *testing.T) (*gin.Engine, models.User, string) {
+	setupUsersTest(t)
+	database.DB.Exec("DELETE FROM user_profiles")
+	database.DB.Exec("DELETE FROM users")
+
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	storage.Store = store
+
+	user := models.User{Email: "avatar_user@example.com", Password: "password"}
+	database.DB.Create(&user)
+	database.DB.Create(&models.UserProfile{UserID: user.ID})
+
+	token, err := utils.GenerateJWT(user.ID, user.Email)
+	assert.NoError(t, err)
+
+	router := gin.Default()
+	router.GET("/users/:id/profile", controllers.RetrieveUserProfile)
+	router.GET("/users/:id/avatar", controllers.GetUserAvatar)
+	router.POST("/users/:id/avatar", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UploadUserAvatar)
+	router.DELETE("/users/:id/avatar", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.DeleteUserAvatar)
+
+	return router, user, token
+}
+
+func avatarUploadRequest(t *testing.T, userID uint, token string, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("avatar", "avatar.png")
+	assert.NoError(t, err)
+	part.Write(content)
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/users/%d/avatar", userID), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
+
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 40, 30))))
+	return buf.Bytes()
+}
+
+func TestUploadAndRetrieveAvatar(t *testing.T) {
+	router, user, token := setupAvatarsTest(t)
+
+	// upload avatar
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, avatarUploadRequest(t, user.ID, token, testPNG(t)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var uploadResponse controllers.AvatarUploadResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploadResponse))
+	assert.Contains(t, uploadResponse.AvatarURL, fmt.Sprintf("/users/%d/avatar", user.ID))
+
+	// profile exposes avatar URL
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/users/%d/profile", user.ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), uploadResponse.AvatarURL)
+
+	// download avatar thumbnail
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/users/%d/avatar?size=64", user.ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// conditional request returns 304
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/users/%d/avatar?size=64", user.ID), nil)
+	req.Header.Set("If-None-Match", etag)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// unsupported size
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/users/%d/avatar?size=1000", user.ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// delete avatar
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", fmt.Sprintf("/users/%d/avatar", user.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", fmt.Sprintf("/users/%d/avatar", user.ID), nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUploadAvatarRejectsNonImage(t *testing.T) {
+	router, user, token := setupAvatarsTest(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, avatarUploadRequest(t, user.ID, token, []byte("<html>definitely not a png</html>")))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestUploadAvatarRejectsLargeFile(t *testing.T) {
+	router, user, token := setupAvatarsTest(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, avatarUploadRequest(t, user.ID, token, make([]byte, utils.MaxAvatarUploadSize+1)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestUploadAvatarForbiddenForOtherUser(t *testing.T) {
+	router, user, _ := setupAvatarsTest(t)
+
+	other := models.User{Email: "other_avatar_user@example.com", Password: "password"}
+	database.DB.Create(&other)
+	otherToken, _ := utils.GenerateJWT(other.ID, other.Email)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, avatarUploadRequest(t, user.ID, otherToken, testPNG(t)))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BlockRequest struct {
+	UserID uint `json:"user_id" binding:"required" example:"42"`
+}
+
+type BlockedUserDetail struct {
+	UserID    uint      `json:"user_id"`
+	Email     string    `json:"email" example:"jane@example.com"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+type BlockListResponse struct {
+	Blocks []BlockedUserDetail `json:"blocks"`
+}
+
+// ListBlockedUsers godoc
+// @Summary      List blocked users
+// @Description  Lists the users the authenticated user has blocked, most recent first.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Success      200 {object} BlockListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/blocks [get]
+func ListBlockedUsers(c *gin.Context) {
+	var blocks []models.UserBlock
+	if err := database.DB.Preload("Blocked").Where("blocker_id = ?", c.Param("id")).Order("id DESC").Find(&blocks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch blocked users"})
+		return
+	}
+
+	response := BlockListResponse{Blocks: make([]BlockedUserDetail, len(blocks))}
+	for i, block := range blocks {
+		response.Blocks[i] = BlockedUserDetail{UserID: block.BlockedID, Email: block.Blocked.Email, BlockedAt: block.CreatedAt}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// BlockUser godoc
+// @Summary      Block a user
+// @Description  Blocks a user: they can no longer invite, mention or message you, and don't see your restricted profile fields. Pending invitations they sent you are declined.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        request body BlockRequest true "User to block"
+// @Success      201 {object} BlockedUserDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/blocks [post]
+func BlockUser(c *gin.Context) {
+	var request BlockRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	userID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	if request.UserID == uint(userID) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "You cannot block yourself"})
+		return
+	}
+
+	var blocked, blocker models.User
+	if err := database.DB.First(&blocked, request.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	if err := database.DB.First(&blocker, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	if services.HasBlocked(database.DB, blocker.ID, blocked.ID) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "User is already blocked"})
+		return
+	}
+
+	block := models.UserBlock{BlockerID: blocker.ID, BlockedID: blocked.ID}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&block).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		err := tx.Model(&models.Invitation{}).
+			Where("inviter_id = ? AND email = ? AND status = ?", blocked.ID, blocker.Email, models.InvitationStatusPending).
+			Updates(map[string]interface{}{"status": models.InvitationStatusRejected, "response_date": now}).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditUserBlocked, TargetType: "user", TargetID: blocked.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to block user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, BlockedUserDetail{UserID: blocked.ID, Email: blocked.Email, BlockedAt: block.CreatedAt})
+}
+
+// UnblockUser godoc
+// @Summary      Unblock a user
+// @Description  Removes a user from the authenticated user's block list.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        blockedId path int true "Blocked user ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/blocks/{blockedId} [delete]
+func UnblockUser(c *gin.Context) {
+	var block models.UserBlock
+	if err := database.DB.Where("blocker_id = ? AND blocked_id = ?", c.Param("id"), c.Param("blockedId")).First(&block).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User is not blocked"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&block).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditUserUnblocked, TargetType: "user", TargetID: block.BlockedID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to unblock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "User unblocked successfully"})
+}
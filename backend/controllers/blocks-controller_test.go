@@ -0,0 +1,139 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type blocksFixture struct {
+	router       *gin.Engine
+	project      models.Project
+	victim       models.User
+	harasser     models.User
+	victimToken  string
+	harasserTok  string
+	blocksPath   string
+	profilePath  string
+	commentsPath string
+}
+
+func setupBlocksTest(t *testing.T) blocksFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.UserBlock{}, &models.DiscussionThread{}, &models.Comment{}, &models.CommentMention{}, &models.CommentEdit{})
+	database.DB.Exec("DELETE FROM user_blocks")
+	database.DB.Exec("DELETE FROM comment_mentions")
+	database.DB.Exec("DELETE FROM comments")
+	database.DB.Exec("DELETE FROM discussion_threads")
+
+	victim := models.User{Email: "blocks_victim@example.com", Password: "password"}
+	harasser := models.User{Email: "blocks_harasser@example.com", Password: "password"}
+	database.DB.Create(&victim)
+	database.DB.Create(&harasser)
+	database.DB.Create(&models.UserProfile{UserID: victim.ID, FullName: "Ada Lovelace", Location: "London"})
+
+	// both collaborate on a project owned by the harasser
+	project := models.Project{Title: "Blocks Project", OwnerID: harasser.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: victim.ID, Role: "programmer"})
+	thread := models.DiscussionThread{ProjectID: project.ID, AuthorID: harasser.ID, Title: "Chat"}
+	database.DB.Create(&thread)
+
+	victimToken, _ := utils.GenerateJWT(victim.ID, victim.Email)
+	harasserToken, _ := utils.GenerateJWT(harasser.ID, harasser.Email)
+
+	router := gin.Default()
+	router.GET("/users/:id/profile", middleware.OptionalAuth(), controllers.RetrieveUserProfile)
+	router.GET("/users/:id/blocks", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.ListBlockedUsers)
+	router.POST("/users/:id/blocks", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.BlockUser)
+	router.DELETE("/users/:id/blocks/:blockedId", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UnblockUser)
+	router.POST("/projects/:id/collaborators", middleware.AuthRequired(), controllers.InviteCollaborator)
+	router.POST("/projects/:id/discussions/:threadId/comments", middleware.AuthRequired(), controllers.CreateComment)
+
+	return blocksFixture{
+		router, project, victim, harasser, victimToken, harasserToken,
+		fmt.Sprintf("/users/%d/blocks", victim.ID),
+		fmt.Sprintf("/users/%d/profile", victim.ID),
+		fmt.Sprintf("/projects/%d/discussions/%d/comments", project.ID, thread.ID),
+	}
+}
+
+func TestBlockList(t *testing.T) {
+	f := setupBlocksTest(t)
+
+	w := projectRequest(f.router, "POST", f.blocksPath, f.victimToken, map[string]uint{"user_id": f.victim.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(f.router, "POST", f.blocksPath, f.harasserTok, map[string]uint{"user_id": f.harasser.ID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "POST", f.blocksPath, f.victimToken, map[string]uint{"user_id": f.harasser.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(f.router, "POST", f.blocksPath, f.victimToken, map[string]uint{"user_id": f.harasser.ID})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = projectRequest(f.router, "GET", f.blocksPath, f.victimToken, nil)
+	var list controllers.BlockListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Len(t, list.Blocks, 1)
+	assert.Equal(t, f.harasser.Email, list.Blocks[0].Email)
+
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("%s/%d", f.blocksPath, f.harasser.ID), f.victimToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("%s/%d", f.blocksPath, f.harasser.ID), f.victimToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBlockedUserRestrictions(t *testing.T) {
+	f := setupBlocksTest(t)
+	other := models.Project{Title: "Another Project", OwnerID: f.harasser.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&other)
+	pending := models.Invitation{ProjectID: other.ID, InviterID: f.harasser.ID, Email: f.victim.Email, Role: "programmer", Status: models.InvitationStatusPending}
+	database.DB.Create(&pending)
+
+	var profile controllers.ProfileRetrievalResponse
+	w := projectRequest(f.router, "GET", f.profilePath, f.harasserTok, nil)
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(t, "London", profile.Location)
+
+	w = projectRequest(f.router, "POST", f.blocksPath, f.victimToken, map[string]uint{"user_id": f.harasser.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// pending invitations from the blocked user are declined
+	database.DB.First(&pending, pending.ID)
+	assert.Equal(t, models.InvitationStatusRejected, pending.Status)
+
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/collaborators", other.ID), f.harasserTok, map[string]string{"email": f.victim.Email, "role": "programmer"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "POST", f.commentsPath, f.harasserTok, map[string]string{"body": "Hey @blocks_victim@example.com"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var comment controllers.CommentDetail
+	json.Unmarshal(w.Body.Bytes(), &comment)
+	assert.Empty(t, comment.Mentions)
+	var notified int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", f.victim.ID, models.NotificationMention).Count(&notified)
+	assert.Equal(t, int64(0), notified)
+
+	// restricted fields are hidden from the blocked user but not from the owner
+	profile = controllers.ProfileRetrievalResponse{}
+	w = projectRequest(f.router, "GET", f.profilePath, f.harasserTok, nil)
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(t, "Ada Lovelace", profile.FullName)
+	assert.Empty(t, profile.Location)
+	assert.Empty(t, profile.Email)
+
+	profile = controllers.ProfileRetrievalResponse{}
+	w = projectRequest(f.router, "GET", f.profilePath, f.victimToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(t, "London", profile.Location)
+}
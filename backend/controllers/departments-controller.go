@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DepartmentRequest struct {
+	Name string `json:"name" binding:"required" example:"Computer & Information Science & Engineering"`
+	// other names the department goes by, used to match free-text affiliations
+	Aliases  []string `json:"aliases" example:"CISE"`
+	Location string   `json:"location" example:"CSE Building"`
+}
+
+type DepartmentDetail struct {
+	ID       uint     `json:"id"`
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases"`
+	Location string   `json:"location"`
+}
+
+func departmentDetail(department models.Department) DepartmentDetail {
+	return DepartmentDetail{ID: department.ID, Name: department.Name, Aliases: department.GetAliases(), Location: department.Location}
+}
+
+// bindDepartment validates a department request, writing a 400 response when it is invalid
+func bindDepartment(c *gin.Context) (DepartmentRequest, bool) {
+	var request DepartmentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return request, false
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Name is required"})
+		return request, false
+	}
+	request.Aliases = cleanAliases(request.Name, request.Aliases)
+	return request, true
+}
+
+// departmentNameTaken reports whether another department of the institution than id already uses the name
+func departmentNameTaken(institutionID uint, name string, id uint) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Department{}).
+		Where("institution_id = ? AND LOWER(name) = ? AND id <> ?", institutionID, strings.ToLower(name), id).Count(&count).Error
+	return count > 0, err
+}
+
+// loadDepartment fetches the department of the departmentId path parameter, which must belong to the institution
+// of the id path parameter, writing a 404 response when it doesn't exist
+func loadDepartment(c *gin.Context) (models.Department, bool) {
+	var department models.Department
+	if err := database.DB.Where("institution_id = ?", c.Param("id")).First(&department, c.Param("departmentId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Department not found"})
+		return department, false
+	}
+	return department, true
+}
+
+// CreateDepartment godoc
+// @Summary      Add a department
+// @Description  Adds a department, school or lab to an institution. Users can link their affiliation to it and its names are used when matching free-text affiliations. Only administrators can manage institutions.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Institution ID"
+// @Param        request body DepartmentRequest true "Department"
+// @Success      201 {object} DepartmentDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions/{id}/departments [post]
+func CreateDepartment(c *gin.Context) {
+	var institution models.Institution
+	if err := database.DB.First(&institution, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+	request, ok := bindDepartment(c)
+	if !ok {
+		return
+	}
+
+	taken, err := departmentNameTaken(institution.ID, request.Name, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save department"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The institution already has a department with this name"})
+		return
+	}
+
+	department := models.Department{InstitutionID: institution.ID, Name: request.Name, Location: request.Location}
+	department.SetAliases(request.Aliases)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&department).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditDepartmentCreated, TargetType: "department", TargetID: department.ID,
+			After: request,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save department"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, departmentDetail(department))
+}
+
+// UpdateDepartment godoc
+// @Summary      Update a department
+// @Description  Renames a department or changes its aliases or location. Only administrators can manage institutions.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Institution ID"
+// @Param        departmentId path int true "Department ID"
+// @Param        request body DepartmentRequest true "Department"
+// @Success      200 {object} DepartmentDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions/{id}/departments/{departmentId} [put]
+func UpdateDepartment(c *gin.Context) {
+	department, ok := loadDepartment(c)
+	if !ok {
+		return
+	}
+	request, ok := bindDepartment(c)
+	if !ok {
+		return
+	}
+
+	taken, err := departmentNameTaken(department.InstitutionID, request.Name, department.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save department"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The institution already has a department with this name"})
+		return
+	}
+
+	before := departmentDetail(department)
+	department.SetAliases(request.Aliases)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&department).Updates(map[string]interface{}{
+			"name": request.Name, "aliases": department.Aliases, "location": request.Location,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditDepartmentUpdated, TargetType: "department", TargetID: department.ID,
+			Before: before, After: request,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save department"})
+		return
+	}
+
+	c.JSON(http.StatusOK, departmentDetail(department))
+}
+
+// DeleteDepartment godoc
+// @Summary      Delete a department
+// @Description  Removes a department from an institution. Affiliations linked to it stay linked to the institution. Only administrators can manage institutions.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Institution ID"
+// @Param        departmentId path int true "Department ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions/{id}/departments/{departmentId} [delete]
+func DeleteDepartment(c *gin.Context) {
+	department, ok := loadDepartment(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserProfile{}).Where("department_id = ?", department.ID).Update("department_id", nil).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&department).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditDepartmentDeleted, TargetType: "department", TargetID: department.ID,
+			Before: departmentDetail(department),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete department"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Department deleted successfully"})
+}
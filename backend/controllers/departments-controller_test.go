@@ -0,0 +1,98 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+)
+
+func TestDepartments(t *testing.T) {
+	f := setupAdminTest(t)
+	f.router.GET("/institutions/:id", controllers.GetInstitution)
+	adminGroup := f.router.Group("/admin", middleware.AuthRequired(), middleware.AdminOnly())
+	adminGroup.DELETE("/institutions/:id", controllers.DeleteInstitution)
+	adminGroup.POST("/institutions/:id/departments", controllers.CreateDepartment)
+	adminGroup.PUT("/institutions/:id/departments/:departmentId", controllers.UpdateDepartment)
+	adminGroup.DELETE("/institutions/:id/departments/:departmentId", controllers.DeleteDepartment)
+
+	university := models.Institution{Name: "University of Florida"}
+	other := models.Institution{Name: "Florida State University"}
+	database.DB.Create(&university)
+	database.DB.Create(&other)
+	departments := fmt.Sprintf("/admin/institutions/%d/departments", university.ID)
+
+	w := projectRequest(f.router, "POST", departments, f.token, map[string]interface{}{"name": "Mathematics"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", departments, f.adminToken, map[string]interface{}{"name": "  "})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(f.router, "POST", "/admin/institutions/9999/departments", f.adminToken, map[string]interface{}{"name": "Mathematics"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = projectRequest(f.router, "POST", departments, f.adminToken, map[string]interface{}{
+		"name": "Computer Science", "aliases": []string{"CS", " cs ", "", "computer science"}, "location": "CSE Building",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var department controllers.DepartmentDetail
+	json.Unmarshal(w.Body.Bytes(), &department)
+	assert.Equal(t, []string{"CS"}, department.Aliases)
+	assert.Equal(t, "CSE Building", department.Location)
+
+	// names are unique within an institution only
+	w = projectRequest(f.router, "POST", departments, f.adminToken, map[string]interface{}{"name": "computer science"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/admin/institutions/%d/departments", other.ID), f.adminToken, map[string]interface{}{"name": "Computer Science"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(f.router, "POST", departments, f.adminToken, map[string]interface{}{"name": "Mathematics"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var mathematics controllers.DepartmentDetail
+	json.Unmarshal(w.Body.Bytes(), &mathematics)
+
+	path := fmt.Sprintf("%s/%d", departments, department.ID)
+	w = projectRequest(f.router, "PUT", path, f.adminToken, map[string]interface{}{"name": "Mathematics"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = projectRequest(f.router, "PUT", fmt.Sprintf("/admin/institutions/%d/departments/%d", other.ID, department.ID), f.adminToken, map[string]interface{}{"name": "CISE"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(f.router, "PUT", path, f.adminToken, map[string]interface{}{"name": "Computer & Information Science & Engineering", "aliases": []string{"CISE"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	department = controllers.DepartmentDetail{}
+	json.Unmarshal(w.Body.Bytes(), &department)
+	assert.Equal(t, "Computer & Information Science & Engineering", department.Name)
+	assert.Equal(t, []string{"CISE"}, department.Aliases)
+
+	// deleting a department keeps the affiliation linked to the institution
+	database.DB.Model(&models.UserProfile{}).Where("user_id = ?", f.member.ID).
+		Updates(map[string]interface{}{"affiliation_institution_id": university.ID, "department_id": department.ID})
+	w = projectRequest(f.router, "DELETE", path, f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var profile models.UserProfile
+	database.DB.Where("user_id = ?", f.member.ID).First(&profile)
+	assert.Nil(t, profile.DepartmentID)
+	assert.Equal(t, university.ID, *profile.AffiliationInstitutionID)
+
+	var detail controllers.InstitutionDetail
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d", university.ID), "", nil)
+	json.Unmarshal(w.Body.Bytes(), &detail)
+	if assert.Len(t, detail.Departments, 1) {
+		assert.Equal(t, "Mathematics", detail.Departments[0].Name)
+	}
+
+	// deleting the institution removes its departments and unlinks affiliations
+	database.DB.Model(&models.UserProfile{}).Where("user_id = ?", f.member.ID).Update("department_id", mathematics.ID)
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/admin/institutions/%d", university.ID), f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var remaining int64
+	database.DB.Model(&models.Department{}).Where("institution_id = ?", university.ID).Count(&remaining)
+	assert.Equal(t, int64(0), remaining)
+	profile = models.UserProfile{}
+	database.DB.Where("user_id = ?", f.member.ID).First(&profile)
+	assert.Nil(t, profile.AffiliationInstitutionID)
+	assert.Nil(t, profile.DepartmentID)
+}
@@ -0,0 +1,647 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MentionDetail struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email" example:"jane.doe@ufl.edu"`
+}
+
+type CommentDetail struct {
+	ID          uint            `json:"id"`
+	ParentID    *uint           `json:"parent_id"`
+	AuthorID    uint            `json:"author_id"`
+	AuthorEmail string          `json:"author_email"`
+	Body        string          `json:"body" example:"Great idea, @jane.doe@ufl.edu what do you think?"`
+	HTML        string          `json:"html"`
+	Mentions    []MentionDetail `json:"mentions"`
+	EditedAt    *time.Time      `json:"edited_at"`
+	Deleted     bool            `json:"deleted"`
+	Hidden      bool            `json:"hidden"` // removed by a moderator
+	CreatedAt   time.Time       `json:"created_at"`
+	Replies     []CommentDetail `json:"replies"`
+}
+
+type ThreadSummary struct {
+	ID             uint      `json:"id"`
+	Title          string    `json:"title" example:"Choice of dataset"`
+	AuthorID       uint      `json:"author_id"`
+	AuthorEmail    string    `json:"author_email"`
+	CommentCount   int64     `json:"comment_count"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ThreadListResponse struct {
+	Threads []ThreadSummary `json:"threads"`
+}
+
+type ThreadDetail struct {
+	ID          uint            `json:"id"`
+	ProjectID   uint            `json:"project_id"`
+	Title       string          `json:"title"`
+	AuthorID    uint            `json:"author_id"`
+	AuthorEmail string          `json:"author_email"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Comments    []CommentDetail `json:"comments"`
+}
+
+type ThreadCreationRequest struct {
+	Title string `json:"title" binding:"required,max=200"`
+	Body  string `json:"body" binding:"required,max=20000"`
+}
+
+type CommentCreationRequest struct {
+	Body     string `json:"body" binding:"required,max=20000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type CommentEditRequest struct {
+	Body string `json:"body" binding:"required,max=20000"`
+}
+
+type CommentEditDetail struct {
+	PreviousBody string    `json:"previous_body"`
+	EditedAt     time.Time `json:"edited_at"`
+}
+
+type CommentHistoryResponse struct {
+	CommentID uint                `json:"comment_id"`
+	Body      string              `json:"body"`
+	Edits     []CommentEditDetail `json:"edits"` // oldest first
+}
+
+// resolveMentions finds the collaborators of a project mentioned in a comment body.
+// Mentions of users who don't collaborate on the project are ignored.
+func resolveMentions(project models.Project, authorID uint, body string) []models.User {
+	emails := utils.ExtractMentions(body)
+	if len(emails) == 0 {
+		return nil
+	}
+
+	var users []models.User
+	database.DB.Where("LOWER(email) IN ?", emails).Find(&users)
+
+	// users who blocked the author can't be mentioned by them
+	blockers, _ := services.BlockersOf(database.DB, authorID)
+
+	mentioned := make([]models.User, 0, len(users))
+	for _, user := range users {
+		if projectMemberRole(project, user.ID) != "" && !slices.Contains(blockers, user.ID) {
+			mentioned = append(mentioned, user)
+		}
+	}
+	return mentioned
+}
+
+// saveMentions replaces the recorded mentions of a comment
+func saveMentions(tx *gorm.DB, commentID uint, users []models.User) error {
+	if err := tx.Unscoped().Where("comment_id = ?", commentID).Delete(&models.CommentMention{}).Error; err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := tx.Create(&models.CommentMention{CommentID: commentID, UserID: user.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findThread loads a thread of a project from the "threadId" URL parameter, writing a 404 response when it does not exist
+func findThread(c *gin.Context, projectID uint) (models.DiscussionThread, bool) {
+	var thread models.DiscussionThread
+	if err := database.DB.Preload("Author").Where("id = ? AND project_id = ?", c.Param("threadId"), projectID).First(&thread).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Discussion not found"})
+		return thread, false
+	}
+	return thread, true
+}
+
+// findComment loads a comment of a thread from the "commentId" URL parameter, writing a 404 response when it does not exist
+func findComment(c *gin.Context, threadID uint) (models.Comment, bool) {
+	var comment models.Comment
+	if err := database.DB.Where("id = ? AND thread_id = ?", c.Param("commentId"), threadID).First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Comment not found"})
+		return comment, false
+	}
+	return comment, true
+}
+
+// toCommentDetail converts a comment without its replies; deleted comments keep their place in the tree but lose their content
+func toCommentDetail(comment models.Comment) CommentDetail {
+	detail := CommentDetail{
+		ID:        comment.ID,
+		ParentID:  comment.ParentID,
+		CreatedAt: comment.CreatedAt,
+		Mentions:  []MentionDetail{},
+		Replies:   []CommentDetail{},
+	}
+	if comment.DeletedAt.Valid {
+		detail.Deleted = true
+		return detail
+	}
+	if comment.HiddenAt != nil {
+		detail.Hidden = true
+		return detail
+	}
+
+	detail.AuthorID = comment.AuthorID
+	detail.AuthorEmail = comment.Author.Email
+	detail.Body = comment.Body
+	detail.HTML, _ = utils.RenderMarkdown(comment.Body)
+	detail.EditedAt = comment.EditedAt
+	for _, mention := range comment.Mentions {
+		detail.Mentions = append(detail.Mentions, MentionDetail{UserID: mention.UserID, Email: mention.User.Email})
+	}
+	return detail
+}
+
+// buildCommentTree nests comments under their parents, keeping creation order among siblings
+func buildCommentTree(comments []models.Comment) []CommentDetail {
+	children := make(map[uint][]models.Comment)
+	roots := make([]models.Comment, 0)
+	for _, comment := range comments {
+		if comment.ParentID == nil {
+			roots = append(roots, comment)
+		} else {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		}
+	}
+
+	var build func(comment models.Comment) CommentDetail
+	build = func(comment models.Comment) CommentDetail {
+		detail := toCommentDetail(comment)
+		for _, child := range children[comment.ID] {
+			detail.Replies = append(detail.Replies, build(child))
+		}
+		return detail
+	}
+
+	tree := make([]CommentDetail, len(roots))
+	for i, root := range roots {
+		tree[i] = build(root)
+	}
+	return tree
+}
+
+// ListDiscussionThreads godoc
+// @Summary      List project discussions
+// @Description  Lists the discussion threads of a project, most recently active first. Discussions of private projects are only visible to collaborators.
+// @Tags         Project Discussions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} ThreadListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions [get]
+func ListDiscussionThreads(c *gin.Context) {
+	project, _, ok := requireProjectReadAccess(c, "Only collaborators can read discussions of private projects")
+	if !ok {
+		return
+	}
+
+	var threads []models.DiscussionThread
+	if err := database.DB.Preload("Author").Where("project_id = ?", project.ID).Order("updated_at DESC").Find(&threads).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch discussions"})
+		return
+	}
+
+	// count comments per thread
+	var stats []struct {
+		ThreadID     uint
+		CommentCount int64
+	}
+	database.DB.Model(&models.Comment{}).
+		Select("thread_id, COUNT(*) AS comment_count").
+		Joins("JOIN discussion_threads ON discussion_threads.id = comments.thread_id").
+		Where("discussion_threads.project_id = ?", project.ID).
+		Group("thread_id").
+		Scan(&stats)
+	counts := make(map[uint]int64)
+	for _, stat := range stats {
+		counts[stat.ThreadID] = stat.CommentCount
+	}
+
+	response := make([]ThreadSummary, len(threads))
+	for i, thread := range threads {
+		response[i] = ThreadSummary{
+			ID:             thread.ID,
+			Title:          thread.Title,
+			AuthorID:       thread.AuthorID,
+			AuthorEmail:    thread.Author.Email,
+			CommentCount:   counts[thread.ID],
+			LastActivityAt: thread.UpdatedAt,
+			CreatedAt:      thread.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, ThreadListResponse{Threads: response})
+}
+
+// CreateDiscussionThread godoc
+// @Summary      Start a project discussion
+// @Description  Creates a discussion thread with an opening post. Collaborators can be mentioned by email, e.g. @jane.doe@ufl.edu.
+// @Tags         Project Discussions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body ThreadCreationRequest true "Thread title and opening post"
+// @Success      201 {object} ThreadDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions [post]
+func CreateDiscussionThread(c *gin.Context) {
+	var request ThreadCreationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityComment, "Your role doesn't allow starting discussions")
+	if !ok {
+		return
+	}
+	userID := utils.InferUserID(c)
+
+	thread := models.DiscussionThread{ProjectID: project.ID, AuthorID: userID, Title: request.Title}
+	comment := models.Comment{AuthorID: userID, Body: request.Body}
+	mentioned := resolveMentions(project, utils.InferUserID(c), request.Body)
+
+	var notifications []*models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&thread).Error; err != nil {
+			return err
+		}
+		comment.ThreadID = thread.ID
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if err := saveMentions(tx, comment.ID, mentioned); err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditDiscussionCreated, TargetType: "discussion", TargetID: thread.ID,
+			ProjectID: auditProject(project.ID), After: gin.H{"title": thread.Title, "body": comment.Body},
+		})
+		if err != nil {
+			return err
+		}
+		notifications, err = services.NotifyMentions(tx, project, comment, mentioned)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create discussion"})
+		return
+	}
+	services.PublishNotifications(notifications...)
+
+	database.DB.Preload("Author").First(&thread, thread.ID)
+	respondWithThread(c, http.StatusCreated, thread)
+}
+
+// respondWithThread writes a thread together with its full comment tree
+func respondWithThread(c *gin.Context, status int, thread models.DiscussionThread) {
+	var comments []models.Comment
+	err := database.DB.Unscoped().
+		Preload("Author").
+		Preload("Mentions.User").
+		Where("thread_id = ?", thread.ID).
+		Order("created_at, id").
+		Find(&comments).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch comments"})
+		return
+	}
+
+	c.JSON(status, ThreadDetail{
+		ID:          thread.ID,
+		ProjectID:   thread.ProjectID,
+		Title:       thread.Title,
+		AuthorID:    thread.AuthorID,
+		AuthorEmail: thread.Author.Email,
+		CreatedAt:   thread.CreatedAt,
+		Comments:    buildCommentTree(comments),
+	})
+}
+
+// RetrieveDiscussionThread godoc
+// @Summary      Get a project discussion
+// @Description  Returns a discussion thread with its nested comments. Deleted comments keep their place in the tree without content.
+// @Tags         Project Discussions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        threadId path int true "Thread ID"
+// @Success      200 {object} ThreadDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions/{threadId} [get]
+func RetrieveDiscussionThread(c *gin.Context) {
+	project, _, ok := requireProjectReadAccess(c, "Only collaborators can read discussions of private projects")
+	if !ok {
+		return
+	}
+
+	thread, ok := findThread(c, project.ID)
+	if !ok {
+		return
+	}
+
+	respondWithThread(c, http.StatusOK, thread)
+}
+
+// CreateComment godoc
+// @Summary      Comment on a project discussion
+// @Description  Adds a comment to a thread, optionally as a reply to another comment. Collaborators can be mentioned by email, e.g. @jane.doe@ufl.edu.
+// @Tags         Project Discussions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        threadId path int true "Thread ID"
+// @Param        request body CommentCreationRequest true "Comment content"
+// @Success      201 {object} CommentDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions/{threadId}/comments [post]
+func CreateComment(c *gin.Context) {
+	var request CommentCreationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityComment, "Your role doesn't allow commenting")
+	if !ok {
+		return
+	}
+	thread, ok := findThread(c, project.ID)
+	if !ok {
+		return
+	}
+
+	if request.ParentID != nil {
+		var parent models.Comment
+		if err := database.DB.Where("id = ? AND thread_id = ?", *request.ParentID, thread.ID).First(&parent).Error; err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Parent comment not found in this discussion"})
+			return
+		}
+	}
+
+	comment := models.Comment{
+		ThreadID: thread.ID,
+		ParentID: request.ParentID,
+		AuthorID: utils.InferUserID(c),
+		Body:     request.Body,
+	}
+	mentioned := resolveMentions(project, utils.InferUserID(c), request.Body)
+
+	var notifications []*models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if err := saveMentions(tx, comment.ID, mentioned); err != nil {
+			return err
+		}
+		var err error
+		if notifications, err = services.NotifyMentions(tx, project, comment, mentioned); err != nil {
+			return err
+		}
+		err = recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditCommentCreated, TargetType: "comment", TargetID: comment.ID,
+			ProjectID: auditProject(project.ID), After: gin.H{"thread_id": thread.ID, "parent_id": comment.ParentID, "body": comment.Body},
+		})
+		if err != nil {
+			return err
+		}
+		// bump the thread's activity timestamp
+		return tx.Model(&thread).Update("updated_at", time.Now()).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create comment"})
+		return
+	}
+	services.PublishNotifications(notifications...)
+
+	database.DB.Preload("Author").Preload("Mentions.User").First(&comment, comment.ID)
+	c.JSON(http.StatusCreated, toCommentDetail(comment))
+}
+
+// EditComment godoc
+// @Summary      Edit a discussion comment
+// @Description  Updates the body of the authenticated user's own comment. The previous body is kept in the comment's edit history.
+// @Tags         Project Discussions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        threadId path int true "Thread ID"
+// @Param        commentId path int true "Comment ID"
+// @Param        request body CommentEditRequest true "New comment content"
+// @Success      200 {object} CommentDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions/{threadId}/comments/{commentId} [put]
+func EditComment(c *gin.Context) {
+	var request CommentEditRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityComment, "Your role doesn't allow commenting")
+	if !ok {
+		return
+	}
+	thread, ok := findThread(c, project.ID)
+	if !ok {
+		return
+	}
+	comment, ok := findComment(c, thread.ID)
+	if !ok {
+		return
+	}
+	if comment.AuthorID != utils.InferUserID(c) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can only edit your own comments"})
+		return
+	}
+	if comment.HiddenAt != nil {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "This comment was removed by a moderator"})
+		return
+	}
+	if strings.TrimSpace(request.Body) == strings.TrimSpace(comment.Body) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Comment is unchanged"})
+		return
+	}
+
+	mentioned := resolveMentions(project, utils.InferUserID(c), request.Body)
+
+	// only users who weren't mentioned before the edit get notified
+	var previous []uint
+	database.DB.Model(&models.CommentMention{}).Where("comment_id = ?", comment.ID).Pluck("user_id", &previous)
+	newlyMentioned := make([]models.User, 0, len(mentioned))
+	for _, user := range mentioned {
+		if !slices.Contains(previous, user.ID) {
+			newlyMentioned = append(newlyMentioned, user)
+		}
+	}
+
+	now := time.Now()
+	var notifications []*models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.CommentEdit{CommentID: comment.ID, PreviousBody: comment.Body}).Error; err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditCommentEdited, TargetType: "comment", TargetID: comment.ID,
+			ProjectID: auditProject(project.ID), Before: gin.H{"body": comment.Body}, After: gin.H{"body": request.Body},
+		})
+		if err != nil {
+			return err
+		}
+		comment.Body = request.Body
+		comment.EditedAt = &now
+		if err := tx.Model(&comment).Updates(map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt}).Error; err != nil {
+			return err
+		}
+		if err := saveMentions(tx, comment.ID, mentioned); err != nil {
+			return err
+		}
+		notifications, err = services.NotifyMentions(tx, project, comment, newlyMentioned)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update comment"})
+		return
+	}
+	services.PublishNotifications(notifications...)
+
+	database.DB.Preload("Author").Preload("Mentions.User").First(&comment, comment.ID)
+	c.JSON(http.StatusOK, toCommentDetail(comment))
+}
+
+// DeleteComment godoc
+// @Summary      Delete a discussion comment
+// @Description  Deletes the authenticated user's own comment. Replies to it remain visible.
+// @Tags         Project Discussions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        threadId path int true "Thread ID"
+// @Param        commentId path int true "Comment ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions/{threadId}/comments/{commentId} [delete]
+func DeleteComment(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can delete comments")
+	if !ok {
+		return
+	}
+	thread, ok := findThread(c, project.ID)
+	if !ok {
+		return
+	}
+	comment, ok := findComment(c, thread.ID)
+	if !ok {
+		return
+	}
+	if comment.AuthorID != utils.InferUserID(c) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can only delete your own comments"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&comment).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditCommentDeleted, TargetType: "comment", TargetID: comment.ID,
+			ProjectID: auditProject(project.ID), Before: gin.H{"body": comment.Body},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete comment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Comment deleted successfully"})
+}
+
+// GetCommentHistory godoc
+// @Summary      Get the edit history of a comment
+// @Description  Returns the current body of a comment together with every previous version.
+// @Tags         Project Discussions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        threadId path int true "Thread ID"
+// @Param        commentId path int true "Comment ID"
+// @Success      200 {object} CommentHistoryResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/discussions/{threadId}/comments/{commentId}/history [get]
+func GetCommentHistory(c *gin.Context) {
+	project, _, ok := requireProjectReadAccess(c, "Only collaborators can read discussions of private projects")
+	if !ok {
+		return
+	}
+	thread, ok := findThread(c, project.ID)
+	if !ok {
+		return
+	}
+	comment, ok := findComment(c, thread.ID)
+	if !ok {
+		return
+	}
+	if comment.HiddenAt != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Comment not found"})
+		return
+	}
+
+	var edits []models.CommentEdit
+	if err := database.DB.Where("comment_id = ?", comment.ID).Order("created_at, id").Find(&edits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch comment history"})
+		return
+	}
+
+	response := CommentHistoryResponse{CommentID: comment.ID, Body: comment.Body, Edits: make([]CommentEditDetail, len(edits))}
+	for i, edit := range edits {
+		response.Edits[i] = CommentEditDetail{PreviousBody: edit.PreviousBody, EditedAt: edit.CreatedAt}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,178 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type discussionsFixture struct {
+	router          *gin.Engine
+	project         models.Project
+	owner           models.User
+	programmer      models.User
+	outsider        models.User
+	ownerToken      string
+	programmerToken string
+	outsiderToken   string
+}
+
+func setupDiscussionsTest(t *testing.T) discussionsFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.DiscussionThread{}, &models.Comment{}, &models.CommentMention{}, &models.CommentEdit{})
+	database.DB.Exec("DELETE FROM comment_edits")
+	database.DB.Exec("DELETE FROM comment_mentions")
+	database.DB.Exec("DELETE FROM comments")
+	database.DB.Exec("DELETE FROM discussion_threads")
+
+	owner := models.User{Email: "discussions_owner@example.com", Password: "password"}
+	programmer := models.User{Email: "discussions_programmer@example.com", Password: "password"}
+	outsider := models.User{Email: "discussions_outsider@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&programmer)
+	database.DB.Create(&outsider)
+
+	project := models.Project{Title: "Discussions Project", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: programmer.ID, Role: "programmer"})
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	programmerToken, _ := utils.GenerateJWT(programmer.ID, programmer.Email)
+	outsiderToken, _ := utils.GenerateJWT(outsider.ID, outsider.Email)
+
+	router := gin.Default()
+	router.GET("/projects/:id/discussions", middleware.AuthRequired(), controllers.ListDiscussionThreads)
+	router.POST("/projects/:id/discussions", middleware.AuthRequired(), controllers.CreateDiscussionThread)
+	router.GET("/projects/:id/discussions/:threadId", middleware.AuthRequired(), controllers.RetrieveDiscussionThread)
+	router.POST("/projects/:id/discussions/:threadId/comments", middleware.AuthRequired(), controllers.CreateComment)
+	router.PUT("/projects/:id/discussions/:threadId/comments/:commentId", middleware.AuthRequired(), controllers.EditComment)
+	router.DELETE("/projects/:id/discussions/:threadId/comments/:commentId", middleware.AuthRequired(), controllers.DeleteComment)
+	router.GET("/projects/:id/discussions/:threadId/comments/:commentId/history", middleware.AuthRequired(), controllers.GetCommentHistory)
+
+	return discussionsFixture{router, project, owner, programmer, outsider, ownerToken, programmerToken, outsiderToken}
+}
+
+func createTestThread(t *testing.T, f discussionsFixture, body string) controllers.ThreadDetail {
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/discussions", f.project.ID), f.ownerToken,
+		map[string]string{"title": "Choice of dataset", "body": body})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var thread controllers.ThreadDetail
+	json.Unmarshal(w.Body.Bytes(), &thread)
+	return thread
+}
+
+func TestDiscussionThreadWithNestedReplies(t *testing.T) {
+	f := setupDiscussionsTest(t)
+
+	// mentions of non-collaborators are dropped
+	thread := createTestThread(t, f, "Thoughts @discussions_programmer@example.com and @discussions_outsider@example.com?")
+	assert.Len(t, thread.Comments, 1)
+	root := thread.Comments[0]
+	assert.Len(t, root.Mentions, 1)
+	assert.Equal(t, f.programmer.ID, root.Mentions[0].UserID)
+
+	var notified int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", f.programmer.ID, models.NotificationMention).Count(&notified)
+	assert.Equal(t, int64(1), notified)
+
+	commentsPath := fmt.Sprintf("/projects/%d/discussions/%d/comments", f.project.ID, thread.ID)
+	w := projectRequest(f.router, "POST", commentsPath, f.programmerToken, map[string]interface{}{"body": "Reply", "parent_id": root.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var reply controllers.CommentDetail
+	json.Unmarshal(w.Body.Bytes(), &reply)
+
+	w = projectRequest(f.router, "POST", commentsPath, f.ownerToken, map[string]interface{}{"body": "Nested", "parent_id": reply.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// parent must belong to the same thread
+	w = projectRequest(f.router, "POST", commentsPath, f.ownerToken, map[string]interface{}{"body": "Orphan", "parent_id": 9999})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/discussions/%d", f.project.ID, thread.ID), f.programmerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var detail controllers.ThreadDetail
+	json.Unmarshal(w.Body.Bytes(), &detail)
+	assert.Len(t, detail.Comments, 1)
+	assert.Len(t, detail.Comments[0].Replies, 1)
+	assert.Equal(t, "Reply", detail.Comments[0].Replies[0].Body)
+	assert.Len(t, detail.Comments[0].Replies[0].Replies, 1)
+	assert.Equal(t, "Nested", detail.Comments[0].Replies[0].Replies[0].Body)
+
+	// deleting a comment keeps its replies in place
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("%s/%d", commentsPath, reply.ID), f.programmerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/discussions/%d", f.project.ID, thread.ID), f.programmerToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &detail)
+	deleted := detail.Comments[0].Replies[0]
+	assert.True(t, deleted.Deleted)
+	assert.Empty(t, deleted.Body)
+	assert.Len(t, deleted.Replies, 1)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/discussions", f.project.ID), f.programmerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list controllers.ThreadListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Len(t, list.Threads, 1)
+	assert.Equal(t, int64(2), list.Threads[0].CommentCount)
+}
+
+func TestEditCommentHistory(t *testing.T) {
+	f := setupDiscussionsTest(t)
+	thread := createTestThread(t, f, "First draft")
+	commentPath := fmt.Sprintf("/projects/%d/discussions/%d/comments/%d", f.project.ID, thread.ID, thread.Comments[0].ID)
+
+	// only the author may edit or delete
+	w := projectRequest(f.router, "PUT", commentPath, f.programmerToken, map[string]string{"body": "Hijacked"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "DELETE", commentPath, f.programmerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "PUT", commentPath, f.ownerToken, map[string]string{"body": "Second draft @discussions_programmer@example.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var edited controllers.CommentDetail
+	json.Unmarshal(w.Body.Bytes(), &edited)
+	assert.NotNil(t, edited.EditedAt)
+	assert.Len(t, edited.Mentions, 1)
+
+	w = projectRequest(f.router, "PUT", commentPath, f.ownerToken, map[string]string{"body": "Final draft"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", commentPath+"/history", f.programmerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history controllers.CommentHistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+	assert.Equal(t, "Final draft", history.Body)
+	assert.Len(t, history.Edits, 2)
+	assert.Equal(t, "First draft", history.Edits[0].PreviousBody)
+	assert.Equal(t, "Second draft @discussions_programmer@example.com", history.Edits[1].PreviousBody)
+}
+
+func TestDiscussionVisibility(t *testing.T) {
+	f := setupDiscussionsTest(t)
+	thread := createTestThread(t, f, "Internal notes")
+	threadPath := fmt.Sprintf("/projects/%d/discussions/%d", f.project.ID, thread.ID)
+
+	// outsiders cannot read discussions of private projects
+	w := projectRequest(f.router, "GET", threadPath, f.outsiderToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	database.DB.Model(&f.project).Update("visibility", models.ProjectVisibilityPublic)
+
+	// public discussions are readable but only collaborators may post
+	w = projectRequest(f.router, "GET", threadPath, f.outsiderToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "POST", threadPath+"/comments", f.outsiderToken, map[string]string{"body": "Hello"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
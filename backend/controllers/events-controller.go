@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// reconnection delay suggested to clients, in milliseconds
+	streamRetryDelay = 3000
+	// maximum number of missed notifications replayed after a reconnect; clients that missed more are told to refetch
+	maxStreamReplay = 100
+)
+
+// StreamHeartbeatInterval is how often idle streams receive a comment line, keeping proxies from closing them
+var StreamHeartbeatInterval = 25 * time.Second
+
+// toStreamData converts event payloads to the same shape the REST API returns
+func toStreamData(event services.Event) interface{} {
+	if notification, ok := event.Data.(models.Notification); ok {
+		return toNotificationDetail(notification)
+	}
+	return event.Data
+}
+
+type StreamTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IssueStreamTicket godoc
+// @Summary      Issue an event stream ticket
+// @Description  Issues a ticket for opening the event stream from clients that cannot set the Authorization header, such as the browser EventSource API. Tickets can only open the stream and expire after a minute; issue a new one to reconnect.
+// @Tags         Notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} StreamTicketResponse
+// @Failure      401 {object} ErrorResponse
+// @Router       /notifications/stream/ticket [post]
+func IssueStreamTicket(c *gin.Context) {
+	now := time.Now()
+	c.JSON(http.StatusOK, StreamTicketResponse{
+		Ticket:    services.StreamTicket(utils.InferUserID(c), c.GetUint(utils.SessionIDKey), now),
+		ExpiresAt: now.Add(services.StreamTicketTTL),
+	})
+}
+
+// StreamEvents godoc
+// @Summary      Stream real-time events
+// @Description  Opens a Server-Sent Events stream of the authenticated user's notifications and of changes to their projects.
+// @Description  Browsers pass a ticket from POST /notifications/stream/ticket in the ticket query parameter instead of their token. After a reconnect, notifications newer than the Last-Event-ID header, or the last_event_id query parameter, are replayed.
+// @Description  Clients that missed more than 100 notifications get a reset event instead and should refetch their notifications.
+// @Tags         Notifications
+// @Produce      text/event-stream
+// @Security     BearerAuth
+// @Param        ticket query string false "Stream ticket, for clients that cannot set the Authorization header"
+// @Param        Last-Event-ID header int false "ID of the last event received"
+// @Param        last_event_id query int false "ID of the last event received, for clients reconnecting with a new ticket"
+// @Success      200 {string} string "Event stream"
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Router       /notifications/stream [get]
+func StreamEvents(c *gin.Context) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+
+	var lastEventID uint64
+	// a new ticket means a new EventSource, which doesn't send Last-Event-ID, so clients pass it along themselves
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	if lastID != "" {
+		var err error
+		if lastEventID, err = strconv.ParseUint(lastID, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// subscribe before replaying so nothing published in between is lost
+	subscription := services.Events.Subscribe(userID)
+	defer services.Events.Unsubscribe(subscription)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	write := func(event sse.Event) bool {
+		if err := sse.Encode(c.Writer, event); err != nil {
+			return false
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	// a retry field on its own sets the reconnection delay without dispatching an event
+	if _, err := fmt.Fprintf(c.Writer, "retry:%d\n\n", streamRetryDelay); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	// replay notifications missed while disconnected
+	if lastEventID > 0 {
+		var missed []models.Notification
+		database.DB.Where("user_id = ? AND id > ?", userID, lastEventID).Order("id").Limit(maxStreamReplay + 1).Find(&missed)
+		if len(missed) > maxStreamReplay {
+			// rather than dropping what doesn't fit, have the client reload its state and resume from the latest ID
+			var latest uint
+			database.DB.Model(&models.Notification{}).Where("user_id = ?", userID).Select("COALESCE(MAX(id), 0)").Scan(&latest)
+			reset := MessageResponse{Message: "Too many events were missed, refetch notifications"}
+			if !write(sse.Event{Id: strconv.FormatUint(uint64(latest), 10), Event: services.EventReset, Data: reset}) {
+				return
+			}
+			lastEventID = uint64(latest)
+			missed = nil
+		}
+		for _, notification := range missed {
+			if !write(sse.Event{Id: strconv.FormatUint(uint64(notification.ID), 10), Event: services.EventNotification, Data: toNotificationDetail(notification)}) {
+				return
+			}
+			lastEventID = uint64(notification.ID)
+		}
+	}
+
+	// the stream outlives the request's authentication, so recheck it on every heartbeat: ending the session,
+	// changing the password or disabling the account closes the stream too
+	sessionID := c.GetUint(utils.SessionIDKey)
+	var issuedAt *time.Time
+	if value, ok := c.Get(utils.TokenIssuedAtKey); ok {
+		if t, ok := value.(time.Time); ok {
+			issuedAt = &t
+		}
+	}
+
+	heartbeat := time.NewTicker(StreamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			// the hub closes subscriptions that fall behind; the client reconnects and replays
+			if !ok {
+				return
+			}
+
+			message := sse.Event{Event: event.Type, Data: toStreamData(event)}
+			if event.ID != 0 {
+				// skip notifications already sent during the replay
+				if uint64(event.ID) <= lastEventID {
+					continue
+				}
+				lastEventID = uint64(event.ID)
+				message.Id = strconv.FormatUint(lastEventID, 10)
+			}
+			if !write(message) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := services.CheckTokenAccess(database.DB, userID, sessionID, issuedAt, time.Now()); err != nil {
+				return
+			}
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package controllers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+)
+
+type streamEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// readStreamEvent reads the next event with data from an SSE stream, skipping comments and retry hints
+func readStreamEvent(t *testing.T, reader *bufio.Reader) streamEvent {
+	var event streamEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream closed: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+
+		switch {
+		case line == "":
+			if event.Data != "" {
+				return event
+			}
+			event = streamEvent{}
+		case strings.HasPrefix(line, "id:"):
+			event.ID = strings.TrimPrefix(line, "id:")
+		case strings.HasPrefix(line, "event:"):
+			event.Event = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			event.Data += strings.TrimPrefix(line, "data:")
+		}
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	setupProjectsTest(t)
+
+	user := models.User{Email: "stream_user@example.com", Password: "password"}
+	database.DB.Create(&user)
+	project := models.Project{Title: "Stream Project", OwnerID: user.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+
+	var seen []models.Notification
+	for _, message := range []string{"first", "second", "third"} {
+		notification := models.Notification{UserID: user.ID, Type: models.NotificationMention, Message: message}
+		database.DB.Create(&notification)
+		seen = append(seen, notification)
+	}
+	token, _ := utils.GenerateJWT(user.ID, user.Email)
+
+	router := gin.Default()
+	router.GET("/notifications/stream", middleware.StreamAuthRequired(), controllers.StreamEvents)
+	router.POST("/notifications/stream/ticket", middleware.AuthRequired(), controllers.IssueStreamTicket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// unauthenticated clients are rejected
+	resp, err := http.Get(server.URL + "/notifications/stream")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	// tokens aren't accepted in the URL, only short-lived tickets that can do nothing but open the stream
+	resp, err = http.Get(server.URL + "/notifications/stream?token=" + token)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+	expired := services.StreamTicket(user.ID, 0, time.Now().Add(-services.StreamTicketTTL-time.Second))
+	resp, err = http.Get(server.URL + "/notifications/stream?ticket=" + url.QueryEscape(expired))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	req, _ := http.NewRequest("POST", server.URL+"/notifications/stream/ticket", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	var ticket controllers.StreamTicketResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&ticket))
+	resp.Body.Close()
+	assert.NotEmpty(t, ticket.Ticket)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// reconnecting with a new ticket passes the last event ID in the query
+	req, _ = http.NewRequestWithContext(ctx, "GET", server.URL+"/notifications/stream?ticket="+url.QueryEscape(ticket.Ticket)+
+		"&last_event_id="+strconv.FormatUint(uint64(seen[0].ID), 10), nil)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+	reader := bufio.NewReader(resp.Body)
+
+	// notifications after Last-Event-ID are replayed in order
+	for _, expected := range seen[1:] {
+		event := readStreamEvent(t, reader)
+		assert.Equal(t, strconv.FormatUint(uint64(expected.ID), 10), event.ID)
+		assert.Equal(t, services.EventNotification, event.Event)
+
+		var detail controllers.NotificationDetail
+		assert.NoError(t, json.Unmarshal([]byte(event.Data), &detail))
+		assert.Equal(t, expected.Message, detail.Message)
+	}
+
+	// wait until the stream has subscribed before publishing live events
+	deadline := time.Now().Add(2 * time.Second)
+	for services.Events.SubscriberCount(user.ID) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	notification, err := services.Notify(database.DB, models.Notification{UserID: user.ID, Type: models.NotificationMention, Message: "live"})
+	assert.NoError(t, err)
+	services.PublishNotifications(notification)
+	event := readStreamEvent(t, reader)
+	assert.Equal(t, services.EventNotification, event.Event)
+	assert.Contains(t, event.Data, `"message":"live"`)
+
+	// project events carry no ID
+	services.PublishProjectEvent(database.DB, project.ID, services.EventCollaboratorAdded, services.CollaboratorEvent{ProjectID: project.ID, UserID: 42, Role: "programmer"})
+	event = readStreamEvent(t, reader)
+	assert.Equal(t, services.EventCollaboratorAdded, event.Event)
+	assert.Empty(t, event.ID)
+	assert.Contains(t, event.Data, `"user_id":42`)
+
+	// closing the connection releases the subscription
+	cancel()
+	deadline = time.Now().Add(2 * time.Second)
+	for services.Events.SubscriberCount(user.ID) != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, services.Events.SubscriberCount(user.ID))
+}
+
+func TestStreamEventsResetsAfterTooManyMissed(t *testing.T) {
+	setupProjectsTest(t)
+
+	user := models.User{Email: "stream_reset@example.com", Password: "password"}
+	database.DB.Create(&user)
+	var notifications []models.Notification
+	for i := 0; i < 102; i++ {
+		notifications = append(notifications, models.Notification{UserID: user.ID, Type: models.NotificationMention, Message: "missed"})
+	}
+	database.DB.Create(&notifications)
+	token, _ := utils.GenerateJWT(user.ID, user.Email)
+
+	router := gin.Default()
+	router.GET("/notifications/stream", middleware.StreamAuthRequired(), controllers.StreamEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/notifications/stream", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Last-Event-ID", strconv.FormatUint(uint64(notifications[0].ID), 10))
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	// more notifications were missed than are replayed, so the client is told to refetch and resumes from the latest
+	event := readStreamEvent(t, reader)
+	assert.Equal(t, services.EventReset, event.Event)
+	assert.Equal(t, strconv.FormatUint(uint64(notifications[len(notifications)-1].ID), 10), event.ID)
+}
+
+func TestStreamEventsClosesWhenSessionEnds(t *testing.T) {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Session{})
+
+	user := models.User{Email: "stream_session@example.com", Password: "password"}
+	database.DB.Create(&user)
+	session, err := services.StartSession(database.DB, user.ID, "Firefox", "127.0.0.1", time.Now())
+	assert.NoError(t, err)
+	token, _ := utils.GenerateSessionJWT(user.ID, user.Email, session.ID)
+
+	interval := controllers.StreamHeartbeatInterval
+	controllers.StreamHeartbeatInterval = 20 * time.Millisecond
+	defer func() { controllers.StreamHeartbeatInterval = interval }()
+
+	router := gin.Default()
+	router.GET("/notifications/stream", middleware.StreamAuthRequired(), controllers.StreamEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/notifications/stream", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	reader := bufio.NewReader(resp.Body)
+
+	// heartbeats keep coming while the session is active
+	line, err := reader.ReadString('\n')
+	for err == nil && !strings.HasPrefix(line, ": heartbeat") {
+		line, err = reader.ReadString('\n')
+	}
+	assert.NoError(t, err)
+
+	// signing the device out closes its open stream at the next heartbeat
+	assert.NoError(t, services.EndSession(database.DB, user.ID, session.ID, time.Now()))
+	for err == nil {
+		_, err = reader.ReadString('\n')
+	}
+	assert.ErrorIs(t, err, io.EOF)
+	assert.NoError(t, ctx.Err())
+}
@@ -0,0 +1,664 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GroupRequest struct {
+	Name        string `json:"name" binding:"required" example:"Computational Biology Lab"`
+	Description string `json:"description"`
+	// registered institution the group belongs to
+	InstitutionID *uint `json:"institution_id" example:"1"`
+	// role members get on the group's projects, programmer when left out
+	DefaultProjectRole string `json:"default_project_role" binding:"omitempty,oneof=programmer editor" example:"programmer"`
+}
+
+type GroupMemberDetail struct {
+	UserID    uint             `json:"user_id"`
+	FullName  string           `json:"full_name"`
+	AvatarURL string           `json:"avatar_url"`
+	Role      models.GroupRole `json:"role" example:"manager"`
+	JoinedAt  time.Time        `json:"joined_at"`
+}
+
+type GroupDetail struct {
+	ID                 uint                `json:"id"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description"`
+	InstitutionID      *uint               `json:"institution_id"`
+	DefaultProjectRole string              `json:"default_project_role"`
+	Members            []GroupMemberDetail `json:"members"`
+	// number of projects the group owns
+	Projects int64 `json:"projects"`
+}
+
+type GroupSummary struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	InstitutionID *uint  `json:"institution_id"`
+	Members       int64  `json:"members"`
+}
+
+type GroupListResponse struct {
+	Groups   []GroupSummary `json:"groups"`
+	Page     int            `json:"page" example:"1"`
+	PageSize int            `json:"page_size" example:"20"`
+	Total    int64          `json:"total" example:"4"`
+}
+
+type GroupProjectListResponse struct {
+	Projects []ProjectRetrievalResponse `json:"projects"`
+	Page     int                        `json:"page" example:"1"`
+	PageSize int                        `json:"page_size" example:"20"`
+	Total    int64                      `json:"total" example:"4"`
+}
+
+type GroupMemberRequest struct {
+	UserID uint   `json:"user_id" binding:"required" example:"42"`
+	Role   string `json:"role" binding:"required,oneof=pi manager member" example:"member"`
+}
+
+type GroupMemberRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=pi manager member" example:"manager"`
+}
+
+// groupDetail describes a group with its members, which must be loaded with their profiles
+func groupDetail(db *gorm.DB, group models.Group) (GroupDetail, error) {
+	detail := GroupDetail{
+		ID:                 group.ID,
+		Name:               group.Name,
+		Description:        group.Description,
+		InstitutionID:      group.InstitutionID,
+		DefaultProjectRole: string(group.DefaultProjectRole),
+		Members:            make([]GroupMemberDetail, len(group.Members)),
+	}
+	for i, member := range group.Members {
+		detail.Members[i] = GroupMemberDetail{
+			UserID:    member.UserID,
+			FullName:  member.User.FullName,
+			AvatarURL: avatarURL(member.User),
+			Role:      member.Role,
+			JoinedAt:  member.CreatedAt,
+		}
+	}
+	err := db.Model(&models.Project{}).Where("group_id = ?", group.ID).Count(&detail.Projects).Error
+	return detail, err
+}
+
+// findGroup fetches a group with its members and their profiles
+func findGroup(id interface{}) (models.Group, error) {
+	var group models.Group
+	err := database.DB.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Members.User").First(&group, id).Error
+	return group, err
+}
+
+// loadGroup fetches the group of the id path parameter with its members, writing a 404 response when it doesn't exist
+func loadGroup(c *gin.Context) (models.Group, bool) {
+	group, err := findGroup(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+		return group, false
+	}
+	return group, true
+}
+
+// requireGroupRole loads the group of the id path parameter and verifies that the authenticated user holds at
+// least minRole in it. On failure the error response is written and ok is false.
+func requireGroupRole(c *gin.Context, minRole models.GroupRole, forbiddenMessage string) (group models.Group, role models.GroupRole, ok bool) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return group, role, false
+	}
+	if group, ok = loadGroup(c); !ok {
+		return group, role, false
+	}
+	role = services.GroupRoleOf(database.DB, group.ID, userID)
+	if !role.AtLeast(minRole) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: forbiddenMessage})
+		return group, role, false
+	}
+	return group, role, true
+}
+
+// bindGroup validates a group request, writing a 400 response when it is invalid
+func bindGroup(c *gin.Context) (GroupRequest, bool) {
+	var request GroupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return request, false
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Name is required"})
+		return request, false
+	}
+	if request.DefaultProjectRole == "" {
+		request.DefaultProjectRole = string(models.CollaboratorRoleProgrammer)
+	}
+	if request.InstitutionID != nil {
+		var institution models.Institution
+		if err := database.DB.First(&institution, *request.InstitutionID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Institution not found"})
+			return request, false
+		}
+	}
+	return request, true
+}
+
+// respondWithGroup reloads a group and writes it as the response
+func respondWithGroup(c *gin.Context, groupID uint, status int) {
+	group, err := findGroup(groupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch group"})
+		return
+	}
+	detail, err := groupDetail(database.DB, group)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch group"})
+		return
+	}
+	c.JSON(status, detail)
+}
+
+// ListGroups godoc
+// @Summary      List research groups
+// @Description  Lists research groups and labs alphabetically, optionally searching by name or filtering by institution.
+// @Tags         Groups
+// @Produce      json
+// @Param        q query string false "Search by name"
+// @Param        institution_id query int false "Only groups of this institution"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Groups per page" default(20)
+// @Success      200 {object} GroupListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups [get]
+func ListGroups(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.Group{})
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+	if institution := c.Query("institution_id"); institution != "" {
+		institutionID, err := strconv.ParseUint(institution, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid institution ID"})
+			return
+		}
+		query = query.Where("institution_id = ?", institutionID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch groups"})
+		return
+	}
+
+	var groups []models.Group
+	if err := query.Order("name").Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&groups).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch groups"})
+		return
+	}
+
+	response := GroupListResponse{Groups: make([]GroupSummary, len(groups)), Page: page, PageSize: pageSize, Total: total}
+	for i, group := range groups {
+		summary := GroupSummary{ID: group.ID, Name: group.Name, Description: group.Description, InstitutionID: group.InstitutionID}
+		if err := database.DB.Model(&models.GroupMember{}).Where("group_id = ?", group.ID).Count(&summary.Members).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch groups"})
+			return
+		}
+		response.Groups[i] = summary
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGroup godoc
+// @Summary      Get a research group
+// @Description  Retrieves a research group with its members and their group roles.
+// @Tags         Groups
+// @Produce      json
+// @Param        id path int true "Group ID"
+// @Success      200 {object} GroupDetail
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id} [get]
+func GetGroup(c *gin.Context) {
+	group, ok := loadGroup(c)
+	if !ok {
+		return
+	}
+	detail, err := groupDetail(database.DB, group)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch group"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// CreateGroup godoc
+// @Summary      Create a research group
+// @Description  Creates a research group or lab with the authenticated user as its PI.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body GroupRequest true "Group"
+// @Success      201 {object} GroupDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups [post]
+func CreateGroup(c *gin.Context) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+	request, ok := bindGroup(c)
+	if !ok {
+		return
+	}
+
+	group := models.Group{
+		Name:               request.Name,
+		Description:        request.Description,
+		InstitutionID:      request.InstitutionID,
+		DefaultProjectRole: models.CollaboratorRole(request.DefaultProjectRole),
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.GroupMember{GroupID: group.ID, UserID: userID, Role: models.GroupRolePI}).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditGroupCreated, TargetType: "group", TargetID: group.ID,
+			After: request,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create group"})
+		return
+	}
+
+	respondWithGroup(c, group.ID, http.StatusCreated)
+}
+
+// UpdateGroup godoc
+// @Summary      Update a research group
+// @Description  Changes the name, description, institution or default project role of a group. Only group PIs and managers can update a group.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Param        request body GroupRequest true "Group"
+// @Success      200 {object} GroupDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id} [put]
+func UpdateGroup(c *gin.Context) {
+	group, _, ok := requireGroupRole(c, models.GroupRoleManager, "Only group PIs and managers can update the group")
+	if !ok {
+		return
+	}
+	request, ok := bindGroup(c)
+	if !ok {
+		return
+	}
+
+	before := GroupRequest{Name: group.Name, Description: group.Description, InstitutionID: group.InstitutionID, DefaultProjectRole: string(group.DefaultProjectRole)}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&group).Omit("Members").Updates(map[string]interface{}{
+			"name": request.Name, "description": request.Description, "institution_id": request.InstitutionID, "default_project_role": request.DefaultProjectRole,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditGroupUpdated, TargetType: "group", TargetID: group.ID,
+			Before: before, After: request,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update group"})
+		return
+	}
+
+	respondWithGroup(c, group.ID, http.StatusOK)
+}
+
+// DeleteGroup godoc
+// @Summary      Delete a research group
+// @Description  Deletes a group. Its projects stay with their owners but are no longer shared with the group's members. Only group PIs can delete a group.
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id} [delete]
+func DeleteGroup(c *gin.Context) {
+	group, _, ok := requireGroupRole(c, models.GroupRolePI, "Only group PIs can delete the group")
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := errors.Join(
+			tx.Model(&models.Project{}).Where("group_id = ?", group.ID).Update("group_id", nil).Error,
+			tx.Where("group_id = ?", group.ID).Delete(&models.GroupMember{}).Error,
+			tx.Delete(&group).Error,
+		)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditGroupDeleted, TargetType: "group", TargetID: group.ID,
+			Before: gin.H{"name": group.Name},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Group deleted successfully"})
+}
+
+// ListGroupProjects godoc
+// @Summary      List group projects
+// @Description  Lists the projects a group owns, newest first. Group members see every project, others only public ones. Projects hidden by moderators are left out.
+// @Tags         Groups
+// @Produce      json
+// @Param        id path int true "Group ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Projects per page" default(20)
+// @Success      200 {object} GroupProjectListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id}/projects [get]
+func ListGroupProjects(c *gin.Context) {
+	var group models.Group
+	if err := database.DB.First(&group, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.Project{}).Where("group_id = ? AND hidden_at IS NULL", group.ID)
+	if services.GroupRoleOf(database.DB, group.ID, utils.InferUserID(c)) == "" {
+		query = query.Where("visibility = ?", models.ProjectVisibilityPublic)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch projects"})
+		return
+	}
+
+	var projects []models.Project
+	if err := query.Order("created_at DESC").Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch projects"})
+		return
+	}
+
+	response := GroupProjectListResponse{Projects: make([]ProjectRetrievalResponse, len(projects)), Page: page, PageSize: pageSize, Total: total}
+	for i, project := range projects {
+		response.Projects[i] = ProjectRetrievalResponse{
+			ID:             project.ID,
+			Title:          project.Title,
+			Description:    project.Description,
+			RequiredSkills: project.GetRequiredSkills(),
+			Visibility:     project.Visibility,
+			Status:         project.Status,
+			OwnerID:        project.OwnerID,
+			GroupID:        project.GroupID,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AddGroupMember godoc
+// @Summary      Add a group member
+// @Description  Adds a user to a group. Members collaborate on the group's projects with its default project role, PIs and managers share the owner's permissions. Managers can add members, only PIs can add managers and PIs.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Param        request body GroupMemberRequest true "Member"
+// @Success      201 {object} GroupDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id}/members [post]
+func AddGroupMember(c *gin.Context) {
+	var request GroupMemberRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	group, actorRole, ok := requireGroupRole(c, models.GroupRoleManager, "Only group PIs and managers can add members")
+	if !ok {
+		return
+	}
+	role := models.GroupRole(request.Role)
+	if role.AtLeast(models.GroupRoleManager) && !actorRole.AtLeast(models.GroupRolePI) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only group PIs can add managers and PIs"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, request.UserID).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	actorID := utils.InferUserID(c)
+	// users can't be added by someone they blocked
+	if services.HasBlocked(database.DB, user.ID, actorID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You cannot add this user"})
+		return
+	}
+	if services.GroupRoleOf(database.DB, group.ID, user.ID) != "" {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "User is already a member of the group"})
+		return
+	}
+
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.GroupMember{GroupID: group.ID, UserID: user.ID, Role: role}).Error; err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditGroupMemberAdded, TargetType: "user", TargetID: user.ID,
+			After: gin.H{"group_id": group.ID, "role": role},
+		})
+		if err != nil {
+			return err
+		}
+		notification, err = services.NotifyGroupMembership(tx, group, user.ID, actorID, role, true)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add member"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	respondWithGroup(c, group.ID, http.StatusCreated)
+}
+
+// findGroupMember loads the member of a group from the "userId" URL parameter, writing a 404 response when there is none
+func findGroupMember(c *gin.Context, groupID uint) (models.GroupMember, bool) {
+	var member models.GroupMember
+	if err := database.DB.Where("group_id = ? AND user_id = ?", groupID, c.Param("userId")).First(&member).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Member not found"})
+		return member, false
+	}
+	return member, true
+}
+
+// UpdateGroupMemberRole godoc
+// @Summary      Change a group member's role
+// @Description  Changes the role of a group member. Only group PIs can change roles, and a group always keeps at least one PI.
+// @Tags         Groups
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Param        userId path int true "Member user ID"
+// @Param        request body GroupMemberRoleRequest true "New role"
+// @Success      200 {object} GroupDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id}/members/{userId} [put]
+func UpdateGroupMemberRole(c *gin.Context) {
+	var request GroupMemberRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	group, _, ok := requireGroupRole(c, models.GroupRolePI, "Only group PIs can change member roles")
+	if !ok {
+		return
+	}
+	member, ok := findGroupMember(c, group.ID)
+	if !ok {
+		return
+	}
+	role := models.GroupRole(request.Role)
+	if member.Role == role {
+		respondWithGroup(c, group.ID, http.StatusOK)
+		return
+	}
+
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.CheckGroupKeepsPI(tx, member); err != nil {
+			return err
+		}
+		before := member.Role
+		if err := tx.Model(&member).Update("role", role).Error; err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditGroupMemberRoleChanged, TargetType: "user", TargetID: member.UserID,
+			Before: gin.H{"group_id": group.ID, "role": before}, After: gin.H{"group_id": group.ID, "role": role},
+		})
+		if err != nil {
+			return err
+		}
+		notification, err = services.NotifyGroupMembership(tx, group, member.UserID, utils.InferUserID(c), role, false)
+		return err
+	})
+	if errors.Is(err, services.ErrLastGroupPI) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The group needs at least one PI"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update member role"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	respondWithGroup(c, group.ID, http.StatusOK)
+}
+
+// RemoveGroupMember godoc
+// @Summary      Remove a group member
+// @Description  Removes a user from a group, who then loses the access to group projects the group granted. Members can leave on their own, managers can remove members and PIs anyone. A group always keeps at least one PI.
+// @Tags         Groups
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Group ID"
+// @Param        userId path int true "Member user ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /groups/{id}/members/{userId} [delete]
+func RemoveGroupMember(c *gin.Context) {
+	group, actorRole, ok := requireGroupRole(c, models.GroupRoleMember, "Only group members can remove members")
+	if !ok {
+		return
+	}
+	member, ok := findGroupMember(c, group.ID)
+	if !ok {
+		return
+	}
+
+	// managers can remove members but not each other
+	leaving := member.UserID == utils.InferUserID(c)
+	if !leaving && !(actorRole.AtLeast(models.GroupRolePI) || (actorRole == models.GroupRoleManager && member.Role == models.GroupRoleMember)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You cannot remove this member"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.CheckGroupKeepsPI(tx, member); err != nil {
+			return err
+		}
+		if err := tx.Delete(&member).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditGroupMemberRemoved, TargetType: "user", TargetID: member.UserID,
+			Before: gin.H{"group_id": group.ID, "role": member.Role},
+		})
+	})
+	if errors.Is(err, services.ErrLastGroupPI) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The group needs at least one PI"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove member"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Member removed successfully"})
+}
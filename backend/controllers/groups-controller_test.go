@@ -0,0 +1,168 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+func TestResearchGroups(t *testing.T) {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+
+	router := gin.Default()
+	groups := router.Group("/groups")
+	groups.GET("/:id", controllers.GetGroup)
+	groups.POST("", middleware.AuthRequired(), controllers.CreateGroup)
+	groups.PUT("/:id", middleware.AuthRequired(), controllers.UpdateGroup)
+	groups.DELETE("/:id", middleware.AuthRequired(), controllers.DeleteGroup)
+	groups.GET("/:id/projects", middleware.OptionalAuth(), controllers.ListGroupProjects)
+	groups.POST("/:id/members", middleware.AuthRequired(), controllers.AddGroupMember)
+	groups.PUT("/:id/members/:userId", middleware.AuthRequired(), controllers.UpdateGroupMemberRole)
+	groups.DELETE("/:id/members/:userId", middleware.AuthRequired(), controllers.RemoveGroupMember)
+	router.GET("/projects/user", middleware.AuthRequired(), controllers.ListUserProjects)
+	router.POST("/projects", middleware.AuthRequired(), controllers.CreateProject)
+	router.PUT("/projects/:id/status", middleware.AuthRequired(), controllers.UpdateProjectStatus)
+	router.PUT("/projects/:id/group", middleware.AuthRequired(), controllers.SetProjectGroup)
+	router.GET("/projects/:id/tasks", middleware.AuthRequired(), controllers.ListTasks)
+
+	tokens := make(map[string]string)
+	users := make(map[string]models.User)
+	for _, name := range []string{"pi", "manager", "student", "outsider"} {
+		user := models.User{Email: name + "@example.com", Password: "hash"}
+		database.DB.Create(&user)
+		database.DB.Create(&models.UserProfile{UserID: user.ID, FullName: name})
+		users[name] = user
+		tokens[name], _ = utils.GenerateJWT(user.ID, user.Email)
+	}
+
+	// the creator becomes the PI
+	w := projectRequest(router, "POST", "/groups", tokens["pi"], map[string]interface{}{"name": "Systems Lab"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var group controllers.GroupDetail
+	json.Unmarshal(w.Body.Bytes(), &group)
+	assert.Equal(t, "programmer", group.DefaultProjectRole)
+	if assert.Len(t, group.Members, 1) {
+		assert.Equal(t, models.GroupRolePI, group.Members[0].Role)
+	}
+	groupPath := fmt.Sprintf("/groups/%d", group.ID)
+
+	// managers add members, only PIs add managers
+	w = projectRequest(router, "POST", groupPath+"/members", tokens["outsider"], map[string]interface{}{"user_id": users["student"].ID, "role": "member"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", groupPath+"/members", tokens["pi"], map[string]interface{}{"user_id": users["manager"].ID, "role": "manager"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(router, "POST", groupPath+"/members", tokens["manager"], map[string]interface{}{"user_id": users["student"].ID, "role": "manager"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", groupPath+"/members", tokens["manager"], map[string]interface{}{"user_id": users["student"].ID, "role": "member"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(router, "POST", groupPath+"/members", tokens["manager"], map[string]interface{}{"user_id": users["student"].ID, "role": "member"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var notifications int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", users["student"].ID, models.NotificationGroupMembership).Count(&notifications)
+	assert.Equal(t, int64(1), notifications)
+
+	// only PIs and managers create projects in the group
+	w = projectRequest(router, "POST", "/projects", tokens["outsider"], map[string]interface{}{"title": "Intruder", "visibility": "public", "status": "open", "group_id": group.ID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", "/projects", tokens["manager"], map[string]interface{}{"title": "Kernel", "visibility": "private", "status": "open", "group_id": group.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created controllers.ProjectCreationResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	projectPath := fmt.Sprintf("/projects/%d", created.ID)
+
+	// members collaborate with the default role, the PI has owner permissions
+	w = projectRequest(router, "GET", projectPath+"/tasks", tokens["student"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "GET", projectPath+"/tasks", tokens["outsider"], nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", projectPath+"/status", tokens["student"], map[string]interface{}{"status": "in-progress"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", projectPath+"/status", tokens["pi"], map[string]interface{}{"status": "in-progress"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var mine controllers.ProjectListResponse
+	w = projectRequest(router, "GET", "/projects/user", tokens["student"], nil)
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	if assert.Len(t, mine.Projects, 1) {
+		assert.Equal(t, group.ID, *mine.Projects[0].GroupID)
+	}
+
+	// private group projects are only listed for members
+	var listed controllers.GroupProjectListResponse
+	w = projectRequest(router, "GET", groupPath+"/projects", tokens["student"], nil)
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	assert.Equal(t, int64(1), listed.Total)
+	listed = controllers.GroupProjectListResponse{}
+	w = projectRequest(router, "GET", groupPath+"/projects", "", nil)
+	json.Unmarshal(w.Body.Bytes(), &listed)
+	assert.Equal(t, int64(0), listed.Total)
+
+	w = projectRequest(router, "PUT", groupPath, tokens["student"], map[string]interface{}{"name": "Systems Lab", "default_project_role": "editor"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", groupPath, tokens["manager"], map[string]interface{}{"name": "Systems Lab", "default_project_role": "owner"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "PUT", groupPath, tokens["manager"], map[string]interface{}{"name": "Systems Lab", "default_project_role": "editor"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	group = controllers.GroupDetail{}
+	json.Unmarshal(w.Body.Bytes(), &group)
+	assert.Equal(t, "editor", group.DefaultProjectRole)
+	assert.Equal(t, int64(1), group.Projects)
+
+	// a group keeps at least one PI
+	piPath := fmt.Sprintf("%s/members/%d", groupPath, users["pi"].ID)
+	managerPath := fmt.Sprintf("%s/members/%d", groupPath, users["manager"].ID)
+	studentPath := fmt.Sprintf("%s/members/%d", groupPath, users["student"].ID)
+	w = projectRequest(router, "PUT", piPath, tokens["manager"], map[string]interface{}{"role": "member"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", piPath, tokens["pi"], map[string]interface{}{"role": "member"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = projectRequest(router, "DELETE", piPath, tokens["pi"], nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = projectRequest(router, "DELETE", piPath, tokens["manager"], nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", managerPath, tokens["pi"], map[string]interface{}{"role": "pi"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "DELETE", piPath, tokens["pi"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// removed members lose the access the group granted
+	w = projectRequest(router, "DELETE", studentPath, tokens["manager"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "GET", projectPath+"/tasks", tokens["student"], nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// projects move into a group only by one of its managers
+	w = projectRequest(router, "POST", "/projects", tokens["outsider"], map[string]interface{}{"title": "Side project", "visibility": "public", "status": "open"})
+	json.Unmarshal(w.Body.Bytes(), &created)
+	sidePath := fmt.Sprintf("/projects/%d/group", created.ID)
+	w = projectRequest(router, "PUT", sidePath, tokens["outsider"], map[string]interface{}{"group_id": group.ID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", sidePath, tokens["outsider"], map[string]interface{}{"group_id": 9999})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(router, "PUT", sidePath, tokens["manager"], map[string]interface{}{"group_id": group.ID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// deleting the group leaves its projects with their owners
+	w = projectRequest(router, "DELETE", groupPath, tokens["outsider"], nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "DELETE", groupPath, tokens["manager"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var project models.Project
+	database.DB.First(&project, projectPath[len("/projects/"):])
+	assert.Nil(t, project.GroupID)
+	assert.Equal(t, users["manager"].ID, project.OwnerID)
+	w = projectRequest(router, "GET", groupPath, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,666 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	// errDomainTaken is returned when a domain is already registered to another institution
+	errDomainTaken = errors.New("domain already registered")
+	// errInstitutionNameTaken is returned when another institution has the same name
+	errInstitutionNameTaken = errors.New("institution name already registered")
+)
+
+type InstitutionRequest struct {
+	Name string `json:"name" binding:"required" example:"University of Florida"`
+	// other names the institution goes by, used to match free-text affiliations
+	Aliases []string `json:"aliases" example:"UF"`
+	City    string   `json:"city" example:"Gainesville"`
+	Region  string   `json:"region" example:"FL"`
+	Country string   `json:"country" example:"US"`
+	// email domains of the institution; subdomains are included automatically
+	Domains []string `json:"domains" binding:"required,min=1" example:"ufl.edu"`
+}
+
+type InstitutionDetail struct {
+	ID          uint               `json:"id"`
+	Name        string             `json:"name"`
+	Aliases     []string           `json:"aliases"`
+	City        string             `json:"city"`
+	Region      string             `json:"region"`
+	Country     string             `json:"country"`
+	Domains     []string           `json:"domains"`
+	Departments []DepartmentDetail `json:"departments"`
+	// number of users whose verified email belongs to the institution
+	VerifiedMembers int64 `json:"verified_members"`
+	// number of verified members and users whose affiliation is linked to the institution
+	Members int64 `json:"members"`
+}
+
+type InstitutionListResponse struct {
+	Institutions []InstitutionDetail `json:"institutions"`
+	Page         int                 `json:"page" example:"1"`
+	PageSize     int                 `json:"page_size" example:"20"`
+	Total        int64               `json:"total" example:"4"`
+}
+
+// InstitutionBadge marks a profile as a verified member of an institution
+type InstitutionBadge struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+// institutionBadge returns the verification badge of a profile, nil when the user isn't a verified member of any institution
+func institutionBadge(profile models.UserProfile) *InstitutionBadge {
+	if profile.Institution == nil {
+		return nil
+	}
+	return &InstitutionBadge{ID: profile.Institution.ID, Name: profile.Institution.Name, VerifiedAt: profile.InstitutionVerifiedAt}
+}
+
+// AffiliationLink is the registered institution and department a self-declared affiliation refers to
+type AffiliationLink struct {
+	InstitutionID   uint   `json:"institution_id"`
+	InstitutionName string `json:"institution_name"`
+	DepartmentID    *uint  `json:"department_id"`
+	DepartmentName  string `json:"department_name"`
+}
+
+// affiliationLink returns the institution and department a profile's affiliation is linked to, nil when it isn't linked
+func affiliationLink(profile models.UserProfile) *AffiliationLink {
+	if profile.AffiliationInstitution == nil {
+		return nil
+	}
+	link := &AffiliationLink{InstitutionID: profile.AffiliationInstitution.ID, InstitutionName: profile.AffiliationInstitution.Name}
+	if profile.Department != nil {
+		link.DepartmentID = &profile.Department.ID
+		link.DepartmentName = profile.Department.Name
+	}
+	return link
+}
+
+type InstitutionMember struct {
+	UserSummary
+	// true when the user's verified email belongs to the institution, false when only their affiliation names it
+	Verified   bool              `json:"verified"`
+	Department *DepartmentDetail `json:"department"`
+}
+
+type InstitutionMemberListResponse struct {
+	Members  []InstitutionMember `json:"members"`
+	Page     int                 `json:"page" example:"1"`
+	PageSize int                 `json:"page_size" example:"20"`
+	Total    int64               `json:"total" example:"4"`
+}
+
+type InstitutionProjectListResponse struct {
+	Projects []ProjectRetrievalResponse `json:"projects"`
+	Page     int                        `json:"page" example:"1"`
+	PageSize int                        `json:"page_size" example:"20"`
+	Total    int64                      `json:"total" example:"4"`
+}
+
+type AffiliationNormalizationResponse struct {
+	// profiles linked to an institution, or that would be on a dry run
+	Normalized []services.AffiliationNormalization `json:"normalized"`
+	// number of unlinked affiliations that matched no institution
+	Unmatched int  `json:"unmatched" example:"2"`
+	DryRun    bool `json:"dry_run"`
+}
+
+// institutionDetail describes an institution, which must have its domains and departments loaded
+func institutionDetail(db *gorm.DB, institution models.Institution) (InstitutionDetail, error) {
+	detail := InstitutionDetail{
+		ID:          institution.ID,
+		Name:        institution.Name,
+		Aliases:     institution.GetAliases(),
+		City:        institution.City,
+		Region:      institution.Region,
+		Country:     institution.Country,
+		Domains:     make([]string, len(institution.Domains)),
+		Departments: make([]DepartmentDetail, len(institution.Departments)),
+	}
+	for i, domain := range institution.Domains {
+		detail.Domains[i] = domain.Domain
+	}
+	for i, department := range institution.Departments {
+		detail.Departments[i] = departmentDetail(department)
+	}
+	err := errors.Join(
+		db.Model(&models.UserProfile{}).Where("institution_id = ?", institution.ID).Count(&detail.VerifiedMembers).Error,
+		institutionMembers(db, institution.ID).Count(&detail.Members).Error,
+	)
+	return detail, err
+}
+
+// institutionMembers selects the visible profiles of an institution's verified members and of users whose
+// affiliation is linked to it
+func institutionMembers(db *gorm.DB, institutionID uint) *gorm.DB {
+	return db.Model(&models.UserProfile{}).
+		Where("institution_id = ? OR affiliation_institution_id = ?", institutionID, institutionID).
+		Where("hidden_at IS NULL").
+		Where("user_id IN (?)", db.Model(&models.User{}).Select("id").Where("disabled_at IS NULL AND deletion_scheduled_at IS NULL"))
+}
+
+// cleanAliases trims aliases and drops empty ones, duplicates and repeats of the canonical name
+func cleanAliases(name string, aliases []string) []string {
+	seen := map[string]bool{strings.ToLower(name): true}
+	cleaned := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias != "" && !seen[strings.ToLower(alias)] {
+			seen[strings.ToLower(alias)] = true
+			cleaned = append(cleaned, alias)
+		}
+	}
+	return cleaned
+}
+
+// loadInstitution fetches the institution of the id path parameter with its domains and departments
+func loadInstitution(c *gin.Context, institution *models.Institution) error {
+	return database.DB.Preload("Domains").Preload("Departments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).First(institution, c.Param("id")).Error
+}
+
+// bindInstitution validates an institution request, writing a 400 response when it is invalid
+func bindInstitution(c *gin.Context) (InstitutionRequest, bool) {
+	var request InstitutionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return request, false
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Name is required"})
+		return request, false
+	}
+	request.Aliases = cleanAliases(request.Name, request.Aliases)
+
+	seen := make(map[string]bool)
+	domains := make([]string, 0, len(request.Domains))
+	for _, raw := range request.Domains {
+		domain, err := services.NormalizeDomain(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid domain: " + raw})
+			return request, false
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+	request.Domains = domains
+	return request, true
+}
+
+// saveInstitutionDomains replaces the domains of an institution and refreshes the membership of affected users
+func saveInstitutionDomains(tx *gorm.DB, institution *models.Institution, domains []string) error {
+	var taken int64
+	if err := tx.Model(&models.InstitutionDomain{}).Where("domain IN ? AND institution_id <> ?", domains, institution.ID).Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return errDomainTaken
+	}
+
+	previous := make([]string, len(institution.Domains))
+	for i, domain := range institution.Domains {
+		previous[i] = domain.Domain
+	}
+	if err := tx.Where("institution_id = ?", institution.ID).Delete(&models.InstitutionDomain{}).Error; err != nil {
+		return err
+	}
+	institution.Domains = make([]models.InstitutionDomain, len(domains))
+	for i, domain := range domains {
+		institution.Domains[i] = models.InstitutionDomain{InstitutionID: institution.ID, Domain: domain}
+	}
+	if err := tx.Create(&institution.Domains).Error; err != nil {
+		return err
+	}
+	return services.RefreshInstitutionMembers(tx, institution.ID, append(previous, domains...), time.Now())
+}
+
+// checkInstitutionName returns errInstitutionNameTaken when an institution other than id already uses the name
+func checkInstitutionName(tx *gorm.DB, name string, id uint) error {
+	var count int64
+	if err := tx.Model(&models.Institution{}).Where("LOWER(name) = ? AND id <> ?", strings.ToLower(name), id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errInstitutionNameTaken
+	}
+	return nil
+}
+
+// institutionSaveError writes the response for a failed create or update
+func institutionSaveError(c *gin.Context, err error) {
+	if errors.Is(err, errDomainTaken) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "A domain is already registered to another institution"})
+		return
+	}
+	if errors.Is(err, errInstitutionNameTaken) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "An institution with this name already exists"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save institution"})
+}
+
+// ListInstitutions godoc
+// @Summary      List institutions
+// @Description  Lists registered institutions alphabetically, optionally searching by name, alias or domain.
+// @Tags         Institutions
+// @Produce      json
+// @Param        q query string false "Search by name, alias or email domain"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Institutions per page" default(20)
+// @Success      200 {object} InstitutionListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /institutions [get]
+func ListInstitutions(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.Institution{})
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(aliases) LIKE ? OR id IN (SELECT institution_id FROM institution_domains WHERE domain LIKE ?)", pattern, pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institutions"})
+		return
+	}
+
+	var institutions []models.Institution
+	if err := query.Preload("Domains").Preload("Departments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name")
+	}).Order("name").Offset((page - 1) * pageSize).Limit(pageSize).Find(&institutions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institutions"})
+		return
+	}
+
+	response := InstitutionListResponse{Institutions: make([]InstitutionDetail, len(institutions)), Page: page, PageSize: pageSize, Total: total}
+	for i, institution := range institutions {
+		detail, err := institutionDetail(database.DB, institution)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institutions"})
+			return
+		}
+		response.Institutions[i] = detail
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetInstitution godoc
+// @Summary      Get an institution
+// @Description  Retrieves a registered institution with its aliases, location, email domains and departments.
+// @Tags         Institutions
+// @Produce      json
+// @Param        id path int true "Institution ID"
+// @Success      200 {object} InstitutionDetail
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /institutions/{id} [get]
+func GetInstitution(c *gin.Context) {
+	var institution models.Institution
+	if err := loadInstitution(c, &institution); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+
+	detail, err := institutionDetail(database.DB, institution)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institution"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// CreateInstitution godoc
+// @Summary      Register an institution
+// @Description  Adds an institution with its email domains. Users who already verified an address in one of the domains become verified members. Only administrators can manage institutions.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body InstitutionRequest true "Institution"
+// @Success      201 {object} InstitutionDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions [post]
+func CreateInstitution(c *gin.Context) {
+	request, ok := bindInstitution(c)
+	if !ok {
+		return
+	}
+
+	institution := models.Institution{Name: request.Name, City: request.City, Region: request.Region, Country: request.Country}
+	institution.SetAliases(request.Aliases)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkInstitutionName(tx, request.Name, 0); err != nil {
+			return err
+		}
+		if err := tx.Omit("Domains", "Departments").Create(&institution).Error; err != nil {
+			return err
+		}
+		if err := saveInstitutionDomains(tx, &institution, request.Domains); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditInstitutionCreated, TargetType: "institution", TargetID: institution.ID,
+			After: request,
+		})
+	})
+	if err != nil {
+		institutionSaveError(c, err)
+		return
+	}
+
+	detail, err := institutionDetail(database.DB, institution)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institution"})
+		return
+	}
+	c.JSON(http.StatusCreated, detail)
+}
+
+// UpdateInstitution godoc
+// @Summary      Update an institution
+// @Description  Renames an institution, changes its aliases or location, or replaces its email domains. Members whose address no longer matches lose their verified membership, users matching a new domain gain it. Only administrators can manage institutions.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Institution ID"
+// @Param        request body InstitutionRequest true "Institution"
+// @Success      200 {object} InstitutionDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions/{id} [put]
+func UpdateInstitution(c *gin.Context) {
+	var institution models.Institution
+	if err := loadInstitution(c, &institution); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+	request, ok := bindInstitution(c)
+	if !ok {
+		return
+	}
+
+	before, err := institutionDetail(database.DB, institution)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institution"})
+		return
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkInstitutionName(tx, request.Name, institution.ID); err != nil {
+			return err
+		}
+		institution.SetAliases(request.Aliases)
+		err := tx.Model(&institution).Omit("Domains", "Departments").Updates(map[string]interface{}{
+			"name": request.Name, "aliases": institution.Aliases, "city": request.City, "region": request.Region, "country": request.Country,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := saveInstitutionDomains(tx, &institution, request.Domains); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditInstitutionUpdated, TargetType: "institution", TargetID: institution.ID,
+			Before: before, After: request,
+		})
+	})
+	if err != nil {
+		institutionSaveError(c, err)
+		return
+	}
+
+	detail, err := institutionDetail(database.DB, institution)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institution"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// DeleteInstitution godoc
+// @Summary      Delete an institution
+// @Description  Removes an institution with its domains and departments. Its members lose their verified membership and affiliations linked to it are unlinked. Only administrators can manage institutions.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Institution ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions/{id} [delete]
+func DeleteInstitution(c *gin.Context) {
+	var institution models.Institution
+	if err := loadInstitution(c, &institution); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+
+	before, err := institutionDetail(database.DB, institution)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch institution"})
+		return
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := errors.Join(
+			tx.Model(&models.UserProfile{}).Where("institution_id = ?", institution.ID).
+				Updates(map[string]interface{}{"institution_id": nil, "institution_verified_at": nil}).Error,
+			tx.Model(&models.UserProfile{}).Where("affiliation_institution_id = ?", institution.ID).
+				Updates(map[string]interface{}{"affiliation_institution_id": nil, "department_id": nil}).Error,
+			tx.Where("institution_id = ?", institution.ID).Delete(&models.InstitutionDomain{}).Error,
+			tx.Where("institution_id = ?", institution.ID).Delete(&models.Department{}).Error,
+			tx.Model(&models.Group{}).Where("institution_id = ?", institution.ID).Update("institution_id", nil).Error,
+			tx.Delete(&institution).Error,
+		)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditInstitutionDeleted, TargetType: "institution", TargetID: institution.ID,
+			Before: before,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete institution"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Institution deleted successfully"})
+}
+
+// ListInstitutionMembers godoc
+// @Summary      List institution members
+// @Description  Lists the verified members of an institution together with users whose affiliation is linked to it, alphabetically. Hidden profiles and disabled accounts are left out.
+// @Tags         Institutions
+// @Produce      json
+// @Param        id path int true "Institution ID"
+// @Param        department_id query int false "Only members of this department"
+// @Param        verified query bool false "Only verified members"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Members per page" default(20)
+// @Success      200 {object} InstitutionMemberListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /institutions/{id}/members [get]
+func ListInstitutionMembers(c *gin.Context) {
+	var institution models.Institution
+	if err := database.DB.First(&institution, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := institutionMembers(database.DB, institution.ID)
+	if department := c.Query("department_id"); department != "" {
+		departmentID, err := strconv.ParseUint(department, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid department ID"})
+			return
+		}
+		query = query.Where("department_id = ?", departmentID)
+	}
+	if c.Query("verified") == "true" {
+		query = query.Where("institution_id = ?", institution.ID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch members"})
+		return
+	}
+
+	var profiles []models.UserProfile
+	err := query.Preload("Institution").Preload("AffiliationInstitution").Preload("Department").
+		Order("full_name").Order("user_id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&profiles).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch members"})
+		return
+	}
+
+	response := InstitutionMemberListResponse{Members: make([]InstitutionMember, len(profiles)), Page: page, PageSize: pageSize, Total: total}
+	for i, profile := range profiles {
+		member := InstitutionMember{
+			UserSummary: userSummary(profile),
+			Verified:    profile.InstitutionID != nil && *profile.InstitutionID == institution.ID,
+		}
+		// the department belongs to the affiliation, which may name another institution than the verified email
+		if profile.Department != nil && profile.Department.InstitutionID == institution.ID {
+			detail := departmentDetail(*profile.Department)
+			member.Department = &detail
+		}
+		response.Members[i] = member
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListInstitutionProjects godoc
+// @Summary      List institution projects
+// @Description  Lists the public projects owned by or involving members of an institution, newest first. Projects hidden by moderators are left out.
+// @Tags         Institutions
+// @Produce      json
+// @Param        id path int true "Institution ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Projects per page" default(20)
+// @Success      200 {object} InstitutionProjectListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /institutions/{id}/projects [get]
+func ListInstitutionProjects(c *gin.Context) {
+	var institution models.Institution
+	if err := database.DB.First(&institution, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	members := func() *gorm.DB { return institutionMembers(database.DB, institution.ID).Select("user_id") }
+	query := database.DB.Model(&models.Project{}).
+		Where("visibility = ? AND hidden_at IS NULL", models.ProjectVisibilityPublic).
+		Where("owner_id IN (?) OR id IN (?)", members(), database.DB.Model(&models.Collaborator{}).Select("project_id").Where("user_id IN (?)", members()))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch projects"})
+		return
+	}
+
+	var projects []models.Project
+	if err := query.Order("created_at DESC").Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&projects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch projects"})
+		return
+	}
+
+	response := InstitutionProjectListResponse{Projects: make([]ProjectRetrievalResponse, len(projects)), Page: page, PageSize: pageSize, Total: total}
+	for i, project := range projects {
+		response.Projects[i] = ProjectRetrievalResponse{
+			ID:             project.ID,
+			Title:          project.Title,
+			Description:    project.Description,
+			RequiredSkills: project.GetRequiredSkills(),
+			Visibility:     project.Visibility,
+			Status:         project.Status,
+			OwnerID:        project.OwnerID,
+			GroupID:        project.GroupID,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// NormalizeAffiliations godoc
+// @Summary      Normalize affiliations
+// @Description  Matches the free-text affiliation of every profile not yet linked to an institution against the names and aliases of registered institutions and their departments, tolerating abbreviations, word order and typos. Matched profiles are linked and their affiliation replaced with the canonical names. Use dry_run to preview the matches without saving them. Only administrators can manage institutions.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        dry_run query bool false "Only report the matches"
+// @Success      200 {object} AffiliationNormalizationResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/institutions/normalize-affiliations [post]
+func NormalizeAffiliations(c *gin.Context) {
+	response := AffiliationNormalizationResponse{DryRun: c.Query("dry_run") == "true"}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if response.Normalized, response.Unmatched, err = services.NormalizeAffiliations(tx, response.DryRun); err != nil {
+			return err
+		}
+		if response.DryRun || len(response.Normalized) == 0 {
+			return nil
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditAffiliationsNormalized, TargetType: "institution",
+			After: response.Normalized,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to normalize affiliations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
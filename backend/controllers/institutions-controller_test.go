@@ -0,0 +1,277 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+func TestInstitutionVerification(t *testing.T) {
+	f := setupAdminTest(t)
+	f.router.GET("/institutions", controllers.ListInstitutions)
+	f.router.GET("/institutions/:id", controllers.GetInstitution)
+	f.router.GET("/users", controllers.SearchUsers)
+	f.router.GET("/users/:id/profile", middleware.OptionalAuth(), controllers.RetrieveUserProfile)
+	f.router.POST("/users/me/verify-email", middleware.AuthRequired(), controllers.RequestEmailVerification)
+	f.router.POST("/auth/verify-email", controllers.VerifyEmail)
+	adminGroup := f.router.Group("/admin", middleware.AuthRequired(), middleware.AdminOnly())
+	adminGroup.POST("/institutions", controllers.CreateInstitution)
+	adminGroup.PUT("/institutions/:id", controllers.UpdateInstitution)
+	adminGroup.DELETE("/institutions/:id", controllers.DeleteInstitution)
+
+	// a faculty member who already verified their university address
+	now := time.Now()
+	faculty := models.User{Email: "ada@cise.ufl.edu", Password: "hash", EmailVerifiedAt: &now}
+	database.DB.Create(&faculty)
+	database.DB.Create(&models.UserProfile{UserID: faculty.ID, FullName: "Ada Lovelace", Role: "faculty"})
+	// claims the same role without a verified address
+	claimant := models.User{Email: "someone@ufl.edu", Password: "hash"}
+	database.DB.Create(&claimant)
+	database.DB.Create(&models.UserProfile{UserID: claimant.ID, FullName: "Ada Claimant", Role: "faculty"})
+
+	w := projectRequest(f.router, "POST", "/admin/institutions", f.token, map[string]interface{}{"name": "University of Florida", "domains": []string{"ufl.edu"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", "/admin/institutions", f.adminToken, map[string]interface{}{"name": "University of Florida", "domains": []string{"not a domain"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "POST", "/admin/institutions", f.adminToken, map[string]interface{}{"name": "University of Florida", "country": "US", "domains": []string{"@UFL.edu"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var institution controllers.InstitutionDetail
+	json.Unmarshal(w.Body.Bytes(), &institution)
+	assert.Equal(t, []string{"ufl.edu"}, institution.Domains)
+	assert.Equal(t, int64(1), institution.VerifiedMembers)
+
+	w = projectRequest(f.router, "POST", "/admin/institutions", f.adminToken, map[string]interface{}{"name": "Gator University", "domains": []string{"ufl.edu"}})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = projectRequest(f.router, "POST", "/admin/institutions", f.adminToken, map[string]interface{}{"name": "university of florida", "domains": []string{"gators.edu"}})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// only the verified member gets a badge
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/users/%d/profile", faculty.ID), "", nil)
+	var profile controllers.ProfileRetrievalResponse
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	if assert.NotNil(t, profile.VerifiedInstitution) {
+		assert.Equal(t, "University of Florida", profile.VerifiedInstitution.Name)
+	}
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/users/%d/profile", claimant.ID), "", nil)
+	profile = controllers.ProfileRetrievalResponse{}
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Nil(t, profile.VerifiedInstitution)
+
+	var search controllers.UserSearchResponse
+	w = projectRequest(f.router, "GET", "/users?q=ada", "", nil)
+	json.Unmarshal(w.Body.Bytes(), &search)
+	assert.Equal(t, int64(2), search.Total)
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/users?q=ada&institution_id=%d", institution.ID), "", nil)
+	search = controllers.UserSearchResponse{}
+	json.Unmarshal(w.Body.Bytes(), &search)
+	if assert.Len(t, search.Users, 1) {
+		assert.Equal(t, faculty.ID, search.Users[0].UserID)
+	}
+
+	// verifying the address by email makes the claimant a member too
+	claimantToken, _ := utils.GenerateJWT(claimant.ID, claimant.Email)
+	w = projectRequest(f.router, "POST", "/users/me/verify-email", claimantToken, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	if assert.Len(t, f.mailer.sent, 1) {
+		link := regexp.MustCompile(`token=([^\s]+)`).FindStringSubmatch(f.mailer.sent[0].Text)[1]
+		token, _ := url.QueryUnescape(link)
+		w = projectRequest(f.router, "POST", "/auth/verify-email", "", map[string]string{"token": token})
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	w = projectRequest(f.router, "POST", "/users/me/verify-email", claimantToken, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d", institution.ID), "", nil)
+	json.Unmarshal(w.Body.Bytes(), &institution)
+	assert.Equal(t, int64(2), institution.VerifiedMembers)
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/admin/users?institution_id=%d", institution.ID), f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), claimant.Email)
+
+	// members whose domain is removed lose the badge
+	w = projectRequest(f.router, "PUT", fmt.Sprintf("/admin/institutions/%d", institution.ID), f.adminToken, map[string]interface{}{"name": "University of Florida", "domains": []string{"cise.ufl.edu"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &institution)
+	assert.Equal(t, int64(1), institution.VerifiedMembers)
+
+	w = projectRequest(f.router, "GET", "/institutions?q=cise", "", nil)
+	var list controllers.InstitutionListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, int64(1), list.Total)
+
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/admin/institutions/%d", institution.ID), f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var unlinked models.UserProfile
+	database.DB.Where("user_id = ?", faculty.ID).First(&unlinked)
+	assert.Nil(t, unlinked.InstitutionID)
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d", institution.ID), "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestInstitutionPages(t *testing.T) {
+	f := setupAdminTest(t)
+	f.router.GET("/institutions/:id", controllers.GetInstitution)
+	f.router.GET("/institutions/:id/members", controllers.ListInstitutionMembers)
+	f.router.GET("/institutions/:id/projects", controllers.ListInstitutionProjects)
+	f.router.GET("/users/:id/profile", middleware.OptionalAuth(), controllers.RetrieveUserProfile)
+	f.router.PUT("/users/:id/profile", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.EditUserProfile)
+	adminGroup := f.router.Group("/admin", middleware.AuthRequired(), middleware.AdminOnly())
+	adminGroup.POST("/institutions/normalize-affiliations", controllers.NormalizeAffiliations)
+
+	university := models.Institution{Name: "University of Florida", City: "Gainesville", Region: "FL", Country: "US",
+		Domains: []models.InstitutionDomain{{Domain: "ufl.edu"}},
+		Departments: []models.Department{
+			{Name: "Computer & Information Science & Engineering", Aliases: `["CISE"]`},
+			{Name: "Mathematics"},
+		}}
+	university.SetAliases([]string{"UF"})
+	database.DB.Create(&university)
+	other := models.Institution{Name: "Florida State University", Departments: []models.Department{{Name: "Physics"}}}
+	database.DB.Create(&other)
+	cise, mathematics := university.Departments[0], university.Departments[1]
+
+	// a verified member, and one whose profile moderators hid
+	now := time.Now()
+	faculty := models.User{Email: "ada@ufl.edu", Password: "hash", EmailVerifiedAt: &now}
+	database.DB.Create(&faculty)
+	database.DB.Create(&models.UserProfile{UserID: faculty.ID, FullName: "Ada Lovelace", InstitutionID: &university.ID, InstitutionVerifiedAt: &now})
+	hidden := models.User{Email: "hidden@ufl.edu", Password: "hash", EmailVerifiedAt: &now}
+	database.DB.Create(&hidden)
+	database.DB.Create(&models.UserProfile{UserID: hidden.ID, FullName: "Hidden", InstitutionID: &university.ID, HiddenAt: &now})
+
+	// the affiliation text is matched despite abbreviations
+	profilePath := fmt.Sprintf("/users/%d/profile", f.member.ID)
+	w := projectRequest(f.router, "PUT", profilePath, f.token, map[string]interface{}{"full_name": "Grace Hopper", "affiliation": "Dept. of Mathematics, Univ. of Florida"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var profile controllers.ProfileRetrievalResponse
+	w = projectRequest(f.router, "GET", profilePath, "", nil)
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(t, "Dept. of Mathematics, Univ. of Florida", profile.Affiliation)
+	if assert.NotNil(t, profile.AffiliationLink) {
+		assert.Equal(t, university.ID, profile.AffiliationLink.InstitutionID)
+		assert.Equal(t, "Mathematics", profile.AffiliationLink.DepartmentName)
+	}
+	assert.Nil(t, profile.VerifiedInstitution)
+
+	// picked departments must belong to the picked institution
+	w = projectRequest(f.router, "PUT", profilePath, f.token, map[string]interface{}{"full_name": "Grace Hopper", "institution_id": other.ID, "department_id": cise.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(f.router, "PUT", profilePath, f.token, map[string]interface{}{"full_name": "Grace Hopper", "institution_id": 9999})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(f.router, "PUT", profilePath, f.token, map[string]interface{}{"full_name": "Grace Hopper", "affiliation": "Somewhere else", "department_id": cise.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+	profile = controllers.ProfileRetrievalResponse{}
+	w = projectRequest(f.router, "GET", profilePath, "", nil)
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	if assert.NotNil(t, profile.AffiliationLink) {
+		assert.Equal(t, university.ID, profile.AffiliationLink.InstitutionID)
+		assert.Equal(t, cise.ID, *profile.AffiliationLink.DepartmentID)
+	}
+
+	var detail controllers.InstitutionDetail
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d", university.ID), "", nil)
+	json.Unmarshal(w.Body.Bytes(), &detail)
+	assert.Equal(t, []string{"UF"}, detail.Aliases)
+	assert.Equal(t, "Gainesville", detail.City)
+	assert.Len(t, detail.Departments, 2)
+	assert.Equal(t, int64(2), detail.VerifiedMembers)
+	assert.Equal(t, int64(2), detail.Members)
+
+	// verified members and linked affiliations, without hidden profiles
+	var members controllers.InstitutionMemberListResponse
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d/members", university.ID), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &members)
+	if assert.Len(t, members.Members, 2) {
+		assert.Equal(t, "Ada Lovelace", members.Members[0].FullName)
+		assert.True(t, members.Members[0].Verified)
+		assert.Equal(t, "Grace Hopper", members.Members[1].FullName)
+		assert.False(t, members.Members[1].Verified)
+		if assert.NotNil(t, members.Members[1].Department) {
+			assert.Equal(t, "Computer & Information Science & Engineering", members.Members[1].Department.Name)
+		}
+	}
+	members = controllers.InstitutionMemberListResponse{}
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d/members?department_id=%d", university.ID, mathematics.ID), "", nil)
+	json.Unmarshal(w.Body.Bytes(), &members)
+	assert.Equal(t, int64(0), members.Total)
+	members = controllers.InstitutionMemberListResponse{}
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d/members?verified=true", university.ID), "", nil)
+	json.Unmarshal(w.Body.Bytes(), &members)
+	assert.Equal(t, int64(1), members.Total)
+	w = projectRequest(f.router, "GET", "/institutions/9999/members", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// public projects owned by or involving members
+	outsider := models.User{Email: "outsider@example.com", Password: "hash"}
+	database.DB.Create(&outsider)
+	owned := models.Project{Title: "Owned", OwnerID: faculty.ID, Visibility: models.ProjectVisibilityPublic, Status: "active"}
+	private := models.Project{Title: "Private", OwnerID: faculty.ID, Visibility: models.ProjectVisibilityPrivate, Status: "active"}
+	joined := models.Project{Title: "Joined", OwnerID: outsider.ID, Visibility: models.ProjectVisibilityPublic, Status: "active"}
+	unrelated := models.Project{Title: "Unrelated", OwnerID: outsider.ID, Visibility: models.ProjectVisibilityPublic, Status: "active"}
+	byHidden := models.Project{Title: "By hidden member", OwnerID: hidden.ID, Visibility: models.ProjectVisibilityPublic, Status: "active"}
+	for _, project := range []*models.Project{&owned, &private, &joined, &unrelated, &byHidden} {
+		database.DB.Create(project)
+	}
+	database.DB.Create(&models.Collaborator{ProjectID: joined.ID, UserID: f.member.ID, Role: string(models.CollaboratorRoleProgrammer)})
+
+	var projects controllers.InstitutionProjectListResponse
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/institutions/%d/projects", university.ID), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &projects)
+	assert.Equal(t, int64(2), projects.Total)
+	if assert.Len(t, projects.Projects, 2) {
+		assert.Equal(t, "Joined", projects.Projects[0].Title)
+		assert.Equal(t, "Owned", projects.Projects[1].Title)
+	}
+
+	// existing free-text affiliations are normalized in bulk
+	legacy := models.User{Email: "legacy@example.com", Password: "hash"}
+	database.DB.Create(&legacy)
+	database.DB.Create(&models.UserProfile{UserID: legacy.ID, FullName: "Legacy", Affiliation: "CISE - Universty of Florida"})
+	unknown := models.User{Email: "unknown@example.com", Password: "hash"}
+	database.DB.Create(&unknown)
+	database.DB.Create(&models.UserProfile{UserID: unknown.ID, FullName: "Unknown", Affiliation: "University of Central Florida"})
+
+	w = projectRequest(f.router, "POST", "/admin/institutions/normalize-affiliations?dry_run=true", f.token, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var normalization controllers.AffiliationNormalizationResponse
+	w = projectRequest(f.router, "POST", "/admin/institutions/normalize-affiliations?dry_run=true", f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &normalization)
+	assert.True(t, normalization.DryRun)
+	assert.Equal(t, 1, normalization.Unmatched)
+	if assert.Len(t, normalization.Normalized, 1) {
+		assert.Equal(t, legacy.ID, normalization.Normalized[0].UserID)
+		assert.Equal(t, "Computer & Information Science & Engineering, University of Florida", normalization.Normalized[0].Normalized)
+	}
+	var stored models.UserProfile
+	database.DB.Where("user_id = ?", legacy.ID).First(&stored)
+	assert.Nil(t, stored.AffiliationInstitutionID)
+
+	w = projectRequest(f.router, "POST", "/admin/institutions/normalize-affiliations", f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored = models.UserProfile{}
+	database.DB.Where("user_id = ?", legacy.ID).First(&stored)
+	assert.Equal(t, "Computer & Information Science & Engineering, University of Florida", stored.Affiliation)
+	if assert.NotNil(t, stored.AffiliationInstitutionID) && assert.NotNil(t, stored.DepartmentID) {
+		assert.Equal(t, university.ID, *stored.AffiliationInstitutionID)
+		assert.Equal(t, cise.ID, *stored.DepartmentID)
+	}
+	var audits int64
+	database.DB.Model(&models.AuditEvent{}).Where("action = ?", models.AuditAffiliationsNormalized).Count(&audits)
+	assert.Equal(t, int64(1), audits)
+}
@@ -0,0 +1,717 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 100
+)
+
+type CreateConversationRequest struct {
+	ParticipantIDs []uint `json:"participant_ids" binding:"required,min=1" example:"42,43"`
+	Title          string `json:"title" binding:"max=100" example:"Protein folding reading group"`
+	Body           string `json:"body" binding:"required,max=5000" example:"Hi, do you have time to talk about the dataset?"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" binding:"required,max=5000" example:"Sounds good, see you Thursday"`
+}
+
+type MarkConversationReadRequest struct {
+	// defaults to the latest message of the conversation
+	MessageID uint `json:"message_id" example:"12"`
+}
+
+type MuteConversationRequest struct {
+	Muted *bool `json:"muted" binding:"required" example:"true"`
+}
+
+type MessagingSettingsRequest struct {
+	MessagePrivacy string `json:"message_privacy" binding:"required,oneof=everyone collaborators nobody" example:"collaborators"`
+}
+
+type MessagingSettingsResponse struct {
+	MessagePrivacy string `json:"message_privacy" example:"everyone"`
+}
+
+// ParticipantDetail describes a member of a conversation, emails are not shared through messaging
+type ParticipantDetail struct {
+	UserID            uint   `json:"user_id"`
+	FullName          string `json:"full_name" example:"Jane Doe"`
+	AvatarURL         string `json:"avatar_url"`
+	LastReadMessageID uint   `json:"last_read_message_id"`
+}
+
+type MessageDetail struct {
+	ID             uint      `json:"id"`
+	ConversationID uint      `json:"conversation_id"`
+	SenderID       uint      `json:"sender_id"`
+	Body           string    `json:"body" example:"Hi, do you have time to talk about the dataset?"`
+	CreatedAt      time.Time `json:"created_at"`
+	// participants other than the sender who have read the message
+	ReadBy []uint `json:"read_by"`
+}
+
+type ConversationDetail struct {
+	ID            uint                `json:"id"`
+	Title         string              `json:"title" example:"Protein folding reading group"`
+	IsGroup       bool                `json:"is_group"`
+	CreatorID     uint                `json:"creator_id"`
+	Participants  []ParticipantDetail `json:"participants"`
+	LastMessage   *MessageDetail      `json:"last_message"`
+	LastMessageAt *time.Time          `json:"last_message_at"`
+	UnreadCount   int64               `json:"unread_count" example:"2"`
+	Muted         bool                `json:"muted"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+type ConversationListResponse struct {
+	Conversations []ConversationDetail `json:"conversations"`
+	Page          int                  `json:"page" example:"1"`
+	PageSize      int                  `json:"page_size" example:"20"`
+	Total         int64                `json:"total" example:"4"`
+}
+
+type MessageListResponse struct {
+	// newest first
+	Messages []MessageDetail `json:"messages"`
+	// pass as "before" to fetch older messages, omitted on the last page
+	NextBefore *uint `json:"next_before" example:"31"`
+}
+
+func toMessageDetail(message models.Message, participants []models.ConversationParticipant) MessageDetail {
+	detail := MessageDetail{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Body:           message.Body,
+		CreatedAt:      message.CreatedAt,
+		ReadBy:         []uint{},
+	}
+	for _, participant := range participants {
+		if participant.UserID != message.SenderID && participant.LastReadMessageID >= message.ID {
+			detail.ReadBy = append(detail.ReadBy, participant.UserID)
+		}
+	}
+	return detail
+}
+
+// conversationDetail builds the view of a conversation for one of its participants
+func conversationDetail(db *gorm.DB, conversation models.Conversation, viewerID uint) (ConversationDetail, error) {
+	var participants []models.ConversationParticipant
+	if err := db.Preload("User.Profile").Where("conversation_id = ?", conversation.ID).Order("id").Find(&participants).Error; err != nil {
+		return ConversationDetail{}, err
+	}
+
+	detail := ConversationDetail{
+		ID:            conversation.ID,
+		Title:         conversation.Title,
+		IsGroup:       conversation.IsGroup,
+		CreatorID:     conversation.CreatorID,
+		Participants:  make([]ParticipantDetail, len(participants)),
+		LastMessageAt: conversation.LastMessageAt,
+		CreatedAt:     conversation.CreatedAt,
+	}
+	var lastRead uint
+	for i, participant := range participants {
+		detail.Participants[i] = ParticipantDetail{
+			UserID:            participant.UserID,
+			FullName:          participant.User.Profile.FullName,
+			AvatarURL:         avatarURL(participant.User.Profile),
+			LastReadMessageID: participant.LastReadMessageID,
+		}
+		if participant.UserID == viewerID {
+			lastRead = participant.LastReadMessageID
+			detail.Muted = participant.Muted
+		}
+	}
+
+	var last models.Message
+	err := db.Where("conversation_id = ?", conversation.ID).Order("id DESC").First(&last).Error
+	if err == nil {
+		lastDetail := toMessageDetail(last, participants)
+		detail.LastMessage = &lastDetail
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return ConversationDetail{}, err
+	}
+
+	err = db.Model(&models.Message{}).
+		Where("conversation_id = ? AND id > ? AND sender_id <> ?", conversation.ID, lastRead, viewerID).
+		Count(&detail.UnreadCount).Error
+	return detail, err
+}
+
+// findConversation loads the conversation in the "id" path parameter and the current user's participation in it,
+// writing a 404 response when it doesn't exist or the user is not a participant
+func findConversation(c *gin.Context) (models.Conversation, models.ConversationParticipant, bool) {
+	var conversation models.Conversation
+	var participant models.ConversationParticipant
+	if err := database.DB.First(&conversation, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Conversation not found"})
+		return conversation, participant, false
+	}
+	err := database.DB.Where("conversation_id = ? AND user_id = ?", conversation.ID, utils.InferUserID(c)).First(&participant).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Conversation not found"})
+		return conversation, participant, false
+	}
+	return conversation, participant, true
+}
+
+// findDirectConversation returns the existing one-to-one conversation between two users, if any
+func findDirectConversation(db *gorm.DB, a, b uint) (models.Conversation, bool) {
+	var conversation models.Conversation
+	shared := db.Model(&models.ConversationParticipant{}).Select("conversation_id").
+		Where("user_id IN ?", []uint{a, b}).Group("conversation_id").Having("COUNT(*) = 2")
+	err := db.Where("is_group = ? AND id IN (?)", false, shared).First(&conversation).Error
+	return conversation, err == nil
+}
+
+// appendMessage stores a message, advances the sender's read position and notifies the other participants
+func appendMessage(c *gin.Context, tx *gorm.DB, conversation *models.Conversation, message *models.Message) ([]*models.Notification, error) {
+	if err := tx.Create(message).Error; err != nil {
+		return nil, err
+	}
+	conversation.LastMessageAt = &message.CreatedAt
+	if err := tx.Model(conversation).Update("last_message_at", message.CreatedAt).Error; err != nil {
+		return nil, err
+	}
+	err := tx.Model(&models.ConversationParticipant{}).
+		Where("conversation_id = ? AND user_id = ?", conversation.ID, message.SenderID).
+		Updates(map[string]interface{}{"last_read_message_id": message.ID, "last_read_at": message.CreatedAt}).Error
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := services.NotifyNewMessage(tx, *conversation, *message)
+	if err != nil {
+		return nil, err
+	}
+	// the body is private to the conversation and is kept out of the audit log
+	return notifications, recordAudit(c, tx, services.AuditEntry{
+		Action: models.AuditMessageSent, TargetType: "conversation", TargetID: conversation.ID,
+		After: map[string]interface{}{"message_id": message.ID},
+	})
+}
+
+// messagingErrorResponse writes the response for an error returned by services.CanMessage
+func messagingErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrMessagingBlocked) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You cannot message this user"})
+		return
+	}
+	c.JSON(http.StatusForbidden, ErrorResponse{Error: "This user does not accept messages from you"})
+}
+
+// ListConversations godoc
+// @Summary      List conversations
+// @Description  Lists the authenticated user's conversations, most recently active first, with their last message and unread count.
+// @Tags         Messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Conversations per page" default(20)
+// @Success      200 {object} ConversationListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations [get]
+func ListConversations(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	userID := utils.InferUserID(c)
+
+	query := database.DB.Model(&models.Conversation{}).
+		Where("id IN (?)", database.DB.Model(&models.ConversationParticipant{}).Select("conversation_id").Where("user_id = ?", userID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversations"})
+		return
+	}
+
+	var conversations []models.Conversation
+	err := query.Order("last_message_at DESC").Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).Find(&conversations).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversations"})
+		return
+	}
+
+	response := ConversationListResponse{Conversations: make([]ConversationDetail, len(conversations)), Page: page, PageSize: pageSize, Total: total}
+	for i, conversation := range conversations {
+		detail, err := conversationDetail(database.DB, conversation, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversations"})
+			return
+		}
+		response.Conversations[i] = detail
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateConversation godoc
+// @Summary      Start a conversation
+// @Description  Starts a one-to-one or group conversation with a first message. Messaging a single user again continues the existing one-to-one conversation. Group conversations hold at most 10 participants. Recipients must not have blocked you and their messaging settings must allow you to message them.
+// @Tags         Messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body CreateConversationRequest true "Participants and first message"
+// @Success      200 {object} ConversationDetail "Existing one-to-one conversation"
+// @Success      201 {object} ConversationDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations [post]
+func CreateConversation(c *gin.Context) {
+	var request CreateConversationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	userID := utils.InferUserID(c)
+
+	var recipientIDs []uint
+	for _, id := range request.ParticipantIDs {
+		if id != userID && !slices.Contains(recipientIDs, id) {
+			recipientIDs = append(recipientIDs, id)
+		}
+	}
+	if len(recipientIDs) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "A conversation needs at least one other participant"})
+		return
+	}
+	if len(recipientIDs)+1 > models.MaxConversationParticipants {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Group conversations are limited to 10 participants"})
+		return
+	}
+
+	var recipients []models.User
+	if err := database.DB.Where("id IN ?", recipientIDs).Find(&recipients).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to start conversation"})
+		return
+	}
+	if len(recipients) != len(recipientIDs) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+		return
+	}
+	for _, recipient := range recipients {
+		if err := services.CanMessage(database.DB, userID, recipient); err != nil {
+			messagingErrorResponse(c, err)
+			return
+		}
+	}
+	// blocks apply between every pair of participants of a group, not only between them and its creator
+	for i, a := range recipientIDs {
+		for _, b := range recipientIDs[i+1:] {
+			if services.BlockedEitherWay(database.DB, a, b) {
+				c.JSON(http.StatusForbidden, ErrorResponse{Error: "Some of these users cannot be in a conversation together"})
+				return
+			}
+		}
+	}
+
+	isGroup := len(recipientIDs) > 1
+	status := http.StatusCreated
+	var conversation models.Conversation
+	if !isGroup {
+		if existing, found := findDirectConversation(database.DB, userID, recipientIDs[0]); found {
+			conversation = existing
+			status = http.StatusOK
+		}
+	}
+
+	message := models.Message{SenderID: userID, Body: request.Body}
+	var notifications []*models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if conversation.ID == 0 {
+			conversation = models.Conversation{CreatorID: userID, IsGroup: isGroup, Title: request.Title}
+			conversation.Participants = append(conversation.Participants, models.ConversationParticipant{UserID: userID})
+			for _, id := range recipientIDs {
+				conversation.Participants = append(conversation.Participants, models.ConversationParticipant{UserID: id})
+			}
+			if err := tx.Create(&conversation).Error; err != nil {
+				return err
+			}
+			err := recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditConversationCreated, TargetType: "conversation", TargetID: conversation.ID,
+				After: map[string]interface{}{"participant_ids": append([]uint{userID}, recipientIDs...), "is_group": isGroup},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		message.ConversationID = conversation.ID
+		var err error
+		notifications, err = appendMessage(c, tx, &conversation, &message)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to start conversation"})
+		return
+	}
+	services.PublishMessage(database.DB, message)
+	services.PublishNotifications(notifications...)
+
+	detail, err := conversationDetail(database.DB, conversation, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversation"})
+		return
+	}
+	c.JSON(status, detail)
+}
+
+// GetConversation godoc
+// @Summary      Get a conversation
+// @Description  Returns a conversation the authenticated user participates in, with its participants' read positions.
+// @Tags         Messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Conversation ID"
+// @Success      200 {object} ConversationDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations/{id} [get]
+func GetConversation(c *gin.Context) {
+	conversation, participant, ok := findConversation(c)
+	if !ok {
+		return
+	}
+
+	detail, err := conversationDetail(database.DB, conversation, participant.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversation"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// ListMessages godoc
+// @Summary      List messages
+// @Description  Lists the messages of a conversation, newest first. Pass the returned next_before to fetch older messages.
+// @Tags         Messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Conversation ID"
+// @Param        before query int false "Only return messages older than this message ID"
+// @Param        limit query int false "Messages per page, at most 100" default(50)
+// @Success      200 {object} MessageListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations/{id}/messages [get]
+func ListMessages(c *gin.Context) {
+	conversation, _, ok := findConversation(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMessagePageSize)))
+	if err != nil || limit < 1 || limit > maxMessagePageSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be between 1 and 100"})
+		return
+	}
+
+	query := database.DB.Where("conversation_id = ?", conversation.ID)
+	if before := c.Query("before"); before != "" {
+		beforeID, err := strconv.ParseUint(before, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid before"})
+			return
+		}
+		query = query.Where("id < ?", beforeID)
+	}
+
+	// fetch one extra message to know whether there are older ones
+	var messages []models.Message
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch messages"})
+		return
+	}
+	var participants []models.ConversationParticipant
+	if err := database.DB.Where("conversation_id = ?", conversation.ID).Find(&participants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch messages"})
+		return
+	}
+
+	var response MessageListResponse
+	if len(messages) > limit {
+		messages = messages[:limit]
+		response.NextBefore = &messages[limit-1].ID
+	}
+	response.Messages = make([]MessageDetail, len(messages))
+	for i, message := range messages {
+		response.Messages[i] = toMessageDetail(message, participants)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SendMessage godoc
+// @Summary      Send a message
+// @Description  Sends a message to a conversation. Messages can't be sent to a one-to-one conversation when either participant blocked the other. Participants are notified unless they muted the conversation or already have unread messages in it.
+// @Tags         Messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Conversation ID"
+// @Param        request body SendMessageRequest true "Message"
+// @Success      201 {object} MessageDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations/{id}/messages [post]
+func SendMessage(c *gin.Context) {
+	conversation, participant, ok := findConversation(c)
+	if !ok {
+		return
+	}
+	var request SendMessageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var participants []models.ConversationParticipant
+	if err := database.DB.Where("conversation_id = ?", conversation.ID).Find(&participants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to send message"})
+		return
+	}
+	// a block between the sender and anyone in the conversation, group or not, stops the sender from writing to it
+	for _, other := range participants {
+		if other.UserID != participant.UserID && services.BlockedEitherWay(database.DB, participant.UserID, other.UserID) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "You cannot message this user"})
+			return
+		}
+	}
+
+	message := models.Message{ConversationID: conversation.ID, SenderID: participant.UserID, Body: request.Body}
+	var notifications []*models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		notifications, err = appendMessage(c, tx, &conversation, &message)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to send message"})
+		return
+	}
+	services.PublishMessage(database.DB, message)
+	services.PublishNotifications(notifications...)
+
+	// the sender has read their own message
+	for i := range participants {
+		if participants[i].UserID == participant.UserID {
+			participants[i].LastReadMessageID = message.ID
+		}
+	}
+	c.JSON(http.StatusCreated, toMessageDetail(message, participants))
+}
+
+// MarkConversationRead godoc
+// @Summary      Mark a conversation as read
+// @Description  Marks messages up to message_id, or every message when it is omitted, as read. The read position never moves backwards.
+// @Tags         Messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Conversation ID"
+// @Param        request body MarkConversationReadRequest false "Last read message"
+// @Success      200 {object} ConversationDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations/{id}/read [post]
+func MarkConversationRead(c *gin.Context) {
+	conversation, participant, ok := findConversation(c)
+	if !ok {
+		return
+	}
+	var request MarkConversationReadRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	var latest models.Message
+	query := database.DB.Where("conversation_id = ?", conversation.ID)
+	if request.MessageID != 0 {
+		query = query.Where("id <= ?", request.MessageID)
+	}
+	if err := query.Order("id DESC").First(&latest).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to mark conversation as read"})
+		return
+	}
+
+	if latest.ID > participant.LastReadMessageID {
+		before := participant.LastReadMessageID
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			now := time.Now()
+			err := tx.Model(&participant).Updates(map[string]interface{}{"last_read_message_id": latest.ID, "last_read_at": now}).Error
+			if err != nil {
+				return err
+			}
+			return recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditConversationRead, TargetType: "conversation", TargetID: conversation.ID,
+				Before: map[string]interface{}{"last_read_message_id": before},
+				After:  map[string]interface{}{"last_read_message_id": latest.ID},
+			})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to mark conversation as read"})
+			return
+		}
+	}
+
+	detail, err := conversationDetail(database.DB, conversation, participant.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversation"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// MuteConversation godoc
+// @Summary      Mute or unmute a conversation
+// @Description  Muted conversations keep counting unread messages but don't create notifications.
+// @Tags         Messages
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Conversation ID"
+// @Param        request body MuteConversationRequest true "Mute setting"
+// @Success      200 {object} ConversationDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /conversations/{id}/mute [put]
+func MuteConversation(c *gin.Context) {
+	conversation, participant, ok := findConversation(c)
+	if !ok {
+		return
+	}
+	var request MuteConversationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if participant.Muted != *request.Muted {
+		before := participant.Muted
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&participant).Update("muted", *request.Muted).Error; err != nil {
+				return err
+			}
+			return recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditConversationMuted, TargetType: "conversation", TargetID: conversation.ID,
+				Before: map[string]interface{}{"muted": before},
+				After:  map[string]interface{}{"muted": *request.Muted},
+			})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update conversation"})
+			return
+		}
+	}
+
+	detail, err := conversationDetail(database.DB, conversation, participant.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch conversation"})
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// GetMessagingSettings godoc
+// @Summary      Get messaging settings
+// @Description  Returns who may start conversations with the user: everyone, collaborators (users sharing a project) or nobody.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Success      200 {object} MessagingSettingsResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Router       /users/{id}/messaging-settings [get]
+func GetMessagingSettings(c *gin.Context) {
+	privacy := models.MessagePrivacyEveryone
+	var profile models.UserProfile
+	if err := database.DB.Where("user_id = ?", c.Param("id")).First(&profile).Error; err == nil && profile.MessagePrivacy != "" {
+		privacy = profile.MessagePrivacy
+	}
+
+	c.JSON(http.StatusOK, MessagingSettingsResponse{MessagePrivacy: string(privacy)})
+}
+
+// UpdateMessagingSettings godoc
+// @Summary      Update messaging settings
+// @Description  Sets who may start conversations with the user. Existing conversations are not affected.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "User ID"
+// @Param        request body MessagingSettingsRequest true "Messaging settings"
+// @Success      200 {object} MessagingSettingsResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/messaging-settings [put]
+func UpdateMessagingSettings(c *gin.Context) {
+	var request MessagingSettingsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	userID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+
+	var profile models.UserProfile
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(models.UserProfile{UserID: uint(userID)}).FirstOrCreate(&profile).Error; err != nil {
+			return err
+		}
+		before := profile.MessagePrivacy
+		profile.MessagePrivacy = models.MessagePrivacy(request.MessagePrivacy)
+		if err := tx.Model(&profile).Update("message_privacy", profile.MessagePrivacy).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditMessagingSettingsUpdated, TargetType: "user", TargetID: uint(userID),
+			Before: map[string]interface{}{"message_privacy": before},
+			After:  map[string]interface{}{"message_privacy": profile.MessagePrivacy},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update messaging settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessagingSettingsResponse{MessagePrivacy: string(profile.MessagePrivacy)})
+}
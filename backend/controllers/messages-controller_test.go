@@ -0,0 +1,264 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type messagesFixture struct {
+	router *gin.Engine
+	users  []models.User
+	tokens []string
+}
+
+func setupMessagesTest(t *testing.T) messagesFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Conversation{}, &models.ConversationParticipant{}, &models.Message{})
+	database.DB.Exec("DELETE FROM messages")
+	database.DB.Exec("DELETE FROM conversation_participants")
+	database.DB.Exec("DELETE FROM conversations")
+
+	fixture := messagesFixture{router: gin.Default()}
+	for i, name := range []string{"Ada Lovelace", "Alan Turing", "Grace Hopper"} {
+		user := models.User{Email: fmt.Sprintf("messages_%d@example.com", i), Password: "password"}
+		database.DB.Create(&user)
+		database.DB.Create(&models.UserProfile{UserID: user.ID, FullName: name})
+		token, _ := utils.GenerateJWT(user.ID, user.Email)
+		fixture.users = append(fixture.users, user)
+		fixture.tokens = append(fixture.tokens, token)
+	}
+
+	router := fixture.router
+	router.GET("/conversations", middleware.AuthRequired(), controllers.ListConversations)
+	router.POST("/conversations", middleware.AuthRequired(), controllers.CreateConversation)
+	router.GET("/conversations/:id", middleware.AuthRequired(), controllers.GetConversation)
+	router.GET("/conversations/:id/messages", middleware.AuthRequired(), controllers.ListMessages)
+	router.POST("/conversations/:id/messages", middleware.AuthRequired(), controllers.SendMessage)
+	router.POST("/conversations/:id/read", middleware.AuthRequired(), controllers.MarkConversationRead)
+	router.PUT("/conversations/:id/mute", middleware.AuthRequired(), controllers.MuteConversation)
+	router.GET("/users/:id/messaging-settings", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.GetMessagingSettings)
+	router.PUT("/users/:id/messaging-settings", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UpdateMessagingSettings)
+	return fixture
+}
+
+func decodeConversation(t *testing.T, body []byte) controllers.ConversationDetail {
+	var conversation controllers.ConversationDetail
+	assert.NoError(t, json.Unmarshal(body, &conversation))
+	return conversation
+}
+
+func countMessageNotifications(userID uint) int64 {
+	var count int64
+	database.DB.Model(&models.Notification{}).Where("user_id = ? AND type = ?", userID, models.NotificationMessage).Count(&count)
+	return count
+}
+
+func TestDirectConversation(t *testing.T) {
+	f := setupMessagesTest(t)
+	ada, alan := f.users[0], f.users[1]
+
+	w := projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{alan.ID}, "body": "Hello Alan",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), alan.Email)
+	conversation := decodeConversation(t, w.Body.Bytes())
+	assert.False(t, conversation.IsGroup)
+	assert.Len(t, conversation.Participants, 2)
+	assert.Equal(t, "Hello Alan", conversation.LastMessage.Body)
+	assert.Empty(t, conversation.LastMessage.ReadBy)
+	assert.Equal(t, int64(0), conversation.UnreadCount)
+	assert.Equal(t, int64(1), countMessageNotifications(alan.ID))
+
+	// messaging the same user again continues the conversation
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{alan.ID}, "body": "Are you there?",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, conversation.ID, decodeConversation(t, w.Body.Bytes()).ID)
+
+	// a second unread message doesn't notify again
+	assert.Equal(t, int64(1), countMessageNotifications(alan.ID))
+
+	path := fmt.Sprintf("/conversations/%d", conversation.ID)
+	w = projectRequest(f.router, "GET", "/conversations", f.tokens[1], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list controllers.ConversationListResponse
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, int64(2), list.Conversations[0].UnreadCount)
+
+	// read receipts
+	w = projectRequest(f.router, "POST", path+"/read", f.tokens[1], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	read := decodeConversation(t, w.Body.Bytes())
+	assert.Equal(t, int64(0), read.UnreadCount)
+	assert.Equal(t, []uint{alan.ID}, read.LastMessage.ReadBy)
+
+	w = projectRequest(f.router, "POST", path+"/messages", f.tokens[1], map[string]string{"body": "Hi Ada"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int64(1), countMessageNotifications(ada.ID))
+
+	// outsiders can't see the conversation
+	w = projectRequest(f.router, "GET", path, f.tokens[2], nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(f.router, "POST", path+"/messages", f.tokens[2], map[string]string{"body": "Hi"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListMessagesPagination(t *testing.T) {
+	f := setupMessagesTest(t)
+
+	w := projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{f.users[1].ID}, "body": "message 0",
+	})
+	path := fmt.Sprintf("/conversations/%d/messages", decodeConversation(t, w.Body.Bytes()).ID)
+	for i := 1; i < 5; i++ {
+		projectRequest(f.router, "POST", path, f.tokens[0], map[string]string{"body": fmt.Sprintf("message %d", i)})
+	}
+
+	w = projectRequest(f.router, "GET", path+"?limit=3", f.tokens[1], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page controllers.MessageListResponse
+	json.Unmarshal(w.Body.Bytes(), &page)
+	assert.Len(t, page.Messages, 3)
+	assert.Equal(t, "message 4", page.Messages[0].Body)
+	assert.NotNil(t, page.NextBefore)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("%s?limit=3&before=%d", path, *page.NextBefore), f.tokens[1], nil)
+	var older controllers.MessageListResponse
+	json.Unmarshal(w.Body.Bytes(), &older)
+	assert.Len(t, older.Messages, 2)
+	assert.Equal(t, "message 0", older.Messages[1].Body)
+	assert.Nil(t, older.NextBefore)
+
+	w = projectRequest(f.router, "GET", path+"?limit=500", f.tokens[1], nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGroupConversationMute(t *testing.T) {
+	f := setupMessagesTest(t)
+
+	w := projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{f.users[1].ID, f.users[2].ID}, "title": "Reading group", "body": "Welcome",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	conversation := decodeConversation(t, w.Body.Bytes())
+	assert.True(t, conversation.IsGroup)
+	assert.Len(t, conversation.Participants, 3)
+	path := fmt.Sprintf("/conversations/%d", conversation.ID)
+
+	// grace mutes the group, reads it, and isn't notified of new messages
+	w = projectRequest(f.router, "PUT", path+"/mute", f.tokens[2], map[string]bool{"muted": true})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, decodeConversation(t, w.Body.Bytes()).Muted)
+	projectRequest(f.router, "POST", path+"/read", f.tokens[2], nil)
+	projectRequest(f.router, "POST", path+"/read", f.tokens[1], nil)
+
+	w = projectRequest(f.router, "POST", path+"/messages", f.tokens[0], map[string]string{"body": "Agenda attached"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, int64(1), countMessageNotifications(f.users[2].ID))
+	assert.Equal(t, int64(2), countMessageNotifications(f.users[1].ID))
+
+	w = projectRequest(f.router, "GET", path, f.tokens[2], nil)
+	assert.Equal(t, int64(1), decodeConversation(t, w.Body.Bytes()).UnreadCount)
+
+	// groups are limited in size
+	ids := []uint{}
+	for i := uint(1); i <= models.MaxConversationParticipants; i++ {
+		ids = append(ids, f.users[0].ID+i+100)
+	}
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{"participant_ids": ids, "body": "Hi all"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestMessagingBlocksAndPrivacy(t *testing.T) {
+	f := setupMessagesTest(t)
+	ada, alan, grace := f.users[0], f.users[1], f.users[2]
+
+	w := projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{alan.ID}, "body": "Hello",
+	})
+	path := fmt.Sprintf("/conversations/%d/messages", decodeConversation(t, w.Body.Bytes()).ID)
+
+	// blocking stops both sides of an existing conversation
+	database.DB.Create(&models.UserBlock{BlockerID: alan.ID, BlockedID: ada.ID})
+	w = projectRequest(f.router, "POST", path, f.tokens[0], map[string]string{"body": "Hello?"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", path, f.tokens[1], map[string]string{"body": "Go away"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{alan.ID, grace.ID}, "body": "Group chat",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// grace only accepts messages from collaborators
+	settingsPath := fmt.Sprintf("/users/%d/messaging-settings", grace.ID)
+	w = projectRequest(f.router, "PUT", settingsPath, f.tokens[2], map[string]string{"message_privacy": "collaborators"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "GET", settingsPath, f.tokens[2], nil)
+	assert.Contains(t, w.Body.String(), `"collaborators"`)
+	w = projectRequest(f.router, "PUT", settingsPath, f.tokens[2], map[string]string{"message_privacy": "friends"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{grace.ID}, "body": "Hi Grace",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	project := models.Project{Title: "Shared", OwnerID: grace.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: ada.ID, Role: "programmer"})
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{grace.ID}, "body": "Hi Grace",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	gracePath := fmt.Sprintf("/conversations/%d/messages", decodeConversation(t, w.Body.Bytes()).ID)
+
+	// nobody can start new conversations, existing ones keep working
+	projectRequest(f.router, "PUT", settingsPath, f.tokens[2], map[string]string{"message_privacy": "nobody"})
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[0], map[string]interface{}{
+		"participant_ids": []uint{grace.ID}, "body": "Hi again",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", gracePath, f.tokens[0], map[string]string{"body": "Hi again"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestGroupConversationBlocks(t *testing.T) {
+	f := setupMessagesTest(t)
+	ada, alan := f.users[0], f.users[1]
+
+	w := projectRequest(f.router, "POST", "/conversations", f.tokens[2], map[string]interface{}{
+		"participant_ids": []uint{ada.ID, alan.ID}, "title": "Reading group", "body": "Welcome",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	path := fmt.Sprintf("/conversations/%d/messages", decodeConversation(t, w.Body.Bytes()).ID)
+
+	// a block between two members keeps both of them from writing to the group, but not the others
+	database.DB.Create(&models.UserBlock{BlockerID: alan.ID, BlockedID: ada.ID})
+	w = projectRequest(f.router, "POST", path, f.tokens[0], map[string]string{"body": "Hello?"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", path, f.tokens[1], map[string]string{"body": "Hello"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(f.router, "POST", path, f.tokens[2], map[string]string{"body": "Next week's paper"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// nor can anyone put them in a new group together
+	w = projectRequest(f.router, "POST", "/conversations", f.tokens[2], map[string]interface{}{
+		"participant_ids": []uint{ada.ID, alan.ID}, "body": "Another group",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MilestoneDetail struct {
+	ID             uint       `json:"id"`
+	ProjectID      uint       `json:"project_id"`
+	Title          string     `json:"title" example:"Submit paper draft"`
+	Description    string     `json:"description"`
+	DueDate        *time.Time `json:"due_date"`
+	TotalTasks     int        `json:"total_tasks" example:"8"`
+	CompletedTasks int        `json:"completed_tasks" example:"6"`
+	Progress       int        `json:"progress" example:"75"` // percentage of completed tasks
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type MilestoneListResponse struct {
+	Milestones []MilestoneDetail `json:"milestones"`
+}
+
+type MilestoneRequest struct {
+	Title       string     `json:"title" binding:"required,max=200"`
+	Description string     `json:"description" binding:"max=10000"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+// milestoneProgress holds task counts of a single milestone
+type milestoneProgress struct {
+	MilestoneID uint
+	Total       int
+	Completed   int
+}
+
+// loadMilestoneProgress counts total and completed tasks for every milestone of a project
+func loadMilestoneProgress(projectID uint) (map[uint]milestoneProgress, error) {
+	var rows []milestoneProgress
+	err := database.DB.Model(&models.Task{}).
+		Select("milestone_id, COUNT(*) AS total, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS completed", models.TaskStatusDone).
+		Where("project_id = ? AND milestone_id IS NOT NULL", projectID).
+		Group("milestone_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[uint]milestoneProgress, len(rows))
+	for _, row := range rows {
+		progress[row.MilestoneID] = row
+	}
+	return progress, nil
+}
+
+func toMilestoneDetail(milestone models.Milestone, progress milestoneProgress) MilestoneDetail {
+	detail := MilestoneDetail{
+		ID:             milestone.ID,
+		ProjectID:      milestone.ProjectID,
+		Title:          milestone.Title,
+		Description:    milestone.Description,
+		DueDate:        milestone.DueDate,
+		TotalTasks:     progress.Total,
+		CompletedTasks: progress.Completed,
+		CreatedAt:      milestone.CreatedAt,
+	}
+	if progress.Total > 0 {
+		detail.Progress = progress.Completed * 100 / progress.Total
+	}
+	return detail
+}
+
+// findMilestone loads a milestone of a project from the "milestoneId" URL parameter, writing a 404 response when it does not exist
+func findMilestone(c *gin.Context, projectID uint) (models.Milestone, bool) {
+	var milestone models.Milestone
+	if err := database.DB.Where("id = ? AND project_id = ?", c.Param("milestoneId"), projectID).First(&milestone).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Milestone not found"})
+		return milestone, false
+	}
+	return milestone, true
+}
+
+// ListMilestones godoc
+// @Summary      List project milestones
+// @Description  Lists the milestones of a project with task counts and completion percentage.
+// @Tags         Project Tasks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} MilestoneListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/milestones [get]
+func ListMilestones(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can view project milestones")
+	if !ok {
+		return
+	}
+
+	var milestones []models.Milestone
+	if err := database.DB.Where("project_id = ?", project.ID).Order("due_date IS NULL, due_date, id").Find(&milestones).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch milestones"})
+		return
+	}
+
+	progress, err := loadMilestoneProgress(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute milestone progress"})
+		return
+	}
+
+	response := make([]MilestoneDetail, len(milestones))
+	for i, milestone := range milestones {
+		response[i] = toMilestoneDetail(milestone, progress[milestone.ID])
+	}
+
+	c.JSON(http.StatusOK, MilestoneListResponse{Milestones: response})
+}
+
+// CreateMilestone godoc
+// @Summary      Create a project milestone
+// @Description  Creates a milestone that tasks can be grouped under. Requires the manage_milestones capability.
+// @Tags         Project Tasks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body MilestoneRequest true "Milestone attributes"
+// @Success      201 {object} MilestoneDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/milestones [post]
+func CreateMilestone(c *gin.Context) {
+	var request MilestoneRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageMilestones, "Your role doesn't allow managing milestones")
+	if !ok {
+		return
+	}
+
+	milestone := models.Milestone{
+		ProjectID:   project.ID,
+		Title:       request.Title,
+		Description: request.Description,
+		DueDate:     request.DueDate,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&milestone).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditMilestoneCreated, TargetType: "milestone", TargetID: milestone.ID,
+			ProjectID: auditProject(project.ID), After: toMilestoneDetail(milestone, milestoneProgress{}),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create milestone"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toMilestoneDetail(milestone, milestoneProgress{}))
+}
+
+// EditMilestone godoc
+// @Summary      Edit a project milestone
+// @Description  Updates the attributes of a milestone. Requires the manage_milestones capability.
+// @Tags         Project Tasks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        milestoneId path int true "Milestone ID"
+// @Param        request body MilestoneRequest true "Milestone attributes"
+// @Success      200 {object} MilestoneDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/milestones/{milestoneId} [put]
+func EditMilestone(c *gin.Context) {
+	var request MilestoneRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageMilestones, "Your role doesn't allow managing milestones")
+	if !ok {
+		return
+	}
+	milestone, ok := findMilestone(c, project.ID)
+	if !ok {
+		return
+	}
+
+	before := toMilestoneDetail(milestone, milestoneProgress{})
+	milestone.Title = request.Title
+	milestone.Description = request.Description
+	milestone.DueDate = request.DueDate
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&milestone).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditMilestoneUpdated, TargetType: "milestone", TargetID: milestone.ID,
+			ProjectID: auditProject(project.ID), Before: before, After: toMilestoneDetail(milestone, milestoneProgress{}),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update milestone"})
+		return
+	}
+
+	progress, err := loadMilestoneProgress(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute milestone progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toMilestoneDetail(milestone, progress[milestone.ID]))
+}
+
+// DeleteMilestone godoc
+// @Summary      Delete a project milestone
+// @Description  Deletes a milestone. Its tasks are kept and no longer belong to a milestone.
+// @Tags         Project Tasks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        milestoneId path int true "Milestone ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/milestones/{milestoneId} [delete]
+func DeleteMilestone(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageMilestones, "Your role doesn't allow managing milestones")
+	if !ok {
+		return
+	}
+	milestone, ok := findMilestone(c, project.ID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Task{}).Where("milestone_id = ?", milestone.ID).Update("milestone_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&milestone).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditMilestoneDeleted, TargetType: "milestone", TargetID: milestone.ID,
+			ProjectID: auditProject(project.ID), Before: toMilestoneDetail(milestone, milestoneProgress{}),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete milestone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Milestone deleted successfully"})
+}
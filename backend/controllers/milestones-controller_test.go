@@ -0,0 +1,68 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/models"
+)
+
+func TestMilestoneProgress(t *testing.T) {
+	f := setupTasksTest(t)
+
+	// programmers cannot manage milestones
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/milestones", f.project.ID), f.programmerToken,
+		map[string]interface{}{"title": "Paper submission"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/milestones", f.project.ID), f.ownerToken,
+		map[string]interface{}{"title": "Paper submission", "due_date": "2025-09-01T00:00:00Z"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var milestone controllers.MilestoneDetail
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &milestone))
+
+	createTestTask(t, f, map[string]interface{}{"title": "Experiments", "milestone_id": milestone.ID, "status": "done"})
+	createTestTask(t, f, map[string]interface{}{"title": "Related work", "milestone_id": milestone.ID, "status": "done"})
+	createTestTask(t, f, map[string]interface{}{"title": "Abstract", "milestone_id": milestone.ID})
+	createTestTask(t, f, map[string]interface{}{"title": "Unrelated"})
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/milestones", f.project.ID), f.programmerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list controllers.MilestoneListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Milestones, 1)
+	assert.Equal(t, 3, list.Milestones[0].TotalTasks)
+	assert.Equal(t, 2, list.Milestones[0].CompletedTasks)
+	assert.Equal(t, 66, list.Milestones[0].Progress)
+
+	// edit milestone
+	w = projectRequest(f.router, "PUT", fmt.Sprintf("/projects/%d/milestones/%d", f.project.ID, milestone.ID), f.ownerToken,
+		map[string]interface{}{"title": "Camera ready"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Camera ready")
+
+	// deleting the milestone keeps its tasks
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/projects/%d/milestones/%d", f.project.ID, milestone.ID), f.ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var remaining int64
+	database.DB.Model(&models.Task{}).Where("project_id = ? AND milestone_id IS NULL", f.project.ID).Count(&remaining)
+	assert.Equal(t, int64(4), remaining)
+}
+
+func TestTaskRejectsMilestoneFromOtherProject(t *testing.T) {
+	f := setupTasksTest(t)
+
+	other := models.Milestone{ProjectID: f.project.ID + 100, Title: "Elsewhere"}
+	database.DB.Create(&other)
+
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/tasks", f.project.ID), f.ownerToken,
+		map[string]interface{}{"title": "Misfiled", "milestone_id": other.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
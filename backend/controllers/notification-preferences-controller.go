@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type NotificationPreferenceDetail struct {
+	Type      string `json:"type" binding:"required" example:"mention"`
+	Frequency string `json:"frequency" binding:"required,oneof=immediate daily weekly off" example:"daily"`
+}
+
+type NotificationPreferencesResponse struct {
+	Preferences []NotificationPreferenceDetail `json:"preferences"`
+}
+
+type NotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceDetail `json:"preferences" binding:"required,dive"`
+}
+
+// respondWithPreferences writes every notification type with the user's email frequency for it
+func respondWithPreferences(c *gin.Context, userID uint) {
+	preferences, err := services.EmailPreferences(database.DB, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch notification preferences"})
+		return
+	}
+
+	response := NotificationPreferencesResponse{Preferences: make([]NotificationPreferenceDetail, len(models.NotificationTypes))}
+	for i, notificationType := range models.NotificationTypes {
+		response.Preferences[i] = NotificationPreferenceDetail{Type: string(notificationType), Frequency: string(preferences[notificationType])}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetNotificationPreferences godoc
+// @Summary      Get notification email preferences
+// @Description  Lists how often the user is emailed about each type of notification.
+// @Tags         Notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "User ID"
+// @Success      200 {object} NotificationPreferencesResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/notification-preferences [get]
+func GetNotificationPreferences(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	respondWithPreferences(c, uint(userID))
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary      Update notification email preferences
+// @Description  Sets how often the user is emailed about the given notification types: immediately, in a daily or weekly digest, or not at all. Types not included are left unchanged.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "User ID"
+// @Param        request body NotificationPreferencesRequest true "Preferences to change"
+// @Success      200 {object} NotificationPreferencesResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/{id}/notification-preferences [put]
+func UpdateNotificationPreferences(c *gin.Context) {
+	var request NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	for _, preference := range request.Preferences {
+		if !slices.Contains(models.NotificationTypes, models.NotificationType(preference.Type)) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown notification type: " + preference.Type})
+			return
+		}
+	}
+
+	userID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := services.EmailPreferences(tx, uint(userID))
+		if err != nil {
+			return err
+		}
+		for _, preference := range request.Preferences {
+			err := services.SetEmailPreference(tx, uint(userID), models.NotificationType(preference.Type), models.EmailFrequency(preference.Frequency))
+			if err != nil {
+				return err
+			}
+		}
+		after, err := services.EmailPreferences(tx, uint(userID))
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditNotificationPrefsUpdated, TargetType: "user", TargetID: uint(userID),
+			Before: before, After: after,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update notification preferences"})
+		return
+	}
+
+	respondWithPreferences(c, uint(userID))
+}
+
+// UnsubscribeFromEmails godoc
+// @Summary      Unsubscribe from notification emails
+// @Description  Turns off notification emails using the signed token from an email's unsubscribe link. No login is required.
+// @Tags         Notifications
+// @Produce      json
+// @Param        token query string true "Unsubscribe token"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /notifications/unsubscribe [get]
+// @Router       /notifications/unsubscribe [post]
+func UnsubscribeFromEmails(c *gin.Context) {
+	userID, notificationType, ok := services.ParseUnsubscribeToken(c.Query("token"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid unsubscribe link"})
+		return
+	}
+
+	types := []models.NotificationType{models.NotificationType(notificationType)}
+	if notificationType == services.UnsubscribeAll {
+		types = models.NotificationTypes
+	} else if !slices.Contains(models.NotificationTypes, types[0]) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid unsubscribe link"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := services.EmailPreferences(tx, userID)
+		if err != nil {
+			return err
+		}
+		for _, t := range types {
+			if err := services.SetEmailPreference(tx, userID, t, models.EmailFrequencyOff); err != nil {
+				return err
+			}
+		}
+		after, err := services.EmailPreferences(tx, userID)
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditEmailUnsubscribed, TargetType: "user", TargetID: userID,
+			Before: before, After: after,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "You have been unsubscribed"})
+}
@@ -0,0 +1,108 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+)
+
+func setupPreferencesTest(t *testing.T) (*gin.Engine, models.User, string) {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.NotificationPreference{})
+	database.DB.Exec("DELETE FROM notification_preferences")
+
+	user := models.User{Email: "preferences@example.com", Password: "password"}
+	database.DB.Create(&user)
+	token, _ := utils.GenerateJWT(user.ID, user.Email)
+
+	router := gin.Default()
+	router.GET("/users/:id/notification-preferences", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.GetNotificationPreferences)
+	router.PUT("/users/:id/notification-preferences", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UpdateNotificationPreferences)
+	router.GET("/notifications/unsubscribe", controllers.UnsubscribeFromEmails)
+	router.POST("/notifications/unsubscribe", controllers.UnsubscribeFromEmails)
+	return router, user, token
+}
+
+func preferenceFrequencies(t *testing.T, body []byte) map[string]string {
+	var response controllers.NotificationPreferencesResponse
+	assert.NoError(t, json.Unmarshal(body, &response))
+
+	frequencies := make(map[string]string)
+	for _, preference := range response.Preferences {
+		frequencies[preference.Type] = preference.Frequency
+	}
+	return frequencies
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	router, user, token := setupPreferencesTest(t)
+	path := fmt.Sprintf("/users/%d/notification-preferences", user.ID)
+
+	w := projectRequest(router, "GET", path, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	frequencies := preferenceFrequencies(t, w.Body.Bytes())
+	assert.Len(t, frequencies, len(models.NotificationTypes))
+	assert.Equal(t, "immediate", frequencies["invitation_received"])
+	assert.Equal(t, "daily", frequencies["mention"])
+
+	w = projectRequest(router, "PUT", path, token, map[string]interface{}{
+		"preferences": []map[string]string{{"type": "mention", "frequency": "weekly"}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	frequencies = preferenceFrequencies(t, w.Body.Bytes())
+	assert.Equal(t, "weekly", frequencies["mention"])
+	assert.Equal(t, "immediate", frequencies["invitation_received"])
+
+	// updating again replaces the stored preference
+	w = projectRequest(router, "PUT", path, token, map[string]interface{}{
+		"preferences": []map[string]string{{"type": "mention", "frequency": "immediate"}},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "immediate", preferenceFrequencies(t, w.Body.Bytes())["mention"])
+
+	w = projectRequest(router, "PUT", path, token, map[string]interface{}{
+		"preferences": []map[string]string{{"type": "birthday", "frequency": "daily"}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "PUT", path, token, map[string]interface{}{
+		"preferences": []map[string]string{{"type": "mention", "frequency": "hourly"}},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUnsubscribeFromEmails(t *testing.T) {
+	router, user, token := setupPreferencesTest(t)
+
+	// unsubscribing from one type works without logging in
+	w := projectRequest(router, "GET", "/notifications/unsubscribe?token="+url.QueryEscape(services.UnsubscribeToken(user.ID, "mention")), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(router, "GET", fmt.Sprintf("/users/%d/notification-preferences", user.ID), token, nil)
+	frequencies := preferenceFrequencies(t, w.Body.Bytes())
+	assert.Equal(t, "off", frequencies["mention"])
+	assert.Equal(t, "immediate", frequencies["invitation_received"])
+
+	// one-click unsubscribe from everything
+	w = projectRequest(router, "POST", "/notifications/unsubscribe?token="+url.QueryEscape(services.UnsubscribeToken(user.ID, services.UnsubscribeAll)), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(router, "GET", fmt.Sprintf("/users/%d/notification-preferences", user.ID), token, nil)
+	for _, frequency := range preferenceFrequencies(t, w.Body.Bytes()) {
+		assert.Equal(t, "off", frequency)
+	}
+
+	w = projectRequest(router, "GET", "/notifications/unsubscribe?token=forged.token", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
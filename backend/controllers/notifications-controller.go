@@ -0,0 +1,254 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type NotificationDetail struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type" example:"invitation_received"`
+	Message   string     `json:"message" example:"You were invited to join \"Protein Folding\" as programmer"`
+	Link      string     `json:"link" example:"/projects/invitations"`
+	ActorID   *uint      `json:"actor_id"`
+	ProjectID *uint      `json:"project_id"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []NotificationDetail `json:"notifications"`
+	Page          int                  `json:"page" example:"1"`
+	PageSize      int                  `json:"page_size" example:"20"`
+	Total         int64                `json:"total" example:"42"`
+	UnreadCount   int64                `json:"unread_count" example:"3"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count" example:"3"`
+}
+
+// parsePagination reads the "page" and "page_size" query parameters, writing a 400 response when they are invalid
+func parsePagination(c *gin.Context) (page, pageSize int, ok bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid page"})
+		return 0, 0, false
+	}
+	pageSize, err = strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxPageSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "page_size must be between 1 and 100"})
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+func toNotificationDetail(notification models.Notification) NotificationDetail {
+	return NotificationDetail{
+		ID:        notification.ID,
+		Type:      string(notification.Type),
+		Message:   notification.Message,
+		Link:      notification.Link,
+		ActorID:   notification.ActorID,
+		ProjectID: notification.ProjectID,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// countUnreadNotifications returns the number of unread notifications of a user
+func countUnreadNotifications(userID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// ListNotifications godoc
+// @Summary      List notifications
+// @Description  Lists the authenticated user's notifications, newest first.
+// @Tags         Notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Notifications per page (max 100)" default(20)
+// @Param        unread query bool false "Only return unread notifications"
+// @Success      200 {object} NotificationListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /notifications [get]
+func ListNotifications(c *gin.Context) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unread, _ := strconv.ParseBool(c.Query("unread")); unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch notifications"})
+		return
+	}
+
+	var notifications []models.Notification
+	err := query.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&notifications).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch notifications"})
+		return
+	}
+
+	unreadCount, err := countUnreadNotifications(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch notifications"})
+		return
+	}
+
+	response := NotificationListResponse{
+		Notifications: make([]NotificationDetail, len(notifications)),
+		Page:          page,
+		PageSize:      pageSize,
+		Total:         total,
+		UnreadCount:   unreadCount,
+	}
+	for i, notification := range notifications {
+		response.Notifications[i] = toNotificationDetail(notification)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUnreadNotificationCount godoc
+// @Summary      Count unread notifications
+// @Description  Returns the number of unread notifications of the authenticated user.
+// @Tags         Notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} UnreadCountResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /notifications/unread-count [get]
+func GetUnreadNotificationCount(c *gin.Context) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+
+	count, err := countUnreadNotifications(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, UnreadCountResponse{UnreadCount: count})
+}
+
+// MarkNotificationRead godoc
+// @Summary      Mark a notification as read
+// @Description  Marks one of the authenticated user's notifications as read.
+// @Tags         Notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Notification ID"
+// @Success      200 {object} NotificationDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /notifications/{id}/read [post]
+func MarkNotificationRead(c *gin.Context) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+
+	var notification models.Notification
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Notification not found"})
+		return
+	}
+
+	// keep the original read time when marking twice
+	if notification.ReadAt == nil {
+		now := time.Now()
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&notification).Update("read_at", now).Error; err != nil {
+				return err
+			}
+			return recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditNotificationRead, TargetType: "notification", TargetID: notification.ID,
+				ProjectID: notification.ProjectID, Before: gin.H{"read_at": nil}, After: gin.H{"read_at": now},
+			})
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update notification"})
+			return
+		}
+		notification.ReadAt = &now
+	}
+
+	c.JSON(http.StatusOK, toNotificationDetail(notification))
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary      Mark all notifications as read
+// @Description  Marks every unread notification of the authenticated user as read.
+// @Tags         Notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /notifications/read-all [post]
+func MarkAllNotificationsRead(c *gin.Context) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Notification{}).
+			Where("user_id = ? AND read_at IS NULL", userID).
+			Update("read_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditNotificationsReadAll, TargetType: "user", TargetID: userID,
+			After: gin.H{"marked_read": result.RowsAffected},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "All notifications marked as read"})
+}
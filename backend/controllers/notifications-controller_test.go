@@ -0,0 +1,147 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+func setupNotificationsTest(t *testing.T) *gin.Engine {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+
+	router := gin.Default()
+	router.POST("/projects/:id/collaborators", middleware.AuthRequired(), controllers.InviteCollaborator)
+	router.PUT("/projects/:id/collaborators/:userId", middleware.AuthRequired(), controllers.UpdateCollaboratorRole)
+	router.POST("/projects/:id/collaborators/invitations/:invitationId/:action", middleware.AuthRequired(), controllers.RespondToProjectInvitation)
+	router.POST("/projects/:id/tasks", middleware.AuthRequired(), controllers.CreateTask)
+	router.GET("/notifications", middleware.AuthRequired(), controllers.ListNotifications)
+	router.GET("/notifications/unread-count", middleware.AuthRequired(), controllers.GetUnreadNotificationCount)
+	router.POST("/notifications/read-all", middleware.AuthRequired(), controllers.MarkAllNotificationsRead)
+	router.POST("/notifications/:id/read", middleware.AuthRequired(), controllers.MarkNotificationRead)
+	return router
+}
+
+func listNotifications(t *testing.T, router *gin.Engine, token, query string) controllers.NotificationListResponse {
+	w := projectRequest(router, "GET", "/notifications"+query, token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response controllers.NotificationListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func TestCollaborationNotifications(t *testing.T) {
+	router := setupNotificationsTest(t)
+
+	owner := models.User{Email: "notify_owner@example.com", Password: "password"}
+	invitee := models.User{Email: "notify_invitee@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&invitee)
+	project := models.Project{Title: "Notify Project", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	inviteeToken, _ := utils.GenerateJWT(invitee.ID, invitee.Email)
+
+	// invitation received
+	w := projectRequest(router, "POST", fmt.Sprintf("/projects/%d/collaborators", project.ID), ownerToken,
+		map[string]string{"email": invitee.Email, "role": "programmer"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	list := listNotifications(t, router, inviteeToken, "")
+	assert.Len(t, list.Notifications, 1)
+	assert.Equal(t, string(models.NotificationInvitationReceived), list.Notifications[0].Type)
+	assert.Equal(t, int64(1), list.UnreadCount)
+
+	// invitation accepted
+	var invitation models.Invitation
+	database.DB.Where("email = ?", invitee.Email).First(&invitation)
+	w = projectRequest(router, "POST", fmt.Sprintf("/projects/%d/collaborators/invitations/%d/accept", project.ID, invitation.ID), inviteeToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	list = listNotifications(t, router, ownerToken, "")
+	assert.Len(t, list.Notifications, 1)
+	assert.Equal(t, string(models.NotificationInvitationAccepted), list.Notifications[0].Type)
+
+	// role changed
+	w = projectRequest(router, "PUT", fmt.Sprintf("/projects/%d/collaborators/%d", project.ID, invitee.ID), ownerToken, map[string]string{"role": "editor"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "PUT", fmt.Sprintf("/projects/%d/collaborators/%d", project.ID, invitee.ID), inviteeToken, map[string]string{"role": "programmer"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var collaborator models.Collaborator
+	database.DB.Where("project_id = ? AND user_id = ?", project.ID, invitee.ID).First(&collaborator)
+	assert.Equal(t, "editor", collaborator.Role)
+
+	// task assigned, including self-assignment which is not notified
+	w = projectRequest(router, "POST", fmt.Sprintf("/projects/%d/tasks", project.ID), ownerToken, map[string]interface{}{"title": "Review", "assignee_id": invitee.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(router, "POST", fmt.Sprintf("/projects/%d/tasks", project.ID), inviteeToken, map[string]interface{}{"title": "Own task", "assignee_id": invitee.ID})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	list = listNotifications(t, router, inviteeToken, "")
+	assert.Len(t, list.Notifications, 3)
+	assert.Equal(t, string(models.NotificationTaskAssigned), list.Notifications[0].Type)
+	assert.Equal(t, string(models.NotificationRoleChanged), list.Notifications[1].Type)
+}
+
+func TestNotificationReadState(t *testing.T) {
+	router := setupNotificationsTest(t)
+
+	user := models.User{Email: "notify_reader@example.com", Password: "password"}
+	other := models.User{Email: "notify_other@example.com", Password: "password"}
+	database.DB.Create(&user)
+	database.DB.Create(&other)
+	for i := 0; i < 5; i++ {
+		database.DB.Create(&models.Notification{UserID: user.ID, Type: models.NotificationMention, Message: fmt.Sprintf("Mention %d", i)})
+	}
+	foreign := models.Notification{UserID: other.ID, Type: models.NotificationMention, Message: "Not yours"}
+	database.DB.Create(&foreign)
+
+	token, _ := utils.GenerateJWT(user.ID, user.Email)
+
+	// pagination
+	page := listNotifications(t, router, token, "?page=2&page_size=2")
+	assert.Len(t, page.Notifications, 2)
+	assert.Equal(t, int64(5), page.Total)
+	assert.Equal(t, "Mention 2", page.Notifications[0].Message)
+
+	w := projectRequest(router, "GET", "/notifications?page_size=500", token, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// mark one as read
+	w = projectRequest(router, "POST", fmt.Sprintf("/notifications/%d/read", page.Notifications[0].ID), token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "POST", fmt.Sprintf("/notifications/%d/read", foreign.ID), token, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	unread := listNotifications(t, router, token, "?unread=true")
+	assert.Len(t, unread.Notifications, 4)
+	assert.Equal(t, int64(4), unread.UnreadCount)
+
+	// mark all as read
+	w = projectRequest(router, "POST", "/notifications/read-all", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(router, "GET", "/notifications/unread-count", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var count controllers.UnreadCountResponse
+	json.Unmarshal(w.Body.Bytes(), &count)
+	assert.Equal(t, int64(0), count.UnreadCount)
+
+	// other users' notifications are untouched
+	database.DB.First(&foreign, foreign.ID)
+	assert.Nil(t, foreign.ReadAt)
+}
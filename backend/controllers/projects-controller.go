@@ -1,15 +1,18 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"backend/database"
 	"backend/models"
+	"backend/services"
 	"backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ProjectRetrievalResponse struct {
@@ -20,6 +23,7 @@ type ProjectRetrievalResponse struct {
 	Visibility     string   `json:"visibility"`
 	Status         string   `json:"status"`
 	OwnerID        uint     `json:"owner_id"`
+	GroupID        *uint    `json:"group_id"`
 }
 
 type ProjectListResponse struct {
@@ -47,7 +51,7 @@ func RetrieveProject(c *gin.Context) {
 
 	// Find project in database
 	var project models.Project
-	if err := database.DB.First(&project, projectID).Error; err != nil {
+	if err := database.DB.Where("hidden_at IS NULL").First(&project, projectID).Error; err != nil {
 		if err.Error() == "record not found" {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
 			return
@@ -65,6 +69,7 @@ func RetrieveProject(c *gin.Context) {
 		Visibility:     project.Visibility,
 		Status:         project.Status,
 		OwnerID:        project.OwnerID,
+		GroupID:        project.GroupID,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -82,8 +87,8 @@ func RetrieveProject(c *gin.Context) {
 func ListProjects(c *gin.Context) {
 	var projects []models.Project
 
-	// Fetch all projects with their owners
-	if err := database.DB.Find(&projects).Error; err != nil {
+	// Fetch all projects with their owners, leaving out those hidden by moderators
+	if err := database.DB.Where("hidden_at IS NULL").Find(&projects).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch projects"})
 		return
 	}
@@ -99,6 +104,7 @@ func ListProjects(c *gin.Context) {
 			Visibility:     project.Visibility,
 			Status:         project.Status,
 			OwnerID:        project.OwnerID,
+			GroupID:        project.GroupID,
 		}
 	}
 
@@ -109,8 +115,10 @@ type ProjectCreationRequest struct {
 	Title          string   `json:"title" binding:"required"`
 	Description    string   `json:"description"`
 	RequiredSkills []string `json:"required_skills"`
-	Visibility     string   `json:"visibility" binding:"oneof=private"`
+	Visibility     string   `json:"visibility" binding:"oneof=private public"`
 	Status         string   `json:"status" binding:"oneof=open in-progress completed"`
+	// group to create the project in; only its PIs and managers can do so
+	GroupID *uint `json:"group_id" example:"1"`
 }
 
 type ProjectCreationResponse struct {
@@ -120,13 +128,14 @@ type ProjectCreationResponse struct {
 
 // CreateProject godoc
 // @Summary      Create research project
-// @Description  Creates a new research project and assigns the creator as an owner
+// @Description  Creates a new research project and assigns the creator as an owner. Projects created in a group are also managed by the group's PIs and managers, and its members collaborate on them.
 // @Tags         Projects
 // @Accept       json
 // @Produce      json
 // @Param        request body ProjectCreationRequest true "Project attributes"
 // @Success      201 {object} ProjectCreationResponse
 // @Failure      400 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
 // @Failure      500 {object} ErrorResponse
 // @Router       /projects [post]
 func CreateProject(c *gin.Context) {
@@ -145,6 +154,12 @@ func CreateProject(c *gin.Context) {
 		return
 	}
 
+	// only managers can add projects to a group
+	if request.GroupID != nil && !services.GroupRoleOf(database.DB, *request.GroupID, userID).AtLeast(models.GroupRoleManager) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only group PIs and managers can create group projects"})
+		return
+	}
+
 	// begin database transaction
 	tx := database.DB.Begin()
 
@@ -155,6 +170,7 @@ func CreateProject(c *gin.Context) {
 		OwnerID:     userID,
 		Visibility:  request.Visibility,
 		Status:      request.Status,
+		GroupID:     request.GroupID,
 	}
 	project.SetRequiredSkills(request.RequiredSkills)
 
@@ -179,6 +195,16 @@ func CreateProject(c *gin.Context) {
 		return
 	}
 
+	err := recordAudit(c, tx, services.AuditEntry{
+		Action: models.AuditProjectCreated, TargetType: "project", TargetID: project.ID,
+		ProjectID: auditProject(project.ID), After: request,
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create project"})
+		return
+	}
+
 	// commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		// If commit fails, rollback and return error
@@ -195,7 +221,7 @@ func CreateProject(c *gin.Context) {
 
 type CollabInvitationRequest struct {
 	Email string `json:"email" binding:"required,email"`
-	Role  string `json:"role" binding:"required,oneof=programmer editor"`
+	Role  string `json:"role" binding:"required,max=50" example:"programmer"` // built-in role other than owner, or one of the project's custom roles
 }
 
 type CollabInvitationResponse struct {
@@ -204,7 +230,7 @@ type CollabInvitationResponse struct {
 
 // InviteCollaborator godoc
 // @Summary      Invite a collaborator to a project
-// @Description  Sends an invitation to a user to collaborate on a project
+// @Description  Sends an invitation to a user to collaborate on a project with a built-in or custom role. Requires the invite capability, and every capability of the offered role.
 // @Tags         Projects
 // @Accept       json
 // @Produce      json
@@ -245,9 +271,14 @@ func InviteCollaborator(c *gin.Context) {
 		return
 	}
 
-	if project.OwnerID != userID {
+	access := projectAccess(project, userID)
+	if !access.Can(models.CapabilityInvite) {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Your role doesn't allow inviting collaborators"})
+		return
+	}
+	if !checkGrantableRole(c, tx, project, access, models.CollaboratorRole(request.Role)) {
 		tx.Rollback()
-		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only project owners can invite collaborators"})
 		return
 	}
 
@@ -269,6 +300,14 @@ func InviteCollaborator(c *gin.Context) {
 		return
 	}
 
+	// users can't be invited by someone they blocked
+	var invitee models.User
+	if err := tx.Where("email = ?", request.Email).First(&invitee).Error; err == nil && services.HasBlocked(tx, invitee.ID, userID) {
+		tx.Rollback()
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You cannot invite this user"})
+		return
+	}
+
 	// Create invitation
 	invitation := models.Invitation{
 		ProjectID: project.ID,
@@ -284,12 +323,31 @@ func InviteCollaborator(c *gin.Context) {
 		return
 	}
 
+	// let the invitee know if they already have an account
+	notification, err := services.NotifyInvitationReceived(tx, invitation, project)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create invitation"})
+		return
+	}
+
+	err = recordAudit(c, tx, services.AuditEntry{
+		Action: models.AuditInvitationCreated, TargetType: "invitation", TargetID: invitation.ID,
+		ProjectID: auditProject(project.ID), After: gin.H{"email": invitation.Email, "role": invitation.Role},
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create invitation"})
+		return
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to commit invitation"})
 		return
 	}
+	services.PublishNotifications(notification)
 
 	c.JSON(http.StatusCreated, CollabInvitationResponse{
 		Message: "Invitation sent successfully",
@@ -441,6 +499,17 @@ func RespondToProjectInvitation(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add collaborator"})
 			return
 		}
+
+		err := services.EnqueueWebhookEvent(tx, project.ID, models.WebhookEventCollaboratorJoined, services.CollaboratorEvent{
+			ProjectID: project.ID,
+			UserID:    userID,
+			Role:      collaborator.Role,
+		})
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add collaborator"})
+			return
+		}
 	} else {
 		invitation.Status = models.InvitationStatusRejected
 	}
@@ -451,14 +520,576 @@ func RespondToProjectInvitation(c *gin.Context) {
 		return
 	}
 
-	tx.Commit()
+	notification, err := services.NotifyInvitationResponse(tx, invitation, project, userID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update invitation"})
+		return
+	}
+
+	auditAction := models.AuditInvitationRejected
+	if invitation.Status == models.InvitationStatusAccepted {
+		auditAction = models.AuditInvitationAccepted
+	}
+	err = recordAudit(c, tx, services.AuditEntry{
+		Action: auditAction, TargetType: "invitation", TargetID: invitation.ID, ProjectID: auditProject(project.ID),
+		Before: gin.H{"status": models.InvitationStatusPending}, After: gin.H{"status": invitation.Status},
+	})
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update invitation"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update invitation"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	if invitation.Status == models.InvitationStatusAccepted {
+		services.PublishProjectEvent(database.DB, project.ID, services.EventCollaboratorAdded,
+			services.CollaboratorEvent{ProjectID: project.ID, UserID: userID, Role: string(invitation.Role)})
+	}
 
 	c.JSON(http.StatusOK, MessageResponse{Message: fmt.Sprintf("Invitation %s successfully", action+"ed")})
 }
 
+type ProjectStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=open in-progress completed" example:"in-progress"`
+}
+
+// ProjectStatusChange is the payload of project.status_changed webhook events
+type ProjectStatusChange struct {
+	PreviousStatus string `json:"previous_status" example:"open"`
+	Status         string `json:"status" example:"in-progress"`
+}
+
+// UpdateProjectStatus godoc
+// @Summary      Change a project's status
+// @Description  Moves a project between the open, in-progress and completed states. Requires the edit_metadata capability.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body ProjectStatusRequest true "New status"
+// @Success      200 {object} ProjectRetrievalResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/status [put]
+func UpdateProjectStatus(c *gin.Context) {
+	var request ProjectStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityEditMetadata, "Your role doesn't allow changing the project status")
+	if !ok {
+		return
+	}
+
+	if project.Status != request.Status {
+		change := ProjectStatusChange{PreviousStatus: project.Status, Status: request.Status}
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&project).Update("status", request.Status).Error; err != nil {
+				return err
+			}
+			err := recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditProjectStatusChanged, TargetType: "project", TargetID: project.ID, ProjectID: auditProject(project.ID),
+				Before: gin.H{"status": change.PreviousStatus}, After: gin.H{"status": change.Status},
+			})
+			if err != nil {
+				return err
+			}
+			return services.EnqueueWebhookEvent(tx, project.ID, models.WebhookEventStatusChanged, change)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update project status"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, ProjectRetrievalResponse{
+		ID:             project.ID,
+		Title:          project.Title,
+		Description:    project.Description,
+		RequiredSkills: project.GetRequiredSkills(),
+		Visibility:     project.Visibility,
+		Status:         project.Status,
+		OwnerID:        project.OwnerID,
+		GroupID:        project.GroupID,
+	})
+}
+
+type CollaboratorRoleRequest struct {
+	Role string `json:"role" binding:"required,max=50" example:"editor"` // built-in role other than owner, or one of the project's custom roles
+}
+
+// UpdateCollaboratorRole godoc
+// @Summary      Change a collaborator's role
+// @Description  Changes the role of a project collaborator. Requires the manage_roles capability, and every capability of the new role.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        userId path int true "Collaborator user ID"
+// @Param        request body CollaboratorRoleRequest true "New role"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/collaborators/{userId} [put]
+func UpdateCollaboratorRole(c *gin.Context) {
+	var request CollaboratorRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, access, ok := requireProjectCapability(c, models.CapabilityManageRoles, "Your role doesn't allow changing collaborator roles")
+	if !ok {
+		return
+	}
+
+	var collaborator models.Collaborator
+	if err := database.DB.Where("project_id = ? AND user_id = ?", project.ID, c.Param("userId")).First(&collaborator).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Collaborator not found"})
+		return
+	}
+	if collaborator.UserID == project.OwnerID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "The project owner's role cannot be changed"})
+		return
+	}
+	if collaborator.Role == request.Role {
+		c.JSON(http.StatusOK, MessageResponse{Message: "Role unchanged"})
+		return
+	}
+	// the current role is taken away, so it must not grant more than the user changing it holds either
+	if !checkGrantableRole(c, database.DB, project, access, models.CollaboratorRole(request.Role)) {
+		return
+	}
+	if current, err := services.RoleCapabilities(database.DB, project.ID, models.CollaboratorRole(collaborator.Role)); err == nil && !access.CanAll(current) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can't change the role of a collaborator with capabilities you don't hold"})
+		return
+	}
+
+	role := models.CollaboratorRole(request.Role)
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		before := collaborator.Role
+		if err := tx.Model(&collaborator).Update("role", request.Role).Error; err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditCollaboratorRoleChanged, TargetType: "user", TargetID: collaborator.UserID, ProjectID: auditProject(project.ID),
+			Before: gin.H{"role": before}, After: gin.H{"role": request.Role},
+		})
+		if err != nil {
+			return err
+		}
+		notification, err = services.NotifyRoleChanged(tx, project, collaborator.UserID, utils.InferUserID(c), role)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update collaborator role"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	services.PublishProjectEvent(database.DB, project.ID, services.EventCollaboratorRoleChanged,
+		services.CollaboratorEvent{ProjectID: project.ID, UserID: collaborator.UserID, Role: request.Role})
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Role updated successfully"})
+}
+
+type CollaboratorDetail struct {
+	UserID    uint                      `json:"user_id"`
+	FullName  string                    `json:"full_name"`
+	AvatarURL string                    `json:"avatar_url"`
+	Role      string                    `json:"role" example:"programmer"`
+	Status    models.CollaboratorStatus `json:"status" example:"active"`
+	JoinedAt  *time.Time                `json:"joined_at"`
+	LeftAt    *time.Time                `json:"left_at"`
+}
+
+type CollaboratorListResponse struct {
+	// active collaborators and those on leave
+	Current []CollaboratorDetail `json:"current"`
+	// alumni, credited for their past contributions
+	Past []CollaboratorDetail `json:"past"`
+}
+
+type CollaboratorStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active on-leave alumni" example:"alumni"`
+}
+
+// ListCollaborators godoc
+// @Summary      List a project's team
+// @Description  Lists the current members of a project, including those on leave, and its alumni. Alumni keep the credit for their contributions but can only read the project. Only collaborators can see the team of a private project.
+// @Tags         Projects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} CollaboratorListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/collaborators [get]
+func ListCollaborators(c *gin.Context) {
+	project, _, ok := requireProjectReadAccess(c, "Only collaborators can see the team of private projects")
+	if !ok {
+		return
+	}
+
+	var collaborators []models.Collaborator
+	if err := database.DB.Preload("User").Where("project_id = ?", project.ID).Order("joined_at, id").Find(&collaborators).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch collaborators"})
+		return
+	}
+
+	response := CollaboratorListResponse{Current: []CollaboratorDetail{}, Past: []CollaboratorDetail{}}
+	for _, collaborator := range collaborators {
+		detail := CollaboratorDetail{
+			UserID:    collaborator.UserID,
+			FullName:  collaborator.User.FullName,
+			AvatarURL: avatarURL(collaborator.User),
+			Role:      collaborator.Role,
+			Status:    collaborator.Status,
+			JoinedAt:  collaborator.JoinedAt,
+			LeftAt:    collaborator.LeftAt,
+		}
+		if collaborator.Status.IsCurrent() {
+			response.Current = append(response.Current, detail)
+		} else {
+			response.Past = append(response.Past, detail)
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCollaboratorStatus godoc
+// @Summary      Change a collaborator's status
+// @Description  Marks a collaborator as active, on leave or alumni. Alumni can only read the project and are listed as past team members. Collaborators can go on leave, come back from leave or leave the team themselves; otherwise this requires the manage_roles capability, and every capability of the collaborator's role. The owner stays active until the project is transferred.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        userId path int true "Collaborator user ID"
+// @Param        request body CollaboratorStatusRequest true "New status"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/collaborators/{userId}/status [put]
+func UpdateCollaboratorStatus(c *gin.Context) {
+	var request CollaboratorStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, access, ok := loadProjectMembership(c)
+	if !ok {
+		return
+	}
+
+	var collaborator models.Collaborator
+	if err := database.DB.Where("project_id = ? AND user_id = ?", project.ID, c.Param("userId")).First(&collaborator).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Collaborator not found"})
+		return
+	}
+	if collaborator.UserID == project.OwnerID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "The project owner stays active until the project is transferred"})
+		return
+	}
+	status := models.CollaboratorStatus(request.Status)
+	if collaborator.Status == status {
+		c.JSON(http.StatusOK, MessageResponse{Message: "Status unchanged"})
+		return
+	}
+
+	if collaborator.UserID == utils.InferUserID(c) {
+		// alumni rejoin the team through someone managing it
+		if !collaborator.Status.IsCurrent() {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Ask someone managing the project to rejoin its team"})
+			return
+		}
+	} else {
+		if !access.Can(models.CapabilityManageRoles) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Your role doesn't allow changing collaborator statuses"})
+			return
+		}
+		if current, err := services.RoleCapabilities(database.DB, project.ID, models.CollaboratorRole(collaborator.Role)); err == nil && !access.CanAll(current) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can't change the status of a collaborator with capabilities you don't hold"})
+			return
+		}
+	}
+
+	var leftAt *time.Time
+	if !status.IsCurrent() {
+		now := time.Now()
+		leftAt = &now
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		before := collaborator.Status
+		if err := tx.Model(&collaborator).Updates(map[string]interface{}{"status": status, "left_at": leftAt}).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditCollaboratorStatusSet, TargetType: "user", TargetID: collaborator.UserID, ProjectID: auditProject(project.ID),
+			Before: gin.H{"status": before}, After: gin.H{"status": status},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update collaborator status"})
+		return
+	}
+
+	services.PublishProjectEvent(database.DB, project.ID, services.EventCollaboratorStatusChanged,
+		services.CollaboratorEvent{ProjectID: project.ID, UserID: collaborator.UserID, Role: collaborator.Role, Status: string(status)})
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Status updated successfully"})
+}
+
+type TransferProjectRequest struct {
+	UserID uint `json:"user_id" binding:"required" example:"42"`
+}
+
+// TransferProject godoc
+// @Summary      Transfer project ownership
+// @Description  Makes an existing collaborator the owner of the project. The previous owner stays on the project as an editor. Requires the transfer_project capability.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body TransferProjectRequest true "New owner"
+// @Success      200 {object} ProjectRetrievalResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/transfer [post]
+func TransferProject(c *gin.Context) {
+	var request TransferProjectRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityTransferProject, "Your role doesn't allow transferring the project")
+	if !ok {
+		return
+	}
+	if request.UserID == project.OwnerID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "You already own this project"})
+		return
+	}
+
+	var collaborator models.Collaborator
+	if err := database.DB.Where("project_id = ? AND user_id = ?", project.ID, request.UserID).First(&collaborator).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Collaborator not found"})
+		return
+	}
+
+	previousOwnerID := project.OwnerID
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&project).Update("owner_id", collaborator.UserID).Error; err != nil {
+			return err
+		}
+		// alumni taking over the project rejoin its team
+		if err := tx.Model(&collaborator).Updates(map[string]interface{}{
+			"role": models.CollaboratorRoleOwner, "status": models.CollaboratorStatusActive, "left_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		// the previous owner keeps working on the project
+		var previous models.Collaborator
+		result := tx.Where("project_id = ? AND user_id = ?", project.ID, previousOwnerID).Limit(1).Find(&previous)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			previous = models.Collaborator{ProjectID: project.ID, UserID: previousOwnerID}
+		}
+		previous.Role = string(models.CollaboratorRoleEditor)
+		if err := tx.Save(&previous).Error; err != nil {
+			return err
+		}
+
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectTransferred, TargetType: "project", TargetID: project.ID, ProjectID: auditProject(project.ID),
+			Before: gin.H{"owner_id": previousOwnerID}, After: gin.H{"owner_id": collaborator.UserID},
+		})
+		if err != nil {
+			return err
+		}
+		notification, err = services.NotifyRoleChanged(tx, project, collaborator.UserID, previousOwnerID, models.CollaboratorRoleOwner)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to transfer project"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	services.PublishProjectEvent(database.DB, project.ID, services.EventCollaboratorRoleChanged,
+		services.CollaboratorEvent{ProjectID: project.ID, UserID: collaborator.UserID, Role: string(models.CollaboratorRoleOwner)})
+	services.PublishProjectEvent(database.DB, project.ID, services.EventCollaboratorRoleChanged,
+		services.CollaboratorEvent{ProjectID: project.ID, UserID: previousOwnerID, Role: string(models.CollaboratorRoleEditor)})
+
+	c.JSON(http.StatusOK, ProjectRetrievalResponse{
+		ID:             project.ID,
+		Title:          project.Title,
+		Description:    project.Description,
+		RequiredSkills: project.GetRequiredSkills(),
+		Visibility:     project.Visibility,
+		Status:         project.Status,
+		OwnerID:        collaborator.UserID,
+		GroupID:        project.GroupID,
+	})
+}
+
+type ProjectGroupRequest struct {
+	// group to move the project into, null to take it out of its group
+	GroupID *uint `json:"group_id" example:"1"`
+}
+
+// SetProjectGroup godoc
+// @Summary      Move a project into a group
+// @Description  Puts a project under a research group, or takes it out of its group. Group PIs and managers share the owner's permissions on group projects and group members collaborate on them with the group's default role. Requires the transfer_project capability on the project, and to be a PI or manager of the group it moves into.
+// @Tags         Projects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body ProjectGroupRequest true "Group"
+// @Success      200 {object} ProjectRetrievalResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/group [put]
+func SetProjectGroup(c *gin.Context) {
+	var request ProjectGroupRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityTransferProject, "Your role doesn't allow changing the project's group")
+	if !ok {
+		return
+	}
+	if request.GroupID != nil {
+		var group models.Group
+		if err := database.DB.First(&group, *request.GroupID).Error; err != nil {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+			return
+		}
+		if !services.GroupRoleOf(database.DB, group.ID, utils.InferUserID(c)).AtLeast(models.GroupRoleManager) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Only group PIs and managers can add projects to the group"})
+			return
+		}
+	}
+
+	previousGroupID := project.GroupID
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&project).Update("group_id", request.GroupID).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectGroupChanged, TargetType: "project", TargetID: project.ID, ProjectID: auditProject(project.ID),
+			Before: gin.H{"group_id": previousGroupID}, After: gin.H{"group_id": request.GroupID},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to change the project's group"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ProjectRetrievalResponse{
+		ID:             project.ID,
+		Title:          project.Title,
+		Description:    project.Description,
+		RequiredSkills: project.GetRequiredSkills(),
+		Visibility:     project.Visibility,
+		Status:         project.Status,
+		OwnerID:        project.OwnerID,
+		GroupID:        request.GroupID,
+	})
+}
+
+// DeleteProject godoc
+// @Summary      Delete a project
+// @Description  Deletes a project together with its pending invitations and webhooks. Requires the delete_project capability.
+// @Tags         Projects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id} [delete]
+func DeleteProject(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityDeleteProject, "Your role doesn't allow deleting the project")
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := removeProject(tx, project); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectDeleted, TargetType: "project", TargetID: project.ID, ProjectID: auditProject(project.ID),
+			Before: gin.H{"title": project.Title, "owner_id": project.OwnerID},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete project"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Project deleted successfully"})
+}
+
+// removeProject deletes a project along with its pending invitations and webhooks
+func removeProject(tx *gorm.DB, project models.Project) error {
+	if err := tx.Where("project_id = ? AND status = ?", project.ID, models.InvitationStatusPending).Delete(&models.Invitation{}).Error; err != nil {
+		return err
+	}
+	// stop queued deliveries along with the hooks themselves
+	if err := tx.Where("webhook_id IN (?) AND status = ?", tx.Model(&models.Webhook{}).Select("id").Where("project_id = ?", project.ID), models.WebhookDeliveryPending).
+		Delete(&models.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("project_id = ?", project.ID).Delete(&models.Webhook{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&project).Error
+}
+
 // ListUserProjects godoc
 // @Summary      List projects the authenticated user is involved in
-// @Description  Retrieves a list of all projects where the user is either the owner or a collaborator
+// @Description  Retrieves a list of all projects where the user is either the owner, a collaborator or a member of the owning group
 // @Tags         Projects
 // @Accept       json
 // @Produce      json
@@ -473,11 +1104,13 @@ func ListUserProjects(c *gin.Context) {
 		return
 	}
 
-	// Find projects where user is owner or collaborator
+	// Find projects where user is owner, collaborator or member of the owning group
 	var projects []models.Project
+	// filtering on subqueries rather than joining collaborators lists each project once, however many members it has
+	collaborations := database.DB.Model(&models.Collaborator{}).Select("project_id").Where("user_id = ?", userID)
 	err := database.DB.
-		Joins("LEFT JOIN collaborators ON projects.id = collaborators.project_id").
-		Where("projects.owner_id = ? OR collaborators.user_id = ?", userID, userID).
+		Where("projects.owner_id = ? OR projects.id IN (?) OR projects.id IN (?)", userID, collaborations, services.GroupProjectIDs(database.DB, userID)).
+		Where("projects.hidden_at IS NULL").
 		Preload("Collaborators").
 		Find(&projects).Error
 
@@ -497,8 +1130,97 @@ func ListUserProjects(c *gin.Context) {
 			Visibility:     project.Visibility,
 			Status:         project.Status,
 			OwnerID:        project.OwnerID,
+			GroupID:        project.GroupID,
 		}
 	}
 
 	c.JSON(http.StatusOK, ProjectListResponse{Projects: response})
 }
+
+// projectAccess returns the role a user holds on a project and the capabilities it grants them
+func projectAccess(project models.Project, userID uint) services.ProjectAccess {
+	return services.ProjectAccessOf(database.DB, project, userID)
+}
+
+// projectMemberRole returns the role a user holds on a project, or an empty role if they don't collaborate on it.
+// Members of the group owning the project hold at least the role they inherit from the group.
+func projectMemberRole(project models.Project, userID uint) models.CollaboratorRole {
+	return projectAccess(project, userID).Role
+}
+
+// loadProjectMembership loads the project from the "id" URL parameter together with the
+// authenticated user's access to it. On failure the error response is written and ok is false.
+func loadProjectMembership(c *gin.Context) (project models.Project, access services.ProjectAccess, ok bool) {
+	userID := utils.InferUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "Authentication required"})
+		return project, access, false
+	}
+
+	if err := database.DB.First(&project, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+		return project, access, false
+	}
+
+	// projects hidden by moderators are gone for everyone but their members
+	access = projectAccess(project, userID)
+	if project.HiddenAt != nil && !access.IsMember() {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Project not found"})
+		return project, access, false
+	}
+	return project, access, true
+}
+
+// requireProjectReadAccess verifies that the authenticated user may read the content of the project
+// from the "id" URL parameter: anyone may read public projects, only collaborators may read private ones.
+// On failure the error response is written and ok is false.
+func requireProjectReadAccess(c *gin.Context, forbiddenMessage string) (project models.Project, access services.ProjectAccess, ok bool) {
+	project, access, ok = loadProjectMembership(c)
+	if !ok {
+		return project, access, false
+	}
+
+	if !access.Can(models.CapabilityView) && project.Visibility != models.ProjectVisibilityPublic {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: forbiddenMessage})
+		return project, access, false
+	}
+	return project, access, true
+}
+
+// checkGrantableRole verifies that the authenticated user, with the given access, may give a collaborator the role.
+// Ownership is only passed on by transferring the project. On failure the error response is written and ok is false.
+func checkGrantableRole(c *gin.Context, db *gorm.DB, project models.Project, access services.ProjectAccess, role models.CollaboratorRole) bool {
+	if role == models.CollaboratorRoleOwner {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Ownership can only be transferred"})
+		return false
+	}
+	err := services.CheckCanGrantRole(db, project.ID, access, role)
+	if errors.Is(err, services.ErrUnknownRole) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown role"})
+		return false
+	}
+	if errors.Is(err, services.ErrRoleEscalation) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can't grant a role with capabilities you don't hold"})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to look up the role"})
+		return false
+	}
+	return true
+}
+
+// requireProjectCapability verifies that the authenticated user collaborates on the project from the
+// "id" URL parameter with a role granting the capability. On failure the error response is written and ok is false.
+func requireProjectCapability(c *gin.Context, capability models.Capability, forbiddenMessage string) (project models.Project, access services.ProjectAccess, ok bool) {
+	project, access, ok = loadProjectMembership(c)
+	if !ok {
+		return project, access, false
+	}
+
+	if !access.Can(capability) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: forbiddenMessage})
+		return project, access, false
+	}
+	return project, access, true
+}
@@ -29,14 +29,26 @@ func setupProjectsTest(t *testing.T) {
 	}
 
 	// Run migrations
-	database.DB.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Project{}, &models.Collaborator{}, &models.Invitation{})
+	database.DB.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Project{}, &models.Collaborator{}, &models.Invitation{}, &models.Notification{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.AuditEvent{}, &models.UserBlock{},
+		&models.Institution{}, &models.InstitutionDomain{}, &models.Department{}, &models.Group{}, &models.GroupMember{}, &models.ProjectRole{})
 
 	// Clean up existing data - Note the order matters due to foreign key constraints
+	database.DB.Exec("DELETE FROM audit_events")
+	database.DB.Exec("DELETE FROM user_blocks")
+	database.DB.Exec("DELETE FROM webhook_deliveries")
+	database.DB.Exec("DELETE FROM webhooks")
+	database.DB.Exec("DELETE FROM notifications")
 	database.DB.Exec("DELETE FROM invitations")
 	database.DB.Exec("DELETE FROM collaborators")
+	database.DB.Exec("DELETE FROM project_roles")
 	database.DB.Exec("DELETE FROM projects")
 	database.DB.Exec("DELETE FROM user_profiles")
 	database.DB.Exec("DELETE FROM users")
+	database.DB.Exec("DELETE FROM group_members")
+	database.DB.Exec("DELETE FROM groups")
+	database.DB.Exec("DELETE FROM departments")
+	database.DB.Exec("DELETE FROM institution_domains")
+	database.DB.Exec("DELETE FROM institutions")
 
 	// Reset auto-increment counters
 	database.DB.Exec("UPDATE sqlite_sequence SET seq = 0 WHERE name IN ('users', 'projects', 'collaborators', 'user_profiles', 'invitations')")
@@ -500,3 +512,175 @@ func TestListUserProjects(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 }
+
+func TestListUserProjectsListsEachProjectOnce(t *testing.T) {
+	setupProjectsTest(t)
+
+	owner := models.User{Email: "listed_owner@example.com", Password: "password"}
+	first := models.User{Email: "listed_first@example.com", Password: "password"}
+	second := models.User{Email: "listed_second@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&first)
+	database.DB.Create(&second)
+	group := models.Group{Name: "Listing Lab"}
+	database.DB.Create(&group)
+	database.DB.Create(&models.GroupMember{GroupID: group.ID, UserID: owner.ID, Role: models.GroupRolePI})
+	database.DB.Create(&models.GroupMember{GroupID: group.ID, UserID: first.ID, Role: models.GroupRoleMember})
+	project := models.Project{Title: "Group Project", OwnerID: owner.ID, GroupID: &group.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: first.ID, Role: "programmer"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: second.ID, Role: "programmer"})
+
+	router := gin.Default()
+	router.GET("/projects/user", middleware.AuthRequired(), controllers.ListUserProjects)
+
+	// owning the project, collaborating on it and belonging to its group all list it just once
+	for _, user := range []models.User{owner, first, second} {
+		token, _ := utils.GenerateJWT(user.ID, user.Email)
+		w := projectRequest(router, "GET", "/projects/user", token, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response controllers.ProjectListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Projects, 1, user.Email) {
+			assert.Equal(t, project.ID, response.Projects[0].ID)
+		}
+	}
+}
+
+func TestTransferAndDeleteProject(t *testing.T) {
+	setupProjectsTest(t)
+
+	owner := models.User{Email: "transfer_owner@example.com", Password: "password"}
+	member := models.User{Email: "transfer_member@example.com", Password: "password"}
+	outsider := models.User{Email: "transfer_outsider@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&member)
+	database.DB.Create(&outsider)
+	project := models.Project{Title: "Handover", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: owner.ID, Role: "owner"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: member.ID, Role: "programmer"})
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	memberToken, _ := utils.GenerateJWT(member.ID, member.Email)
+
+	router := gin.Default()
+	router.POST("/projects/:id/transfer", middleware.AuthRequired(), controllers.TransferProject)
+	router.DELETE("/projects/:id", middleware.AuthRequired(), controllers.DeleteProject)
+	path := fmt.Sprintf("/projects/%d", project.ID)
+
+	// only collaborators can take over a project
+	w := projectRequest(router, "POST", path+"/transfer", ownerToken, map[string]uint{"user_id": outsider.ID})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(router, "POST", path+"/transfer", memberToken, map[string]uint{"user_id": member.ID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(router, "POST", path+"/transfer", ownerToken, map[string]uint{"user_id": member.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var transferred models.Project
+	database.DB.First(&transferred, project.ID)
+	assert.Equal(t, member.ID, transferred.OwnerID)
+	var previous models.Collaborator
+	database.DB.Where("project_id = ? AND user_id = ?", project.ID, owner.ID).First(&previous)
+	assert.Equal(t, "editor", previous.Role)
+
+	// the previous owner can no longer delete it
+	w = projectRequest(router, "DELETE", path, ownerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "DELETE", path, memberToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Error(t, database.DB.First(&models.Project{}, project.ID).Error)
+}
+
+func TestCollaboratorStatus(t *testing.T) {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+
+	tokens := make(map[string]string)
+	users := make(map[string]models.User)
+	for _, name := range []string{"owner", "editor", "student", "visitor", "outsider"} {
+		user := models.User{Email: "status_" + name + "@example.com", Password: "password"}
+		database.DB.Create(&user)
+		database.DB.Create(&models.UserProfile{UserID: user.ID, FullName: name})
+		users[name] = user
+		tokens[name], _ = utils.GenerateJWT(user.ID, user.Email)
+	}
+	project := models.Project{Title: "Lifecycle", OwnerID: users["owner"].ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: users["owner"].ID, Role: "owner"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: users["editor"].ID, Role: "editor"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: users["student"].ID, Role: "programmer"})
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: users["visitor"].ID, Role: "programmer"})
+
+	router := gin.Default()
+	router.GET("/projects/:id/collaborators", middleware.AuthRequired(), controllers.ListCollaborators)
+	router.PUT("/projects/:id/collaborators/:userId/status", middleware.AuthRequired(), controllers.UpdateCollaboratorStatus)
+	router.POST("/projects/:id/transfer", middleware.AuthRequired(), controllers.TransferProject)
+	router.GET("/projects/:id/tasks", middleware.AuthRequired(), controllers.ListTasks)
+	router.POST("/projects/:id/tasks", middleware.AuthRequired(), controllers.CreateTask)
+	path := fmt.Sprintf("/projects/%d", project.ID)
+	statusPath := func(name string) string { return fmt.Sprintf("%s/collaborators/%d/status", path, users[name].ID) }
+
+	var team controllers.CollaboratorListResponse
+	w := projectRequest(router, "GET", path+"/collaborators", tokens["student"], nil)
+	json.Unmarshal(w.Body.Bytes(), &team)
+	assert.Len(t, team.Current, 4)
+	assert.Empty(t, team.Past)
+	assert.NotNil(t, team.Current[0].JoinedAt)
+	w = projectRequest(router, "GET", path+"/collaborators", tokens["outsider"], nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// only those managing the team change others' status, and the owner stays active
+	w = projectRequest(router, "PUT", statusPath("student"), tokens["editor"], map[string]string{"status": "alumni"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", statusPath("owner"), tokens["owner"], map[string]string{"status": "alumni"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "PUT", statusPath("student"), tokens["owner"], map[string]string{"status": "retired"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// on leave collaborators keep their access
+	w = projectRequest(router, "PUT", statusPath("student"), tokens["student"], map[string]string{"status": "on-leave"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "POST", path+"/tasks", tokens["student"], map[string]interface{}{"title": "Write up"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// alumni keep the credit but can only read
+	w = projectRequest(router, "PUT", statusPath("student"), tokens["owner"], map[string]string{"status": "alumni"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "GET", path+"/tasks", tokens["student"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "POST", path+"/tasks", tokens["student"], map[string]interface{}{"title": "One more thing"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", path+"/tasks", tokens["editor"], map[string]interface{}{"title": "Follow up", "assignee_id": users["student"].ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	team = controllers.CollaboratorListResponse{}
+	w = projectRequest(router, "GET", path+"/collaborators", tokens["student"], nil)
+	json.Unmarshal(w.Body.Bytes(), &team)
+	assert.Len(t, team.Current, 3)
+	if assert.Len(t, team.Past, 1) {
+		assert.Equal(t, users["student"].ID, team.Past[0].UserID)
+		assert.Equal(t, models.CollaboratorStatusAlumni, team.Past[0].Status)
+		assert.NotNil(t, team.Past[0].LeftAt)
+	}
+
+	// alumni can't rejoin on their own, collaborators can leave on their own
+	w = projectRequest(router, "PUT", statusPath("student"), tokens["student"], map[string]string{"status": "active"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", statusPath("visitor"), tokens["visitor"], map[string]string{"status": "alumni"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "PUT", statusPath("student"), tokens["owner"], map[string]string{"status": "active"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rejoined models.Collaborator
+	database.DB.Where("project_id = ? AND user_id = ?", project.ID, users["student"].ID).First(&rejoined)
+	assert.Equal(t, models.CollaboratorStatusActive, rejoined.Status)
+	assert.Nil(t, rejoined.LeftAt)
+
+	// alumni taking over the project rejoin the team
+	w = projectRequest(router, "POST", path+"/transfer", tokens["owner"], map[string]uint{"user_id": users["visitor"].ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var owner models.Collaborator
+	database.DB.Where("project_id = ? AND user_id = ?", project.ID, users["visitor"].ID).First(&owner)
+	assert.Equal(t, models.CollaboratorStatusActive, owner.Status)
+}
@@ -0,0 +1,406 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errReportAlreadyResolved = errors.New("report already resolved")
+
+type ReportCreationRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=project profile comment" example:"comment"`
+	TargetID   uint   `json:"target_id" binding:"required" example:"12"`
+	Reason     string `json:"reason" binding:"required,oneof=spam harassment inappropriate impersonation other" example:"spam"`
+	Details    string `json:"details" binding:"max=2000" example:"Advertises an unrelated product"`
+}
+
+type ReportResolutionRequest struct {
+	Action string `json:"action" binding:"required,oneof=dismiss hide_content suspend_author" example:"hide_content"`
+	Note   string `json:"note" binding:"max=2000"`
+}
+
+type ReportDetail struct {
+	ID             uint       `json:"id"`
+	TargetType     string     `json:"target_type" example:"comment"`
+	TargetID       uint       `json:"target_id"`
+	Reason         string     `json:"reason" example:"spam"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status" example:"open"`
+	Resolution     string     `json:"resolution" example:"hide_content"`
+	ResolutionNote string     `json:"resolution_note"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type ReportListResponse struct {
+	Reports []ReportDetail `json:"reports"`
+}
+
+type ModerationQueueEntry struct {
+	ReportDetail
+	ReporterID    uint   `json:"reporter_id"`
+	ReporterEmail string `json:"reporter_email"`
+	AuthorID      uint   `json:"author_id"`
+	// description of the reported content, e.g. a project title or the beginning of a comment
+	TargetSummary string `json:"target_summary"`
+	// number of open reports on the same content, including this one
+	OpenReports int64 `json:"open_reports"`
+}
+
+type ModerationQueueResponse struct {
+	Reports  []ModerationQueueEntry `json:"reports"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+	Total    int64                  `json:"total"`
+}
+
+func toReportDetail(report models.Report) ReportDetail {
+	return ReportDetail{
+		ID:             report.ID,
+		TargetType:     string(report.TargetType),
+		TargetID:       report.TargetID,
+		Reason:         string(report.Reason),
+		Details:        report.Details,
+		Status:         string(report.Status),
+		Resolution:     string(report.Resolution),
+		ResolutionNote: report.ResolutionNote,
+		ResolvedAt:     report.ResolvedAt,
+		CreatedAt:      report.CreatedAt,
+	}
+}
+
+// loadReportTarget returns the author of reported content and a short description of it
+func loadReportTarget(db *gorm.DB, targetType models.ReportTargetType, targetID uint) (authorID uint, summary string, err error) {
+	switch targetType {
+	case models.ReportTargetProject:
+		var project models.Project
+		if err := db.First(&project, targetID).Error; err != nil {
+			return 0, "", err
+		}
+		return project.OwnerID, fmt.Sprintf("the project %q", project.Title), nil
+	case models.ReportTargetProfile:
+		var user models.User
+		if err := db.Preload("Profile").First(&user, targetID).Error; err != nil {
+			return 0, "", err
+		}
+		// the summary is sent to the reporter, who mustn't learn the reported user's email address
+		if user.Profile.FullName == "" {
+			return user.ID, fmt.Sprintf("the profile of user #%d", user.ID), nil
+		}
+		return user.ID, fmt.Sprintf("the profile of %s", user.Profile.FullName), nil
+	default:
+		var comment models.Comment
+		if err := db.First(&comment, targetID).Error; err != nil {
+			return 0, "", err
+		}
+		excerpt := []rune(comment.Body)
+		if len(excerpt) > 60 {
+			excerpt = append(excerpt[:60], '…')
+		}
+		return comment.AuthorID, fmt.Sprintf("the comment %q", string(excerpt)), nil
+	}
+}
+
+// hideReportTarget removes reported content from view
+func hideReportTarget(tx *gorm.DB, report models.Report, now time.Time) error {
+	switch report.TargetType {
+	case models.ReportTargetProject:
+		return tx.Model(&models.Project{}).Where("id = ?", report.TargetID).Update("hidden_at", now).Error
+	case models.ReportTargetProfile:
+		return tx.Model(&models.UserProfile{}).Where("user_id = ?", report.TargetID).Update("hidden_at", now).Error
+	default:
+		return tx.Model(&models.Comment{}).Where("id = ?", report.TargetID).Update("hidden_at", now).Error
+	}
+}
+
+// CreateReport godoc
+// @Summary      Report content
+// @Description  Flags a project, profile or comment for review by administrators. Profiles are reported by user ID. You will be notified once the report has been reviewed.
+// @Tags         Reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ReportCreationRequest true "Reported content and reason"
+// @Success      201 {object} ReportDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /reports [post]
+func CreateReport(c *gin.Context) {
+	var request ReportCreationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	userID := utils.InferUserID(c)
+
+	targetType := models.ReportTargetType(request.TargetType)
+	authorID, _, err := loadReportTarget(database.DB, targetType, request.TargetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Reported content not found"})
+		return
+	}
+	if authorID == userID {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "You cannot report your own content"})
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?", userID, targetType, request.TargetID, models.ReportStatusOpen).
+		Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "You already reported this content"})
+		return
+	}
+
+	report := models.Report{
+		ReporterID: userID,
+		TargetType: targetType,
+		TargetID:   request.TargetID,
+		AuthorID:   authorID,
+		Reason:     models.ReportReason(request.Reason),
+		Details:    request.Details,
+		Status:     models.ReportStatusOpen,
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditReportCreated, TargetType: "report", TargetID: report.ID,
+			After: gin.H{"target_type": report.TargetType, "target_id": report.TargetID, "reason": report.Reason},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create report"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, toReportDetail(report))
+}
+
+// ListMyReports godoc
+// @Summary      List my reports
+// @Description  Lists the reports filed by the authenticated user together with their outcome, newest first.
+// @Tags         Reports
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} ReportListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /reports [get]
+func ListMyReports(c *gin.Context) {
+	var reports []models.Report
+	if err := database.DB.Where("reporter_id = ?", utils.InferUserID(c)).Order("id DESC").Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch reports"})
+		return
+	}
+
+	response := ReportListResponse{Reports: make([]ReportDetail, len(reports))}
+	for i, report := range reports {
+		response.Reports[i] = toReportDetail(report)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListModerationQueue godoc
+// @Summary      Get the moderation queue
+// @Description  Lists reports for administrators to review, oldest first. Defaults to open reports. Only administrators can view the queue.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status query string false "Report status" Enums(open, resolved) default(open)
+// @Param        target_type query string false "Only reports on this kind of content" Enums(project, profile, comment)
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Reports per page (max 100)" default(20)
+// @Success      200 {object} ModerationQueueResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/reports [get]
+func ListModerationQueue(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.Report{}).Where("status = ?", c.DefaultQuery("status", string(models.ReportStatusOpen)))
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch reports"})
+		return
+	}
+
+	var reports []models.Report
+	if err := query.Preload("Reporter").Order("id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch reports"})
+		return
+	}
+
+	response := ModerationQueueResponse{Reports: make([]ModerationQueueEntry, len(reports)), Page: page, PageSize: pageSize, Total: total}
+	for i, report := range reports {
+		entry := ModerationQueueEntry{
+			ReportDetail:  toReportDetail(report),
+			ReporterID:    report.ReporterID,
+			ReporterEmail: report.Reporter.Email,
+			AuthorID:      report.AuthorID,
+			TargetSummary: "deleted content",
+		}
+		if _, summary, err := loadReportTarget(database.DB, report.TargetType, report.TargetID); err == nil {
+			entry.TargetSummary = summary
+		}
+		database.DB.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportStatusOpen).
+			Count(&entry.OpenReports)
+		response.Reports[i] = entry
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveReport godoc
+// @Summary      Resolve a report
+// @Description  Dismisses a report, hides the reported content or suspends its author. All open reports on the same content are resolved with the same outcome and every reporter is notified. Only administrators can resolve reports.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Report ID"
+// @Param        request body ReportResolutionRequest true "Moderation action"
+// @Success      200 {object} ReportDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /admin/reports/{id}/resolve [post]
+func ResolveReport(c *gin.Context) {
+	var request ReportResolutionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	resolution := models.ReportResolution(request.Action)
+	adminID := utils.InferUserID(c)
+
+	var report models.Report
+	if err := database.DB.First(&report, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Report not found"})
+		return
+	}
+	if report.Status != models.ReportStatusOpen {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "This report has already been resolved"})
+		return
+	}
+	if resolution == models.ReportResolutionAuthorSuspended {
+		var author models.User
+		if err := database.DB.First(&author, report.AuthorID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "The author's account no longer exists"})
+			return
+		}
+		if author.IsAdmin || author.ID == adminID {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Administrators cannot be suspended through reports"})
+			return
+		}
+	}
+
+	_, summary, err := loadReportTarget(database.DB, report.TargetType, report.TargetID)
+	if err != nil {
+		summary = "content that has since been deleted"
+	}
+
+	now := time.Now()
+	var notifications []*models.Notification
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var reports []models.Report
+		if err := tx.Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportStatusOpen).Find(&reports).Error; err != nil {
+			return err
+		}
+		if len(reports) == 0 {
+			return errReportAlreadyResolved
+		}
+
+		switch resolution {
+		case models.ReportResolutionContentHidden:
+			if err := hideReportTarget(tx, report, now); err != nil {
+				return err
+			}
+			err := recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditContentHidden, TargetType: string(report.TargetType), TargetID: report.TargetID,
+				After: gin.H{"hidden": true, "report_id": report.ID},
+			})
+			if err != nil {
+				return err
+			}
+		case models.ReportResolutionAuthorSuspended:
+			if err := tx.Model(&models.User{}).Where("id = ? AND disabled_at IS NULL", report.AuthorID).Update("disabled_at", now).Error; err != nil {
+				return err
+			}
+			err := recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditUserDisabled, TargetType: "user", TargetID: report.AuthorID,
+				Before: gin.H{"disabled": false}, After: gin.H{"disabled": true, "report_id": report.ID},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, open := range reports {
+			open.Status = models.ReportStatusResolved
+			open.Resolution = resolution
+			open.ResolutionNote = request.Note
+			open.ResolvedByID = &adminID
+			open.ResolvedAt = &now
+			if err := tx.Save(&open).Error; err != nil {
+				return err
+			}
+			err := recordAudit(c, tx, services.AuditEntry{
+				Action: models.AuditReportResolved, TargetType: "report", TargetID: open.ID,
+				Before: gin.H{"status": models.ReportStatusOpen}, After: gin.H{"status": open.Status, "resolution": open.Resolution},
+			})
+			if err != nil {
+				return err
+			}
+			notification, err := services.NotifyReportResolved(tx, open, summary)
+			if err != nil {
+				return err
+			}
+			notifications = append(notifications, notification)
+			if open.ID == report.ID {
+				report = open
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errReportAlreadyResolved) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "This report has already been resolved"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to resolve report"})
+		return
+	}
+	services.PublishNotifications(notifications...)
+
+	c.JSON(http.StatusOK, toReportDetail(report))
+}
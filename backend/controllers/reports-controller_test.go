@@ -0,0 +1,194 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type reportsFixture struct {
+	router        *gin.Engine
+	project       models.Project
+	author        models.User
+	authorToken   string
+	reporterToken string
+	otherToken    string
+	adminToken    string
+}
+
+func setupReportsTest(t *testing.T) reportsFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Report{}, &models.DiscussionThread{}, &models.Comment{}, &models.CommentMention{}, &models.CommentEdit{})
+	database.DB.Exec("DELETE FROM reports")
+	database.DB.Exec("DELETE FROM comments")
+	database.DB.Exec("DELETE FROM discussion_threads")
+
+	author := models.User{Email: "reports_author@example.com", Password: "password"}
+	reporter := models.User{Email: "reports_reporter@example.com", Password: "password"}
+	other := models.User{Email: "reports_other@example.com", Password: "password"}
+	admin := models.User{Email: "reports_admin@example.com", Password: "password", IsAdmin: true}
+	for _, user := range []*models.User{&author, &reporter, &other, &admin} {
+		database.DB.Create(user)
+		database.DB.Create(&models.UserProfile{UserID: user.ID, FullName: "Full Name"})
+	}
+	project := models.Project{Title: "Buy cheap watches", OwnerID: author.ID, Visibility: "public", Status: "open"}
+	database.DB.Create(&project)
+
+	tokens := make([]string, 4)
+	for i, user := range []models.User{author, reporter, other, admin} {
+		tokens[i], _ = utils.GenerateJWT(user.ID, user.Email)
+	}
+
+	router := gin.Default()
+	router.GET("/projects", controllers.ListProjects)
+	router.GET("/projects/:id", controllers.RetrieveProject)
+	router.GET("/projects/user", middleware.AuthRequired(), controllers.ListUserProjects)
+	router.GET("/projects/:id/discussions", middleware.AuthRequired(), controllers.ListDiscussionThreads)
+	router.GET("/users/:id/profile", controllers.RetrieveUserProfile)
+	router.GET("/notifications", middleware.AuthRequired(), controllers.ListNotifications)
+	router.GET("/reports", middleware.AuthRequired(), controllers.ListMyReports)
+	router.POST("/reports", middleware.AuthRequired(), controllers.CreateReport)
+	router.GET("/admin/reports", middleware.AuthRequired(), middleware.AdminOnly(), controllers.ListModerationQueue)
+	router.POST("/admin/reports/:id/resolve", middleware.AuthRequired(), middleware.AdminOnly(), controllers.ResolveReport)
+
+	return reportsFixture{router, project, author, tokens[0], tokens[1], tokens[2], tokens[3]}
+}
+
+func createTestReport(t *testing.T, f reportsFixture, token, targetType string, targetID uint) controllers.ReportDetail {
+	w := projectRequest(f.router, "POST", "/reports", token, map[string]interface{}{"target_type": targetType, "target_id": targetID, "reason": "spam"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var report controllers.ReportDetail
+	json.Unmarshal(w.Body.Bytes(), &report)
+	return report
+}
+
+func TestCreateReport(t *testing.T) {
+	f := setupReportsTest(t)
+
+	w := projectRequest(f.router, "POST", "/reports", f.reporterToken, map[string]interface{}{"target_type": "project", "target_id": f.project.ID, "reason": "boring"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(f.router, "POST", "/reports", f.reporterToken, map[string]interface{}{"target_type": "comment", "target_id": 9999, "reason": "spam"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(f.router, "POST", "/reports", f.authorToken, map[string]interface{}{"target_type": "project", "target_id": f.project.ID, "reason": "spam"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	report := createTestReport(t, f, f.reporterToken, "project", f.project.ID)
+	assert.Equal(t, "open", report.Status)
+
+	// one open report per reporter and content
+	w = projectRequest(f.router, "POST", "/reports", f.reporterToken, map[string]interface{}{"target_type": "project", "target_id": f.project.ID, "reason": "other"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = projectRequest(f.router, "GET", "/admin/reports", f.reporterToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHideReportedProject(t *testing.T) {
+	f := setupReportsTest(t)
+	report := createTestReport(t, f, f.reporterToken, "project", f.project.ID)
+	createTestReport(t, f, f.otherToken, "project", f.project.ID)
+
+	w := projectRequest(f.router, "GET", "/admin/reports", f.adminToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var queue controllers.ModerationQueueResponse
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Equal(t, int64(2), queue.Total)
+	assert.Equal(t, int64(2), queue.Reports[0].OpenReports)
+	assert.Equal(t, `the project "Buy cheap watches"`, queue.Reports[0].TargetSummary)
+	assert.Equal(t, "reports_reporter@example.com", queue.Reports[0].ReporterEmail)
+
+	resolvePath := fmt.Sprintf("/admin/reports/%d/resolve", report.ID)
+	w = projectRequest(f.router, "POST", resolvePath, f.adminToken, map[string]string{"action": "hide_content", "note": "Spam"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "POST", resolvePath, f.adminToken, map[string]string{"action": "dismiss"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// both reports are resolved and both reporters notified
+	w = projectRequest(f.router, "GET", "/admin/reports", f.adminToken, nil)
+	json.Unmarshal(w.Body.Bytes(), &queue)
+	assert.Equal(t, int64(0), queue.Total)
+	var notified int64
+	database.DB.Model(&models.Notification{}).Where("type = ?", models.NotificationReportResolved).Count(&notified)
+	assert.Equal(t, int64(2), notified)
+
+	w = projectRequest(f.router, "GET", "/reports", f.reporterToken, nil)
+	var mine controllers.ReportListResponse
+	json.Unmarshal(w.Body.Bytes(), &mine)
+	assert.Equal(t, "hide_content", mine.Reports[0].Resolution)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d", f.project.ID), "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(f.router, "GET", "/projects", "", nil)
+	assert.NotContains(t, w.Body.String(), "Buy cheap watches")
+	w = projectRequest(f.router, "GET", "/projects/user", f.authorToken, nil)
+	assert.NotContains(t, w.Body.String(), "Buy cheap watches")
+
+	// only members still reach the hidden project's content
+	discussionsPath := fmt.Sprintf("/projects/%d/discussions", f.project.ID)
+	w = projectRequest(f.router, "GET", discussionsPath, f.reporterToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = projectRequest(f.router, "GET", discussionsPath, f.authorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHideReportedProfileAndComment(t *testing.T) {
+	f := setupReportsTest(t)
+	thread := models.DiscussionThread{ProjectID: f.project.ID, AuthorID: f.author.ID, Title: "Deals"}
+	database.DB.Create(&thread)
+	comment := models.Comment{ThreadID: thread.ID, AuthorID: f.author.ID, Body: "Visit my shop"}
+	database.DB.Create(&comment)
+
+	profileReport := createTestReport(t, f, f.reporterToken, "profile", f.author.ID)
+	commentReport := createTestReport(t, f, f.reporterToken, "comment", comment.ID)
+
+	for _, id := range []uint{profileReport.ID, commentReport.ID} {
+		w := projectRequest(f.router, "POST", fmt.Sprintf("/admin/reports/%d/resolve", id), f.adminToken, map[string]string{"action": "hide_content"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := projectRequest(f.router, "GET", fmt.Sprintf("/users/%d/profile", f.author.ID), "", nil)
+	var profile controllers.ProfileRetrievalResponse
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.True(t, profile.Hidden)
+	assert.Empty(t, profile.FullName)
+
+	var hidden models.Comment
+	database.DB.First(&hidden, comment.ID)
+	assert.NotNil(t, hidden.HiddenAt)
+
+	// the reporter is told whose profile was reviewed without learning their email address
+	var resolved models.Notification
+	database.DB.Joins("JOIN users ON users.id = notifications.user_id").
+		Where("users.email = ? AND notifications.type = ?", "reports_reporter@example.com", models.NotificationReportResolved).
+		Order("notifications.id").First(&resolved)
+	assert.Contains(t, resolved.Message, "the profile of Full Name")
+	assert.NotContains(t, resolved.Message, "reports_author@example.com")
+}
+
+func TestSuspendReportedAuthor(t *testing.T) {
+	f := setupReportsTest(t)
+	report := createTestReport(t, f, f.reporterToken, "project", f.project.ID)
+
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/admin/reports/%d/resolve", report.ID), f.adminToken, map[string]string{"action": "suspend_author"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", "/notifications", f.authorToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// dismissing leaves the content in place
+	report = createTestReport(t, f, f.otherToken, "project", f.project.ID)
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/admin/reports/%d/resolve", report.ID), f.adminToken, map[string]string{"action": "dismiss"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d", f.project.ID), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// descriptions of the built-in roles, shown next to the project's custom roles
+var builtInRoleDescriptions = map[models.CollaboratorRole]string{
+	models.CollaboratorRoleProgrammer: "Works on tasks, files, the wiki and discussions",
+	models.CollaboratorRoleEditor:     "Also manages milestones and deletes tasks and files",
+	models.CollaboratorRoleOwner:      "Holds every capability, including managing collaborators and deleting the project",
+}
+
+type ProjectRoleRequest struct {
+	Name         string              `json:"name" binding:"required,max=50" example:"reviewer"`
+	Description  string              `json:"description" example:"Reads everything and comments on discussions"`
+	Capabilities []models.Capability `json:"capabilities" example:"view,comment"`
+}
+
+type ProjectRoleDetail struct {
+	ID           uint                `json:"id,omitempty"` // zero for built-in roles
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Capabilities []models.Capability `json:"capabilities"`
+	BuiltIn      bool                `json:"built_in"`
+}
+
+type ProjectRoleListResponse struct {
+	Roles []ProjectRoleDetail `json:"roles"`
+}
+
+type ProjectPermissionsResponse struct {
+	Role         string              `json:"role" example:"editor"` // empty if the user doesn't collaborate on the project
+	Capabilities []models.Capability `json:"capabilities"`
+}
+
+func projectRoleDetail(role models.ProjectRole) ProjectRoleDetail {
+	return ProjectRoleDetail{ID: role.ID, Name: role.Name, Description: role.Description, Capabilities: role.GetCapabilities()}
+}
+
+// bindProjectRole validates a custom role request, writing a 400 response when it is invalid
+func bindProjectRole(c *gin.Context) (ProjectRoleRequest, bool) {
+	var request ProjectRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return request, false
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Name is required"})
+		return request, false
+	}
+	for _, role := range models.BuiltInRoles {
+		if strings.EqualFold(request.Name, string(role)) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Custom roles can't use the name of a built-in role"})
+			return request, false
+		}
+	}
+	for _, capability := range request.Capabilities {
+		if !capability.IsValid() {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown capability: " + string(capability)})
+			return request, false
+		}
+	}
+	request.Capabilities = services.NormalizeCapabilities(request.Capabilities)
+	return request, true
+}
+
+// projectRoleNameTaken reports whether another custom role of the project than id already uses the name
+func projectRoleNameTaken(projectID uint, name string, id uint) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.ProjectRole{}).
+		Where("project_id = ? AND LOWER(name) = ? AND id <> ?", projectID, strings.ToLower(name), id).Count(&count).Error
+	return count > 0, err
+}
+
+// loadProjectRole fetches the custom role of the roleId path parameter, writing a 404 response when the project
+// has no such role
+func loadProjectRole(c *gin.Context, projectID uint) (models.ProjectRole, bool) {
+	var role models.ProjectRole
+	if err := database.DB.Where("project_id = ?", projectID).First(&role, c.Param("roleId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Role not found"})
+		return role, false
+	}
+	return role, true
+}
+
+// ListProjectRoles godoc
+// @Summary      List the roles of a project
+// @Description  Lists the built-in roles and the project's custom roles with the capabilities each one grants. Only collaborators can list roles.
+// @Tags         Project Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} ProjectRoleListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/roles [get]
+func ListProjectRoles(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can list project roles")
+	if !ok {
+		return
+	}
+
+	var custom []models.ProjectRole
+	if err := database.DB.Where("project_id = ?", project.ID).Order("name").Find(&custom).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch roles"})
+		return
+	}
+
+	roles := make([]ProjectRoleDetail, 0, len(models.BuiltInRoles)+len(custom))
+	for _, role := range models.BuiltInRoles {
+		roles = append(roles, ProjectRoleDetail{
+			Name: string(role), Description: builtInRoleDescriptions[role], Capabilities: role.BuiltInCapabilities(), BuiltIn: true,
+		})
+	}
+	for _, role := range custom {
+		roles = append(roles, projectRoleDetail(role))
+	}
+	c.JSON(http.StatusOK, ProjectRoleListResponse{Roles: roles})
+}
+
+// GetProjectPermissions godoc
+// @Summary      Get your permissions on a project
+// @Description  Returns the role the authenticated user holds on a project and the capabilities it grants them, including those inherited from the group owning the project.
+// @Tags         Project Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} ProjectPermissionsResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Router       /projects/{id}/permissions [get]
+func GetProjectPermissions(c *gin.Context) {
+	_, access, ok := loadProjectMembership(c)
+	if !ok {
+		return
+	}
+
+	capabilities := access.Capabilities
+	if capabilities == nil {
+		capabilities = []models.Capability{}
+	}
+	c.JSON(http.StatusOK, ProjectPermissionsResponse{Role: string(access.Role), Capabilities: capabilities})
+}
+
+// CreateProjectRole godoc
+// @Summary      Define a custom role
+// @Description  Defines a role for the project granting the picked capabilities. Every role can view the project. Requires the manage_roles capability, and every capability the role grants.
+// @Tags         Project Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body ProjectRoleRequest true "Role"
+// @Success      201 {object} ProjectRoleDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/roles [post]
+func CreateProjectRole(c *gin.Context) {
+	project, access, ok := requireProjectCapability(c, models.CapabilityManageRoles, "Your role doesn't allow managing roles")
+	if !ok {
+		return
+	}
+	request, ok := bindProjectRole(c)
+	if !ok {
+		return
+	}
+	if !access.CanAll(request.Capabilities) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can't grant capabilities you don't hold"})
+		return
+	}
+
+	taken, err := projectRoleNameTaken(project.ID, request.Name, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save role"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The project already has a role with this name"})
+		return
+	}
+
+	role := models.ProjectRole{ProjectID: project.ID, Name: request.Name, Description: request.Description}
+	role.SetCapabilities(request.Capabilities)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectRoleCreated, TargetType: "project_role", TargetID: role.ID,
+			ProjectID: auditProject(project.ID), After: request,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, projectRoleDetail(role))
+}
+
+// UpdateProjectRole godoc
+// @Summary      Update a custom role
+// @Description  Renames a custom role or changes the capabilities it grants. Collaborators and pending invitations holding the role keep it. Requires the manage_roles capability, and every capability the role grants before and after the change.
+// @Tags         Project Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        roleId path int true "Role ID"
+// @Param        request body ProjectRoleRequest true "Role"
+// @Success      200 {object} ProjectRoleDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/roles/{roleId} [put]
+func UpdateProjectRole(c *gin.Context) {
+	project, access, ok := requireProjectCapability(c, models.CapabilityManageRoles, "Your role doesn't allow managing roles")
+	if !ok {
+		return
+	}
+	role, ok := loadProjectRole(c, project.ID)
+	if !ok {
+		return
+	}
+	request, ok := bindProjectRole(c)
+	if !ok {
+		return
+	}
+	if !access.CanAll(role.GetCapabilities()) || !access.CanAll(request.Capabilities) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can't change a role with capabilities you don't hold"})
+		return
+	}
+
+	taken, err := projectRoleNameTaken(project.ID, request.Name, role.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save role"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The project already has a role with this name"})
+		return
+	}
+
+	before := ProjectRoleRequest{Name: role.Name, Description: role.Description, Capabilities: role.GetCapabilities()}
+	previousName := role.Name
+	role.Name, role.Description = request.Name, request.Description
+	role.SetCapabilities(request.Capabilities)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&role).Error; err != nil {
+			return err
+		}
+		// roles are assigned by name
+		if previousName != role.Name {
+			if err := tx.Model(&models.Collaborator{}).Where("project_id = ? AND role = ?", project.ID, previousName).Update("role", role.Name).Error; err != nil {
+				return err
+			}
+			err := tx.Model(&models.Invitation{}).Where("project_id = ? AND role = ? AND status = ?", project.ID, previousName, models.InvitationStatusPending).
+				Update("role", role.Name).Error
+			if err != nil {
+				return err
+			}
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectRoleUpdated, TargetType: "project_role", TargetID: role.ID,
+			ProjectID: auditProject(project.ID), Before: before, After: request,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, projectRoleDetail(role))
+}
+
+// DeleteProjectRole godoc
+// @Summary      Delete a custom role
+// @Description  Deletes a custom role. Roles still held by collaborators or offered in pending invitations can't be deleted. Requires the manage_roles capability, and every capability the role grants.
+// @Tags         Project Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        roleId path int true "Role ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/roles/{roleId} [delete]
+func DeleteProjectRole(c *gin.Context) {
+	project, access, ok := requireProjectCapability(c, models.CapabilityManageRoles, "Your role doesn't allow managing roles")
+	if !ok {
+		return
+	}
+	role, ok := loadProjectRole(c, project.ID)
+	if !ok {
+		return
+	}
+	if !access.CanAll(role.GetCapabilities()) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "You can't change a role with capabilities you don't hold"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var holders, invitations int64
+		if err := tx.Model(&models.Collaborator{}).Where("project_id = ? AND role = ?", project.ID, role.Name).Count(&holders).Error; err != nil {
+			return err
+		}
+		err := tx.Model(&models.Invitation{}).Where("project_id = ? AND role = ? AND status = ?", project.ID, role.Name, models.InvitationStatusPending).
+			Count(&invitations).Error
+		if err != nil {
+			return err
+		}
+		if holders+invitations > 0 {
+			return services.ErrRoleInUse
+		}
+
+		if err := tx.Delete(&role).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProjectRoleDeleted, TargetType: "project_role", TargetID: role.ID,
+			ProjectID: auditProject(project.ID), Before: projectRoleDetail(role),
+		})
+	})
+	if errors.Is(err, services.ErrRoleInUse) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "The role is still held by collaborators or offered in pending invitations"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete role"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Role deleted successfully"})
+}
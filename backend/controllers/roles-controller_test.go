@@ -0,0 +1,144 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+func TestProjectRoles(t *testing.T) {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+
+	router := gin.Default()
+	projects := router.Group("/projects", middleware.AuthRequired())
+	projects.POST("/:id/collaborators", controllers.InviteCollaborator)
+	projects.PUT("/:id/collaborators/:userId", controllers.UpdateCollaboratorRole)
+	projects.GET("/:id/permissions", controllers.GetProjectPermissions)
+	projects.GET("/:id/roles", controllers.ListProjectRoles)
+	projects.POST("/:id/roles", controllers.CreateProjectRole)
+	projects.PUT("/:id/roles/:roleId", controllers.UpdateProjectRole)
+	projects.DELETE("/:id/roles/:roleId", controllers.DeleteProjectRole)
+	projects.GET("/:id/tasks", controllers.ListTasks)
+	projects.POST("/:id/tasks", controllers.CreateTask)
+
+	tokens := make(map[string]string)
+	users := make(map[string]models.User)
+	for _, name := range []string{"owner", "editor", "programmer", "reviewer", "lead", "outsider"} {
+		user := models.User{Email: name + "@example.com", Password: "hash"}
+		database.DB.Create(&user)
+		database.DB.Create(&models.UserProfile{UserID: user.ID, FullName: name})
+		users[name] = user
+		tokens[name], _ = utils.GenerateJWT(user.ID, user.Email)
+	}
+	project := models.Project{Title: "Roles", OwnerID: users["owner"].ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	for _, name := range []string{"owner", "editor", "programmer", "reviewer", "lead"} {
+		database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: users[name].ID, Role: name})
+	}
+	path := fmt.Sprintf("/projects/%d", project.ID)
+	collaboratorPath := func(name string) string { return fmt.Sprintf("%s/collaborators/%d", path, users[name].ID) }
+
+	// built-in roles follow the capability matrix
+	var permissions controllers.ProjectPermissionsResponse
+	w := projectRequest(router, "GET", path+"/permissions", tokens["programmer"], nil)
+	json.Unmarshal(w.Body.Bytes(), &permissions)
+	assert.Equal(t, "programmer", permissions.Role)
+	assert.Contains(t, permissions.Capabilities, models.CapabilityManageTasks)
+	assert.NotContains(t, permissions.Capabilities, models.CapabilityDeleteTasks)
+	permissions = controllers.ProjectPermissionsResponse{}
+	w = projectRequest(router, "GET", path+"/permissions", tokens["outsider"], nil)
+	json.Unmarshal(w.Body.Bytes(), &permissions)
+	assert.Equal(t, "", permissions.Role)
+	assert.Empty(t, permissions.Capabilities)
+
+	// custom roles always include viewing the project
+	w = projectRequest(router, "POST", path+"/roles", tokens["editor"], map[string]interface{}{"name": "reviewer", "capabilities": []string{"comment"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", path+"/roles", tokens["owner"], map[string]interface{}{"name": "reviewer", "capabilities": []string{"comment", "fly"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "POST", path+"/roles", tokens["owner"], map[string]interface{}{"name": "Owner", "capabilities": []string{"comment"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "POST", path+"/roles", tokens["owner"], map[string]interface{}{"name": "reviewer", "capabilities": []string{"comment"}})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var reviewer controllers.ProjectRoleDetail
+	json.Unmarshal(w.Body.Bytes(), &reviewer)
+	assert.Equal(t, []models.Capability{models.CapabilityView, models.CapabilityComment}, reviewer.Capabilities)
+	w = projectRequest(router, "POST", path+"/roles", tokens["owner"], map[string]interface{}{"name": "Reviewer"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = projectRequest(router, "GET", path+"/tasks", tokens["reviewer"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "POST", path+"/tasks", tokens["reviewer"], map[string]interface{}{"title": "Review"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", path+"/tasks", tokens["programmer"], map[string]interface{}{"title": "Build"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// roles are only granted by users holding all of their capabilities
+	w = projectRequest(router, "POST", path+"/roles", tokens["owner"], map[string]interface{}{
+		"name": "lead", "capabilities": []string{"comment", "edit_wiki", "manage_tasks", "upload_files", "invite", "manage_roles"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(router, "POST", path+"/roles", tokens["lead"], map[string]interface{}{"name": "admin", "capabilities": []string{"delete_project"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", path+"/collaborators", tokens["lead"], map[string]interface{}{"email": "new@example.com", "role": "editor"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "POST", path+"/collaborators", tokens["lead"], map[string]interface{}{"email": "new@example.com", "role": "ghost"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "POST", path+"/collaborators", tokens["owner"], map[string]interface{}{"email": "new@example.com", "role": "owner"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "POST", path+"/collaborators", tokens["lead"], map[string]interface{}{"email": "new@example.com", "role": "reviewer"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(router, "PUT", collaboratorPath("editor"), tokens["lead"], map[string]interface{}{"role": "programmer"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = projectRequest(router, "PUT", collaboratorPath("programmer"), tokens["lead"], map[string]interface{}{"role": "reviewer"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "POST", path+"/tasks", tokens["programmer"], map[string]interface{}{"title": "Build more"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// renaming a role keeps it assigned
+	w = projectRequest(router, "PUT", fmt.Sprintf("%s/roles/%d", path, reviewer.ID), tokens["owner"], map[string]interface{}{
+		"name": "commenter", "capabilities": []string{"comment", "manage_tasks"},
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	permissions = controllers.ProjectPermissionsResponse{}
+	w = projectRequest(router, "GET", path+"/permissions", tokens["reviewer"], nil)
+	json.Unmarshal(w.Body.Bytes(), &permissions)
+	assert.Equal(t, "commenter", permissions.Role)
+	assert.Contains(t, permissions.Capabilities, models.CapabilityManageTasks)
+	var invitation models.Invitation
+	database.DB.Where("email = ?", "new@example.com").First(&invitation)
+	assert.Equal(t, models.CollaboratorRole("commenter"), invitation.Role)
+
+	var roles controllers.ProjectRoleListResponse
+	w = projectRequest(router, "GET", path+"/roles", tokens["programmer"], nil)
+	json.Unmarshal(w.Body.Bytes(), &roles)
+	if assert.Len(t, roles.Roles, 5) {
+		assert.True(t, roles.Roles[0].BuiltIn)
+		assert.Equal(t, "commenter", roles.Roles[3].Name)
+	}
+	w = projectRequest(router, "GET", path+"/roles", tokens["outsider"], nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// roles in use can't be deleted
+	w = projectRequest(router, "DELETE", fmt.Sprintf("%s/roles/%d", path, reviewer.ID), tokens["owner"], nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = projectRequest(router, "POST", path+"/roles", tokens["owner"], map[string]interface{}{"name": "unused"})
+	var unused controllers.ProjectRoleDetail
+	json.Unmarshal(w.Body.Bytes(), &unused)
+	w = projectRequest(router, "DELETE", fmt.Sprintf("%s/roles/%d", path, unused.ID), tokens["owner"], nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "DELETE", fmt.Sprintf("%s/roles/%d", path, unused.ID), tokens["owner"], nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
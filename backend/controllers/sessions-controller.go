@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SessionDetail struct {
+	ID uint `json:"id"`
+	// browser and operating system, e.g. "Firefox on Windows"
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// whether this is the session making the request
+	Current bool `json:"current"`
+}
+
+type SessionListResponse struct {
+	Sessions []SessionDetail `json:"sessions"`
+}
+
+// ListSessions godoc
+// @Summary      List active sessions
+// @Description  Lists the devices the authenticated user is logged in on, most recently used first.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} SessionListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/sessions [get]
+func ListSessions(c *gin.Context) {
+	sessions, err := services.ActiveSessions(database.DB, utils.InferUserID(c), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch sessions"})
+		return
+	}
+
+	currentID := c.GetUint(utils.SessionIDKey)
+	response := SessionListResponse{Sessions: make([]SessionDetail, len(sessions))}
+	for i, session := range sessions {
+		response.Sessions[i] = SessionDetail{
+			ID:         session.ID,
+			Device:     services.DeviceName(session.UserAgent),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// EndSession godoc
+// @Summary      End a session
+// @Description  Logs the authenticated user out of one of their devices. Tokens issued for the session stop working immediately. Ending the current session logs out.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Session ID"
+// @Success      200 {object} MessageResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/sessions/{id} [delete]
+func EndSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid session ID"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.EndSession(tx, utils.InferUserID(c), uint(sessionID), time.Now()); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{Action: models.AuditSessionEnded, TargetType: "session", TargetID: uint(sessionID)})
+	})
+	if errors.Is(err, services.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to end session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Session ended successfully"})
+}
+
+type LoginAttemptDetail struct {
+	models.LoginAttempt
+	// browser and operating system, e.g. "Firefox on Windows"
+	Device string `json:"device"`
+}
+
+type LoginHistoryResponse struct {
+	Logins   []LoginAttemptDetail `json:"logins"`
+	Page     int                  `json:"page" example:"1"`
+	PageSize int                  `json:"page_size" example:"20"`
+	Total    int64                `json:"total" example:"4"`
+}
+
+// ListLoginHistory godoc
+// @Summary      List login history
+// @Description  Lists successful and failed logins to the authenticated user's account, newest first. Logins from a device or network the account hadn't used before are marked unfamiliar.
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Logins per page" default(20)
+// @Success      200 {object} LoginHistoryResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users/me/login-history [get]
+func ListLoginHistory(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	query := database.DB.Model(&models.LoginAttempt{}).Where("user_id = ?", utils.InferUserID(c))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch login history"})
+		return
+	}
+
+	var attempts []models.LoginAttempt
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&attempts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch login history"})
+		return
+	}
+
+	response := LoginHistoryResponse{Logins: make([]LoginAttemptDetail, len(attempts)), Page: page, PageSize: pageSize, Total: total}
+	for i, attempt := range attempts {
+		response.Logins[i] = LoginAttemptDetail{LoginAttempt: attempt, Device: services.DeviceName(attempt.UserAgent)}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
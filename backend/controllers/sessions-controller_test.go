@@ -0,0 +1,184 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+)
+
+// loginFrom logs a fixture account in with the given user agent and returns the session token
+func loginFrom(t *testing.T, f accountFixture, email, userAgent string) string {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBufferString(fmt.Sprintf(`{"email": %q, "password": "password"}`, email)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = "203.0.113.5:52100"
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response controllers.UserLoginResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response.Token
+}
+
+func listSessions(t *testing.T, f accountFixture, token string) []controllers.SessionDetail {
+	w := projectRequest(f.router, "GET", "/users/me/sessions", token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response controllers.SessionListResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response.Sessions
+}
+
+func TestSessions(t *testing.T) {
+	f := setupAccountTest(t)
+	f.router.POST("/auth/login", controllers.LoginUser)
+	f.router.GET("/users/me/sessions", middleware.AuthRequired(), controllers.ListSessions)
+	f.router.DELETE("/users/me/sessions/:id", middleware.AuthRequired(), controllers.EndSession)
+
+	laptop := loginFrom(t, f, f.user.Email, "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	phone := loginFrom(t, f, f.user.Email, "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36")
+
+	sessions := listSessions(t, f, laptop)
+	if !assert.Len(t, sessions, 2) {
+		return
+	}
+	var laptopID, phoneID uint
+	for _, session := range sessions {
+		if session.Current {
+			laptopID = session.ID
+			assert.Equal(t, "Firefox on Linux", session.Device)
+		} else {
+			phoneID = session.ID
+			assert.Equal(t, "Chrome on Android", session.Device)
+		}
+	}
+	assert.NotZero(t, laptopID)
+	assert.NotZero(t, phoneID)
+
+	// sessions of other users can't be ended
+	colleagueToken := loginFrom(t, f, f.colleague.Email, "curl/8.5.0")
+	w := projectRequest(f.router, "DELETE", fmt.Sprintf("/users/me/sessions/%d", laptopID), colleagueToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// ending a session signs the device out right away
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/users/me/sessions/%d", phoneID), laptop, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "GET", "/users/me/sessions", phone, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Len(t, listSessions(t, f, laptop), 1)
+
+	// changing the password ends every other session and keeps the current one
+	tablet := loginFrom(t, f, f.user.Email, "Mozilla/5.0 (iPad; CPU OS 17_5 like Mac OS X) AppleWebKit/605.1.15 Version/17.5 Mobile/15E148 Safari/604.1")
+	w = projectRequest(f.router, "POST", "/users/me/password", laptop, map[string]string{"current_password": "password", "new_password": "a long unusual passphrase"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var changed controllers.ChangePasswordResponse
+	json.Unmarshal(w.Body.Bytes(), &changed)
+	w = projectRequest(f.router, "GET", "/users/me/sessions", tablet, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	sessions = listSessions(t, f, changed.Token)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, laptopID, sessions[0].ID)
+		assert.True(t, sessions[0].Current)
+	}
+
+	// ending the current session logs out
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/users/me/sessions/%d", laptopID), changed.Token, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(f.router, "GET", "/users/me/sessions", changed.Token, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestUnfamiliarLoginAlert(t *testing.T) {
+	f := setupAccountTest(t)
+	f.router.POST("/auth/login", controllers.LoginUser)
+	f.router.GET("/auth/not-me", controllers.ConfirmLoginReport)
+	f.router.POST("/auth/not-me", controllers.ReportLogin)
+	f.router.GET("/users/me/sessions", middleware.AuthRequired(), controllers.ListSessions)
+	f.router.GET("/users/me/login-history", middleware.AuthRequired(), controllers.ListLoginHistory)
+	firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+	// the first login and later ones from the same device don't raise alerts
+	laptop := loginFrom(t, f, f.user.Email, firefox)
+	loginFrom(t, f, f.user.Email, firefox)
+	assert.Empty(t, f.mailer.sent)
+
+	w := projectRequest(f.router, "POST", "/auth/login", "", map[string]string{"email": f.user.Email, "password": "wrong"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	loginFrom(t, f, f.user.Email, "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36")
+	if !assert.Len(t, f.mailer.sent, 1) {
+		return
+	}
+	assert.Contains(t, f.mailer.sent[0].Text, "Chrome on Windows")
+	var notification models.Notification
+	assert.NoError(t, database.DB.Where("user_id = ? AND type = ?", f.user.ID, models.NotificationUnfamiliarLogin).First(&notification).Error)
+	assert.NotNil(t, notification.EmailedAt)
+
+	w = projectRequest(f.router, "GET", "/users/me/login-history", laptop, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history controllers.LoginHistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+	if assert.Len(t, history.Logins, 4) {
+		assert.True(t, history.Logins[0].Unfamiliar)
+		assert.Equal(t, models.LoginFailureInvalidPassword, history.Logins[1].FailureReason)
+		assert.False(t, history.Logins[1].Succeeded)
+		assert.Equal(t, "Firefox on Linux", history.Logins[2].Device)
+	}
+
+	// tampered links are rejected
+	link := regexp.MustCompile(`token=([^\s]+)`).FindStringSubmatch(f.mailer.sent[0].Text)[1]
+	token, _ := url.QueryUnescape(link)
+	w = projectRequest(f.router, "GET", "/auth/not-me?token="+url.QueryEscape(token+"x"), "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NotContains(t, w.Body.String(), "<form")
+	w = projectRequest(f.router, "POST", "/auth/not-me?token="+url.QueryEscape(token+"x"), "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// opening the link only asks to confirm, so link scanners can't lock the account
+	w = projectRequest(f.router, "GET", "/auth/not-me?token="+url.QueryEscape(token), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<form method="post">`)
+	var user models.User
+	database.DB.First(&user, f.user.ID)
+	assert.False(t, user.PasswordResetRequired)
+	assert.Len(t, f.mailer.sent, 1)
+
+	// confirming "this wasn't me" signs out every device and requires a new password
+	form := url.Values{"token": {token}}
+	req, _ := http.NewRequest("POST", "/auth/not-me", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+	w = httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Your account has been secured")
+	database.DB.First(&user, f.user.ID)
+	assert.True(t, user.PasswordResetRequired)
+	var active int64
+	database.DB.Model(&models.Session{}).Where("user_id = ? AND ended_at IS NULL", f.user.ID).Count(&active)
+	assert.Zero(t, active)
+	if assert.Len(t, f.mailer.sent, 2) {
+		assert.Contains(t, f.mailer.sent[1].Text, "/reset-password?token=")
+	}
+	var reported models.LoginAttempt
+	database.DB.First(&reported, history.Logins[0].ID)
+	assert.NotNil(t, reported.ReportedAt)
+
+	// reporting again doesn't send another email
+	w = projectRequest(f.router, "POST", "/auth/not-me?token="+url.QueryEscape(token), "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, f.mailer.sent, 2)
+}
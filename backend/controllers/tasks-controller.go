@@ -0,0 +1,505 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TaskDetail struct {
+	ID          uint       `json:"id"`
+	ProjectID   uint       `json:"project_id"`
+	MilestoneID *uint      `json:"milestone_id"`
+	CreatorID   uint       `json:"creator_id"`
+	AssigneeID  *uint      `json:"assignee_id"`
+	Title       string     `json:"title" example:"Write literature review"`
+	Description string     `json:"description"`
+	Status      string     `json:"status" example:"todo"`
+	Position    int        `json:"position" example:"0"`
+	DueDate     *time.Time `json:"due_date"`
+	Labels      []string   `json:"labels" example:"writing,urgent"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type TaskListResponse struct {
+	Tasks []TaskDetail `json:"tasks"`
+}
+
+type TaskCreationRequest struct {
+	Title       string     `json:"title" binding:"required,max=200"`
+	Description string     `json:"description" binding:"max=10000"`
+	Status      string     `json:"status" binding:"omitempty,oneof=todo in-progress review done"`
+	AssigneeID  *uint      `json:"assignee_id"`
+	MilestoneID *uint      `json:"milestone_id"`
+	DueDate     *time.Time `json:"due_date"`
+	Labels      []string   `json:"labels" binding:"max=20,dive,max=50"`
+}
+
+type TaskEditRequest struct {
+	Title       string     `json:"title" binding:"required,max=200"`
+	Description string     `json:"description" binding:"max=10000"`
+	Status      string     `json:"status" binding:"required,oneof=todo in-progress review done"`
+	AssigneeID  *uint      `json:"assignee_id"`
+	MilestoneID *uint      `json:"milestone_id"`
+	DueDate     *time.Time `json:"due_date"`
+	Labels      []string   `json:"labels" binding:"max=20,dive,max=50"`
+}
+
+type TaskMoveRequest struct {
+	Status   string `json:"status" binding:"required,oneof=todo in-progress review done"`
+	Position *int   `json:"position" binding:"required"`
+}
+
+func toTaskDetail(task models.Task) TaskDetail {
+	return TaskDetail{
+		ID:          task.ID,
+		ProjectID:   task.ProjectID,
+		MilestoneID: task.MilestoneID,
+		CreatorID:   task.CreatorID,
+		AssigneeID:  task.AssigneeID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		Position:    task.Position,
+		DueDate:     task.DueDate,
+		Labels:      task.GetLabels(),
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
+
+// validateTaskReferences checks that the assignee is a current collaborator of the project and the milestone
+// belongs to it. On failure the error response is written and false is returned.
+func validateTaskReferences(c *gin.Context, project models.Project, assigneeID, milestoneID *uint) bool {
+	if assigneeID != nil {
+		if access := projectAccess(project, *assigneeID); !access.IsMember() || !access.Status.IsCurrent() {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Tasks can only be assigned to current project collaborators"})
+			return false
+		}
+	}
+	if milestoneID != nil {
+		var milestone models.Milestone
+		if err := database.DB.Where("id = ? AND project_id = ?", *milestoneID, project.ID).First(&milestone).Error; err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Milestone not found in this project"})
+			return false
+		}
+	}
+	return true
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, to be used with ESCAPE '\'
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// findTask loads a task of a project from the "taskId" URL parameter, writing a 404 response when it does not exist
+func findTask(c *gin.Context, projectID uint) (models.Task, bool) {
+	var task models.Task
+	if err := database.DB.Where("id = ? AND project_id = ?", c.Param("taskId"), projectID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Task not found"})
+		return task, false
+	}
+	return task, true
+}
+
+// columnLength counts the tasks in a status column, excluding the given task
+func columnLength(tx *gorm.DB, projectID uint, status models.TaskStatus, excludeID uint) int {
+	var count int64
+	tx.Model(&models.Task{}).
+		Where("project_id = ? AND status = ? AND id <> ?", projectID, status, excludeID).
+		Count(&count)
+	return int(count)
+}
+
+// removeFromColumn closes the gap a task leaves behind in its current status column
+func removeFromColumn(tx *gorm.DB, task models.Task) error {
+	return tx.Model(&models.Task{}).
+		Where("project_id = ? AND status = ? AND position > ? AND id <> ?", task.ProjectID, task.Status, task.Position, task.ID).
+		Update("position", gorm.Expr("position - 1")).Error
+}
+
+// moveTask places a task at position within the status column, shifting the other tasks of both columns.
+// Positions beyond the end of the column append the task.
+func moveTask(tx *gorm.DB, task *models.Task, status models.TaskStatus, position int) error {
+	if err := removeFromColumn(tx, *task); err != nil {
+		return err
+	}
+
+	length := columnLength(tx, task.ProjectID, status, task.ID)
+	if position < 0 || position > length {
+		position = length
+	}
+
+	// open a gap at the target position
+	if err := tx.Model(&models.Task{}).
+		Where("project_id = ? AND status = ? AND position >= ? AND id <> ?", task.ProjectID, status, position, task.ID).
+		Update("position", gorm.Expr("position + 1")).Error; err != nil {
+		return err
+	}
+
+	task.Status = status
+	task.Position = position
+	return tx.Model(task).Updates(map[string]interface{}{"status": status, "position": position}).Error
+}
+
+// ListTasks godoc
+// @Summary      List project tasks
+// @Description  Lists the tasks of a project ordered by board column and position. Results can be filtered by status, assignee, milestone and label.
+// @Tags         Project Tasks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        status query string false "Filter by status (todo, in-progress, review, done)"
+// @Param        assignee_id query int false "Filter by assignee"
+// @Param        milestone_id query int false "Filter by milestone"
+// @Param        label query string false "Filter by label"
+// @Success      200 {object} TaskListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/tasks [get]
+func ListTasks(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can view project tasks")
+	if !ok {
+		return
+	}
+
+	query := database.DB.Where("project_id = ?", project.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if assigneeID := c.Query("assignee_id"); assigneeID != "" {
+		query = query.Where("assignee_id = ?", assigneeID)
+	}
+	if milestoneID := c.Query("milestone_id"); milestoneID != "" {
+		query = query.Where("milestone_id = ?", milestoneID)
+	}
+	label := c.Query("label")
+	if label != "" {
+		// labels are stored as a JSON array of strings, so look for the label as it is encoded there
+		encoded, _ := json.Marshal(label)
+		query = query.Where(`labels LIKE ? ESCAPE '\'`, "%"+escapeLike(string(encoded))+"%")
+	}
+
+	var tasks []models.Task
+	if err := query.Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch tasks"})
+		return
+	}
+	if label != "" {
+		// LIKE ignores case, the label has to match exactly
+		tasks = slices.DeleteFunc(tasks, func(task models.Task) bool { return !slices.Contains(task.GetLabels(), label) })
+	}
+
+	// order by board column, then by position within the column
+	columns := make(map[models.TaskStatus]int, len(models.TaskStatuses))
+	for i, status := range models.TaskStatuses {
+		columns[status] = i
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].Status != tasks[j].Status {
+			return columns[tasks[i].Status] < columns[tasks[j].Status]
+		}
+		return tasks[i].Position < tasks[j].Position
+	})
+
+	response := make([]TaskDetail, len(tasks))
+	for i, task := range tasks {
+		response[i] = toTaskDetail(task)
+	}
+
+	c.JSON(http.StatusOK, TaskListResponse{Tasks: response})
+}
+
+// CreateTask godoc
+// @Summary      Create a project task
+// @Description  Creates a task at the end of its status column. The assignee must be a collaborator of the project.
+// @Tags         Project Tasks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body TaskCreationRequest true "Task attributes"
+// @Success      201 {object} TaskDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/tasks [post]
+func CreateTask(c *gin.Context) {
+	var request TaskCreationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageTasks, "Your role doesn't allow creating tasks")
+	if !ok {
+		return
+	}
+	if !validateTaskReferences(c, project, request.AssigneeID, request.MilestoneID) {
+		return
+	}
+
+	status := models.TaskStatus(request.Status)
+	if status == "" {
+		status = models.TaskStatusTodo
+	}
+
+	task := models.Task{
+		ProjectID:   project.ID,
+		MilestoneID: request.MilestoneID,
+		CreatorID:   utils.InferUserID(c),
+		AssigneeID:  request.AssigneeID,
+		Title:       request.Title,
+		Description: request.Description,
+		Status:      status,
+		DueDate:     request.DueDate,
+	}
+	task.SetLabels(request.Labels)
+
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		task.Position = columnLength(tx, project.ID, status, 0)
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if err := services.EnqueueWebhookEvent(tx, project.ID, models.WebhookEventTaskCreated, toTaskDetail(task)); err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditTaskCreated, TargetType: "task", TargetID: task.ID,
+			ProjectID: auditProject(project.ID), After: toTaskDetail(task),
+		})
+		if err != nil {
+			return err
+		}
+		notification, err = services.NotifyTaskAssigned(tx, project, task, task.CreatorID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create task"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	c.JSON(http.StatusCreated, toTaskDetail(task))
+}
+
+// RetrieveTask godoc
+// @Summary      Get a project task
+// @Description  Retrieves a single task of a project.
+// @Tags         Project Tasks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        taskId path int true "Task ID"
+// @Success      200 {object} TaskDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Router       /projects/{id}/tasks/{taskId} [get]
+func RetrieveTask(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can view project tasks")
+	if !ok {
+		return
+	}
+	task, ok := findTask(c, project.ID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaskDetail(task))
+}
+
+// EditTask godoc
+// @Summary      Edit a project task
+// @Description  Replaces the attributes of a task. Changing the status moves the task to the end of the new column.
+// @Tags         Project Tasks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        taskId path int true "Task ID"
+// @Param        request body TaskEditRequest true "Task attributes"
+// @Success      200 {object} TaskDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/tasks/{taskId} [put]
+func EditTask(c *gin.Context) {
+	var request TaskEditRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageTasks, "Your role doesn't allow editing tasks")
+	if !ok {
+		return
+	}
+	task, ok := findTask(c, project.ID)
+	if !ok {
+		return
+	}
+	// the task may keep an assignee who has since left the project, only new assignees must be current collaborators
+	assigneeID := request.AssigneeID
+	if assigneeID != nil && task.AssigneeID != nil && *assigneeID == *task.AssigneeID {
+		assigneeID = nil
+	}
+	if !validateTaskReferences(c, project, assigneeID, request.MilestoneID) {
+		return
+	}
+
+	before := toTaskDetail(task)
+	var notification *models.Notification
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if status := models.TaskStatus(request.Status); status != task.Status {
+			if err := moveTask(tx, &task, status, -1); err != nil {
+				return err
+			}
+		}
+
+		reassigned := request.AssigneeID != nil && (task.AssigneeID == nil || *task.AssigneeID != *request.AssigneeID)
+
+		task.Title = request.Title
+		task.Description = request.Description
+		task.AssigneeID = request.AssigneeID
+		task.MilestoneID = request.MilestoneID
+		task.DueDate = request.DueDate
+		task.SetLabels(request.Labels)
+		if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+		err := recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditTaskUpdated, TargetType: "task", TargetID: task.ID,
+			ProjectID: auditProject(project.ID), Before: before, After: toTaskDetail(task),
+		})
+		if err != nil {
+			return err
+		}
+
+		if reassigned {
+			notification, err = services.NotifyTaskAssigned(tx, project, task, utils.InferUserID(c))
+		}
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update task"})
+		return
+	}
+	services.PublishNotifications(notification)
+
+	c.JSON(http.StatusOK, toTaskDetail(task))
+}
+
+// MoveTask godoc
+// @Summary      Move a project task
+// @Description  Moves a task to a position (0-based) within a status column, reordering the other tasks.
+// @Tags         Project Tasks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        taskId path int true "Task ID"
+// @Param        request body TaskMoveRequest true "Target column and position"
+// @Success      200 {object} TaskDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/tasks/{taskId}/move [post]
+func MoveTask(c *gin.Context) {
+	var request TaskMoveRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageTasks, "Your role doesn't allow moving tasks")
+	if !ok {
+		return
+	}
+	task, ok := findTask(c, project.ID)
+	if !ok {
+		return
+	}
+
+	before := toTaskDetail(task)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := moveTask(tx, &task, models.TaskStatus(request.Status), *request.Position); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditTaskMoved, TargetType: "task", TargetID: task.ID,
+			ProjectID: auditProject(project.ID), Before: before, After: toTaskDetail(task),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to move task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toTaskDetail(task))
+}
+
+// DeleteTask godoc
+// @Summary      Delete a project task
+// @Description  Deletes a task. Requires the delete_tasks capability.
+// @Tags         Project Tasks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        taskId path int true "Task ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/tasks/{taskId} [delete]
+func DeleteTask(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityDeleteTasks, "Your role doesn't allow deleting tasks")
+	if !ok {
+		return
+	}
+	task, ok := findTask(c, project.ID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		if err := removeFromColumn(tx, task); err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditTaskDeleted, TargetType: "task", TargetID: task.ID,
+			ProjectID: auditProject(project.ID), Before: toTaskDetail(task),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Task deleted successfully"})
+}
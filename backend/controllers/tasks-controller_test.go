@@ -0,0 +1,215 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type tasksFixture struct {
+	router          *gin.Engine
+	project         models.Project
+	owner           models.User
+	programmer      models.User
+	outsider        models.User
+	ownerToken      string
+	programmerToken string
+}
+
+func setupTasksTest(t *testing.T) tasksFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+	database.DB.Exec("DELETE FROM milestones")
+
+	owner := models.User{Email: "tasks_owner@example.com", Password: "password"}
+	programmer := models.User{Email: "tasks_programmer@example.com", Password: "password"}
+	outsider := models.User{Email: "tasks_outsider@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&programmer)
+	database.DB.Create(&outsider)
+
+	project := models.Project{Title: "Tasks Project", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: programmer.ID, Role: "programmer"})
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	programmerToken, _ := utils.GenerateJWT(programmer.ID, programmer.Email)
+
+	router := gin.Default()
+	router.GET("/projects/:id/tasks", middleware.AuthRequired(), controllers.ListTasks)
+	router.POST("/projects/:id/tasks", middleware.AuthRequired(), controllers.CreateTask)
+	router.GET("/projects/:id/tasks/:taskId", middleware.AuthRequired(), controllers.RetrieveTask)
+	router.PUT("/projects/:id/tasks/:taskId", middleware.AuthRequired(), controllers.EditTask)
+	router.POST("/projects/:id/tasks/:taskId/move", middleware.AuthRequired(), controllers.MoveTask)
+	router.DELETE("/projects/:id/tasks/:taskId", middleware.AuthRequired(), controllers.DeleteTask)
+	router.GET("/projects/:id/milestones", middleware.AuthRequired(), controllers.ListMilestones)
+	router.POST("/projects/:id/milestones", middleware.AuthRequired(), controllers.CreateMilestone)
+	router.PUT("/projects/:id/milestones/:milestoneId", middleware.AuthRequired(), controllers.EditMilestone)
+	router.DELETE("/projects/:id/milestones/:milestoneId", middleware.AuthRequired(), controllers.DeleteMilestone)
+
+	return tasksFixture{router, project, owner, programmer, outsider, ownerToken, programmerToken}
+}
+
+func projectRequest(router *gin.Engine, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func createTestTask(t *testing.T, f tasksFixture, body map[string]interface{}) controllers.TaskDetail {
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/tasks", f.project.ID), f.programmerToken, body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var task controllers.TaskDetail
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	return task
+}
+
+func TestCreateAndFilterTasks(t *testing.T) {
+	f := setupTasksTest(t)
+
+	first := createTestTask(t, f, map[string]interface{}{
+		"title":       "Collect data",
+		"assignee_id": f.programmer.ID,
+		"labels":      []string{"data", "urgent"},
+		"due_date":    "2025-06-01T00:00:00Z",
+	})
+	assert.Equal(t, "todo", first.Status)
+	assert.Equal(t, 0, first.Position)
+	assert.Equal(t, []string{"data", "urgent"}, first.Labels)
+
+	second := createTestTask(t, f, map[string]interface{}{"title": "Write report", "labels": []string{"writing"}})
+	assert.Equal(t, 1, second.Position)
+
+	// assignee must be a collaborator
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/tasks", f.project.ID), f.ownerToken, map[string]interface{}{
+		"title":       "Outsourced",
+		"assignee_id": f.outsider.ID,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// filter by label
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/tasks?label=urgent", f.project.ID), f.ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list controllers.TaskListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Tasks, 1)
+	assert.Equal(t, "Collect data", list.Tasks[0].Title)
+
+	// labels are matched exactly, whatever characters they contain
+	createTestTask(t, f, map[string]interface{}{"title": "Escaping", "labels": []string{`50% "done"`, "under_score"}})
+	for label, expected := range map[string]int{`50% "done"`: 1, "50%": 0, "under_score": 1, "under%": 0, "under_": 0, "URGENT": 0} {
+		w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/tasks?label=%s", f.project.ID, url.QueryEscape(label)), f.ownerToken, nil)
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		assert.Len(t, list.Tasks, expected, label)
+	}
+
+	// filter by assignee
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/tasks?assignee_id=%d", f.project.ID, f.programmer.ID), f.ownerToken, nil)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Tasks, 1)
+}
+
+func TestMoveTaskReordersColumns(t *testing.T) {
+	f := setupTasksTest(t)
+
+	a := createTestTask(t, f, map[string]interface{}{"title": "A"})
+	b := createTestTask(t, f, map[string]interface{}{"title": "B"})
+	c := createTestTask(t, f, map[string]interface{}{"title": "C"})
+
+	// move C to the top of the todo column
+	w := projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/tasks/%d/move", f.project.ID, c.ID), f.programmerToken,
+		map[string]interface{}{"status": "todo", "position": 0})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// move A into progress
+	w = projectRequest(f.router, "POST", fmt.Sprintf("/projects/%d/tasks/%d/move", f.project.ID, a.ID), f.programmerToken,
+		map[string]interface{}{"status": "in-progress", "position": 0})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/tasks", f.project.ID), f.ownerToken, nil)
+	var list controllers.TaskListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list.Tasks, 3)
+
+	assert.Equal(t, c.ID, list.Tasks[0].ID)
+	assert.Equal(t, 0, list.Tasks[0].Position)
+	assert.Equal(t, b.ID, list.Tasks[1].ID)
+	assert.Equal(t, 1, list.Tasks[1].Position)
+	assert.Equal(t, a.ID, list.Tasks[2].ID)
+	assert.Equal(t, "in-progress", list.Tasks[2].Status)
+}
+
+func TestEditAndDeleteTask(t *testing.T) {
+	f := setupTasksTest(t)
+	task := createTestTask(t, f, map[string]interface{}{"title": "Draft"})
+
+	w := projectRequest(f.router, "PUT", fmt.Sprintf("/projects/%d/tasks/%d", f.project.ID, task.ID), f.programmerToken, map[string]interface{}{
+		"title":       "Final draft",
+		"status":      "review",
+		"assignee_id": f.owner.ID,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var edited controllers.TaskDetail
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &edited))
+	assert.Equal(t, "Final draft", edited.Title)
+	assert.Equal(t, "review", edited.Status)
+
+	// programmers cannot delete tasks
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/projects/%d/tasks/%d", f.project.ID, task.ID), f.programmerToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(f.router, "DELETE", fmt.Sprintf("/projects/%d/tasks/%d", f.project.ID, task.ID), f.ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = projectRequest(f.router, "GET", fmt.Sprintf("/projects/%d/tasks/%d", f.project.ID, task.ID), f.ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEditTaskOfAlumniAssignee(t *testing.T) {
+	f := setupTasksTest(t)
+	task := createTestTask(t, f, map[string]interface{}{"title": "Calibrate", "assignee_id": f.programmer.ID})
+	other := createTestTask(t, f, map[string]interface{}{"title": "Analyze"})
+	database.DB.Model(&models.Collaborator{}).Where("project_id = ? AND user_id = ?", f.project.ID, f.programmer.ID).
+		Update("status", models.CollaboratorStatusAlumni)
+
+	// the task keeps its assignee after they became alumni
+	path := fmt.Sprintf("/projects/%d/tasks/%d", f.project.ID, task.ID)
+	w := projectRequest(f.router, "PUT", path, f.ownerToken, map[string]interface{}{
+		"title":       "Calibrate sensors",
+		"status":      "todo",
+		"assignee_id": f.programmer.ID,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var edited controllers.TaskDetail
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &edited))
+	assert.Equal(t, "Calibrate sensors", edited.Title)
+
+	// but alumni can't be given new tasks
+	w = projectRequest(f.router, "PUT", fmt.Sprintf("/projects/%d/tasks/%d", f.project.ID, other.ID), f.ownerToken, map[string]interface{}{
+		"title":       "Analyze",
+		"status":      "todo",
+		"assignee_id": f.programmer.ID,
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -3,10 +3,15 @@ package controllers
 import (
 	"backend/database"
 	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ProfileRetrievalResponse struct {
@@ -20,14 +25,21 @@ type ProfileRetrievalResponse struct {
 	Projects    string `json:"projects"`
 	Location    string `json:"location"`
 	GitHub      string `json:"github"`
+	AvatarURL   string `json:"avatar_url"`
+	Hidden      bool   `json:"hidden"`
+	// set when the user's verified email belongs to a registered institution, unlike the self-declared affiliation and role
+	VerifiedInstitution *InstitutionBadge `json:"verified_institution"`
+	// registered institution and department the affiliation refers to, if any
+	AffiliationLink *AffiliationLink `json:"affiliation_link"`
 }
 
 // RetrieveUserProfile godoc
 // @Summary      Get user profile
-// @Description  Retrieve user profile information by user ID.
+// @Description  Retrieve user profile information by user ID. The email, location and GitHub fields are only shown to signed in users the profile owner hasn't blocked. Verified members of a registered institution carry a verification badge.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id path string true "User ID"
 // @Success      200  {object}  ProfileRetrievalResponse
 // @Failure      404  {object}  ErrorResponse
@@ -38,23 +50,39 @@ func RetrieveUserProfile(c *gin.Context) {
 
 	// get user and user profile in a single query
 	var user models.User
-	if err := database.DB.Preload("Profile").First(&user, userID).Error; err != nil {
+	if err := database.DB.Preload("Profile.Institution").Preload("Profile.AffiliationInstitution").Preload("Profile.Department").First(&user, userID).Error; err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 		return
 	}
 
 	// create response with new fields
 	response := ProfileRetrievalResponse{
-		UserID:      user.ID,
-		Email:       user.Email,
-		FullName:    user.Profile.FullName,
-		Bio:         user.Profile.Bio,
-		Affiliation: user.Profile.Affiliation,
-		Skills:      user.Profile.Skills,
-		Role:        user.Profile.Role,
-		Projects:    user.Profile.Projects,
-		Location:    user.Profile.Location,
-		GitHub:      user.Profile.GitHub,
+		UserID:              user.ID,
+		Email:               user.Email,
+		FullName:            user.Profile.FullName,
+		Bio:                 user.Profile.Bio,
+		Affiliation:         user.Profile.Affiliation,
+		Skills:              user.Profile.Skills,
+		Role:                user.Profile.Role,
+		Projects:            user.Profile.Projects,
+		Location:            user.Profile.Location,
+		GitHub:              user.Profile.GitHub,
+		AvatarURL:           avatarURL(user.Profile),
+		VerifiedInstitution: institutionBadge(user.Profile),
+		AffiliationLink:     affiliationLink(user.Profile),
+	}
+
+	// restricted fields are kept from anonymous visitors and from users the owner blocked
+	viewerID := utils.InferUserID(c)
+	if viewerID == 0 || (viewerID != user.ID && services.HasBlocked(database.DB, user.ID, viewerID)) {
+		response.Email = ""
+		response.Location = ""
+		response.GitHub = ""
+	}
+
+	// profiles hidden by moderators only reveal the account itself
+	if user.Profile.HiddenAt != nil {
+		response = ProfileRetrievalResponse{UserID: user.ID, Email: response.Email, Hidden: true}
 	}
 
 	// respond on success
@@ -70,6 +98,9 @@ type ProfileEditRequest struct {
 	Projects    string `json:"projects"`
 	Location    string `json:"location"`
 	GitHub      string `json:"github"`
+	// registered institution and department of the affiliation; when neither is given the affiliation text is matched against registered institutions
+	InstitutionID *uint `json:"institution_id" example:"1"`
+	DepartmentID  *uint `json:"department_id" example:"3"`
 }
 
 type ProfileEditResponse struct {
@@ -78,7 +109,7 @@ type ProfileEditResponse struct {
 
 // EditUserProfile godoc
 // @Summary      Edit user profile
-// @Description  Update an existing user profile with new information.
+// @Description  Update an existing user profile with new information. The affiliation is linked to the registered institution and department picked, or else to the ones its text matches, tolerating abbreviations and typos.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
@@ -109,6 +140,21 @@ func EditUserProfile(c *gin.Context) {
 		return
 	}
 
+	// link the affiliation to a registered institution, either the one picked or the one the text matches
+	institutionID, departmentID, err := services.ResolveAffiliation(database.DB, request.Affiliation, request.InstitutionID, request.DepartmentID)
+	if errors.Is(err, services.ErrInstitutionNotFound) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Institution not found"})
+		return
+	}
+	if errors.Is(err, services.ErrDepartmentNotFound) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Department not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to match affiliation"})
+		return
+	}
+
 	// find or create the profile linked to this user
 	var profile models.UserProfile
 	result := database.DB.Where("user_id = ?", userID).FirstOrCreate(&profile, models.UserProfile{UserID: uint(uid)})
@@ -118,6 +164,7 @@ func EditUserProfile(c *gin.Context) {
 	}
 
 	// update profile fields with new fields
+	before := profile
 	profile.FullName = request.FullName
 	profile.Bio = request.Bio
 	profile.Affiliation = request.Affiliation
@@ -126,9 +173,20 @@ func EditUserProfile(c *gin.Context) {
 	profile.Projects = request.Projects
 	profile.Location = request.Location
 	profile.GitHub = request.GitHub
+	profile.AffiliationInstitutionID = institutionID
+	profile.DepartmentID = departmentID
 
 	// save changes to database
-	if err := database.DB.Save(&profile).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&profile).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditProfileUpdated, TargetType: "user", TargetID: profile.UserID,
+			Before: before, After: profile,
+		})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update profile"})
 		return
 	}
@@ -136,3 +194,93 @@ func EditUserProfile(c *gin.Context) {
 	// send success response
 	c.JSON(http.StatusOK, ProfileEditResponse{Message: "Profile updated successfully"})
 }
+
+type UserSummary struct {
+	UserID              uint              `json:"user_id"`
+	FullName            string            `json:"full_name"`
+	Affiliation         string            `json:"affiliation"`
+	Role                string            `json:"role"`
+	AvatarURL           string            `json:"avatar_url"`
+	VerifiedInstitution *InstitutionBadge `json:"verified_institution"`
+	AffiliationLink     *AffiliationLink  `json:"affiliation_link"`
+}
+
+// userSummary describes a profile in user listings; its Institution, AffiliationInstitution and Department
+// must be loaded for the badge and affiliation link
+func userSummary(profile models.UserProfile) UserSummary {
+	return UserSummary{
+		UserID:              profile.UserID,
+		FullName:            profile.FullName,
+		Affiliation:         profile.Affiliation,
+		Role:                profile.Role,
+		AvatarURL:           avatarURL(profile),
+		VerifiedInstitution: institutionBadge(profile),
+		AffiliationLink:     affiliationLink(profile),
+	}
+}
+
+type UserSearchResponse struct {
+	Users    []UserSummary `json:"users"`
+	Page     int           `json:"page" example:"1"`
+	PageSize int           `json:"page_size" example:"20"`
+	Total    int64         `json:"total" example:"4"`
+}
+
+// SearchUsers godoc
+// @Summary      Search users
+// @Description  Searches user profiles by name, affiliation, role or skills. Filter by institution to only find users whose verified email belongs to it. Hidden profiles and disabled accounts are left out.
+// @Tags         Users
+// @Produce      json
+// @Param        q query string false "Search by name, affiliation, role or skills"
+// @Param        institution_id query int false "Only verified members of this institution"
+// @Param        verified query bool false "Only verified members of any institution"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Users per page" default(20)
+// @Success      200 {object} UserSearchResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /users [get]
+func SearchUsers(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.UserProfile{}).
+		Where("hidden_at IS NULL").
+		Where("user_id IN (?)", database.DB.Model(&models.User{}).Select("id").Where("disabled_at IS NULL AND deletion_scheduled_at IS NULL"))
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		pattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(full_name) LIKE ? OR LOWER(affiliation) LIKE ? OR LOWER(role) LIKE ? OR LOWER(skills) LIKE ?", pattern, pattern, pattern, pattern)
+	}
+	if institution := c.Query("institution_id"); institution != "" {
+		institutionID, err := strconv.ParseUint(institution, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid institution ID"})
+			return
+		}
+		query = query.Where("institution_id = ?", institutionID)
+	}
+	if c.Query("verified") == "true" {
+		query = query.Where("institution_id IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to search users"})
+		return
+	}
+
+	var profiles []models.UserProfile
+	if err := query.Preload("Institution").Preload("AffiliationInstitution").Preload("Department").Order("full_name").Order("user_id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to search users"})
+		return
+	}
+
+	response := UserSearchResponse{Users: make([]UserSummary, len(profiles)), Page: page, PageSize: pageSize, Total: total}
+	for i, profile := range profiles {
+		response.Users[i] = userSummary(profile)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -27,7 +27,7 @@ func setupUsersTest(t *testing.T) {
 	}
 
 	// Run migrations or setup test data here if needed
-	database.DB.AutoMigrate(&models.User{}, &models.UserProfile{})
+	database.DB.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.AuditEvent{})
 }
 
 func registerAndLoginUser(t *testing.T, email string) (string, uint) {
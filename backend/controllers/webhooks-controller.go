@@ -0,0 +1,440 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookDetail struct {
+	ID        uint      `json:"id"`
+	ProjectID uint      `json:"project_id"`
+	URL       string    `json:"url" example:"https://hooks.example.com/grid"`
+	Events    []string  `json:"events" example:"task.created,collaborator.joined"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookCreationResponse struct {
+	WebhookDetail
+	// the secret is only returned once, when the webhook is created
+	Secret string `json:"secret" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []WebhookDetail `json:"webhooks"`
+}
+
+type WebhookCreationRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=2048" example:"https://hooks.example.com/grid"`
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+}
+
+type WebhookEditRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,required"`
+	Active *bool    `json:"active" binding:"required"`
+}
+
+type WebhookDeliveryDetail struct {
+	ID             uint       `json:"id"`
+	Event          string     `json:"event" example:"task.created"`
+	Status         string     `json:"status" example:"succeeded"`
+	Attempts       int        `json:"attempts" example:"1"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status" example:"200"`
+	ResponseBody   string     `json:"response_body"`
+	Error          string     `json:"error"`
+	Payload        string     `json:"payload"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryDetail `json:"deliveries"`
+	Page       int                     `json:"page"`
+	PageSize   int                     `json:"page_size"`
+	Total      int64                   `json:"total"`
+}
+
+func toWebhookDetail(webhook models.Webhook) WebhookDetail {
+	events := webhook.GetEvents()
+	detail := WebhookDetail{
+		ID:        webhook.ID,
+		ProjectID: webhook.ProjectID,
+		URL:       webhook.URL,
+		Events:    make([]string, len(events)),
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+	for i, event := range events {
+		detail.Events[i] = string(event)
+	}
+	return detail
+}
+
+func toWebhookDeliveryDetail(delivery models.WebhookDelivery) WebhookDeliveryDetail {
+	return WebhookDeliveryDetail{
+		ID:             delivery.ID,
+		Event:          string(delivery.Event),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		Payload:        delivery.Payload,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+// parseWebhookRequest validates the URL scheme and event names of a webhook, writing a 400 response when they are invalid
+func parseWebhookRequest(c *gin.Context, rawURL string, names []string) ([]models.WebhookEvent, bool) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Webhook URL must be an http or https URL"})
+		return nil, false
+	}
+
+	events := make([]models.WebhookEvent, 0, len(names))
+	for _, name := range names {
+		event := models.WebhookEvent(name)
+		if !slices.Contains(models.WebhookEvents, event) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Unknown webhook event: " + name})
+			return nil, false
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
+
+// findWebhook loads a webhook of a project from the "webhookId" URL parameter, writing a 404 response when it does not exist
+func findWebhook(c *gin.Context, projectID uint) (models.Webhook, bool) {
+	var webhook models.Webhook
+	if err := database.DB.Where("id = ? AND project_id = ?", c.Param("webhookId"), projectID).First(&webhook).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Webhook not found"})
+		return webhook, false
+	}
+	return webhook, true
+}
+
+// ListWebhooks godoc
+// @Summary      List project webhooks
+// @Description  Lists the webhooks registered on a project. Requires the manage_webhooks capability.
+// @Tags         Project Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} WebhookListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/webhooks [get]
+func ListWebhooks(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageWebhooks, "Your role doesn't allow managing webhooks")
+	if !ok {
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := database.DB.Where("project_id = ?", project.ID).Order("id").Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch webhooks"})
+		return
+	}
+
+	response := make([]WebhookDetail, len(webhooks))
+	for i, webhook := range webhooks {
+		response[i] = toWebhookDetail(webhook)
+	}
+
+	c.JSON(http.StatusOK, WebhookListResponse{Webhooks: response})
+}
+
+// CreateWebhook godoc
+// @Summary      Register a project webhook
+// @Description  Registers a URL that receives a signed JSON POST for each subscribed event (collaborator.joined, project.status_changed, task.created).
+// @Description  Each request carries an X-Grid-Signature-256 header with the HMAC-SHA256 of the body, keyed with the secret returned here.
+// @Tags         Project Webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body WebhookCreationRequest true "Webhook URL and events"
+// @Success      201 {object} WebhookCreationResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var request WebhookCreationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageWebhooks, "Your role doesn't allow managing webhooks")
+	if !ok {
+		return
+	}
+	events, ok := parseWebhookRequest(c, request.URL, request.Events)
+	if !ok {
+		return
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to generate webhook secret"})
+		return
+	}
+
+	webhook := models.Webhook{
+		ProjectID: project.ID,
+		CreatorID: utils.InferUserID(c),
+		URL:       request.URL,
+		Secret:    secret,
+		Active:    true,
+	}
+	webhook.SetEvents(events)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&webhook).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditWebhookCreated, TargetType: "webhook", TargetID: webhook.ID,
+			ProjectID: auditProject(project.ID), After: toWebhookDetail(webhook),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookCreationResponse{WebhookDetail: toWebhookDetail(webhook), Secret: secret})
+}
+
+// EditWebhook godoc
+// @Summary      Edit a project webhook
+// @Description  Changes the URL, events or active state of a webhook. Inactive webhooks receive no new deliveries.
+// @Tags         Project Webhooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        webhookId path int true "Webhook ID"
+// @Param        request body WebhookEditRequest true "Webhook attributes"
+// @Success      200 {object} WebhookDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/webhooks/{webhookId} [put]
+func EditWebhook(c *gin.Context) {
+	var request WebhookEditRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageWebhooks, "Your role doesn't allow managing webhooks")
+	if !ok {
+		return
+	}
+	webhook, ok := findWebhook(c, project.ID)
+	if !ok {
+		return
+	}
+	events, ok := parseWebhookRequest(c, request.URL, request.Events)
+	if !ok {
+		return
+	}
+
+	before := toWebhookDetail(webhook)
+	webhook.URL = request.URL
+	webhook.Active = *request.Active
+	webhook.SetEvents(events)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&webhook).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditWebhookUpdated, TargetType: "webhook", TargetID: webhook.ID,
+			ProjectID: auditProject(project.ID), Before: before, After: toWebhookDetail(webhook),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toWebhookDetail(webhook))
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a project webhook
+// @Description  Removes a webhook. Deliveries still queued for it are abandoned.
+// @Tags         Project Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        webhookId path int true "Webhook ID"
+// @Success      200 {object} MessageResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/webhooks/{webhookId} [delete]
+func DeleteWebhook(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageWebhooks, "Your role doesn't allow managing webhooks")
+	if !ok {
+		return
+	}
+	webhook, ok := findWebhook(c, project.ID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&webhook).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditWebhookDeleted, TargetType: "webhook", TargetID: webhook.ID,
+			ProjectID: auditProject(project.ID), Before: toWebhookDetail(webhook),
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "Webhook deleted successfully"})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  Lists the deliveries of a webhook, newest first, with the outcome of their latest attempt.
+// @Tags         Project Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        webhookId path int true "Webhook ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Deliveries per page (max 100)" default(20)
+// @Success      200 {object} WebhookDeliveryListResponse
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/webhooks/{webhookId}/deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageWebhooks, "Your role doesn't allow managing webhooks")
+	if !ok {
+		return
+	}
+	webhook, ok := findWebhook(c, project.ID)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhook.ID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch deliveries"})
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch deliveries"})
+		return
+	}
+
+	response := WebhookDeliveryListResponse{
+		Deliveries: make([]WebhookDeliveryDetail, len(deliveries)),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+	}
+	for i, delivery := range deliveries {
+		response.Deliveries[i] = toWebhookDeliveryDetail(delivery)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RedeliverWebhook godoc
+// @Summary      Redeliver a webhook event
+// @Description  Queues a new delivery of the same payload. The new delivery is attempted within a few seconds.
+// @Tags         Project Webhooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        webhookId path int true "Webhook ID"
+// @Param        deliveryId path int true "Delivery ID"
+// @Success      202 {object} WebhookDeliveryDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhook(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityManageWebhooks, "Your role doesn't allow managing webhooks")
+	if !ok {
+		return
+	}
+	webhook, ok := findWebhook(c, project.ID)
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := database.DB.Where("id = ? AND webhook_id = ?", c.Param("deliveryId"), webhook.ID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Delivery not found"})
+		return
+	}
+
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditWebhookRedelivered, TargetType: "webhook", TargetID: webhook.ID,
+			ProjectID: auditProject(project.ID), After: gin.H{"delivery_id": delivery.ID, "original_delivery_id": original.ID, "event": delivery.Event},
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to queue delivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, toWebhookDeliveryDetail(delivery))
+}
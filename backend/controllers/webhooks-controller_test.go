@@ -0,0 +1,119 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+func setupWebhooksTest(t *testing.T) (*gin.Engine, models.Project, string, string) {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.Milestone{}, &models.Task{})
+	database.DB.Exec("DELETE FROM tasks")
+
+	owner := models.User{Email: "webhooks_owner@example.com", Password: "password"}
+	programmer := models.User{Email: "webhooks_programmer@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&programmer)
+	project := models.Project{Title: "Webhooks Project", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: programmer.ID, Role: "programmer"})
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	programmerToken, _ := utils.GenerateJWT(programmer.ID, programmer.Email)
+
+	router := gin.Default()
+	router.PUT("/projects/:id/status", middleware.AuthRequired(), controllers.UpdateProjectStatus)
+	router.POST("/projects/:id/tasks", middleware.AuthRequired(), controllers.CreateTask)
+	router.GET("/projects/:id/webhooks", middleware.AuthRequired(), controllers.ListWebhooks)
+	router.POST("/projects/:id/webhooks", middleware.AuthRequired(), controllers.CreateWebhook)
+	router.PUT("/projects/:id/webhooks/:webhookId", middleware.AuthRequired(), controllers.EditWebhook)
+	router.DELETE("/projects/:id/webhooks/:webhookId", middleware.AuthRequired(), controllers.DeleteWebhook)
+	router.GET("/projects/:id/webhooks/:webhookId/deliveries", middleware.AuthRequired(), controllers.ListWebhookDeliveries)
+	router.POST("/projects/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", middleware.AuthRequired(), controllers.RedeliverWebhook)
+	return router, project, ownerToken, programmerToken
+}
+
+func TestWebhookManagement(t *testing.T) {
+	router, project, ownerToken, programmerToken := setupWebhooksTest(t)
+	webhooksPath := fmt.Sprintf("/projects/%d/webhooks", project.ID)
+
+	// only owners manage webhooks
+	w := projectRequest(router, "POST", webhooksPath, programmerToken, map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"task.created"}})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = projectRequest(router, "POST", webhooksPath, ownerToken, map[string]interface{}{"url": "https://hooks.example.com", "events": []string{"task.deleted"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = projectRequest(router, "POST", webhooksPath, ownerToken, map[string]interface{}{"url": "ftp://hooks.example.com", "events": []string{"task.created"}})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = projectRequest(router, "POST", webhooksPath, ownerToken, map[string]interface{}{
+		"url":    "https://hooks.example.com/grid",
+		"events": []string{"task.created", "project.status_changed", "task.created"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created controllers.WebhookCreationResponse
+	json.Unmarshal(w.Body.Bytes(), &created)
+	assert.Len(t, created.Secret, 64)
+	assert.Equal(t, []string{"task.created", "project.status_changed"}, created.Events)
+
+	// the secret is never listed again
+	w = projectRequest(router, "GET", webhooksPath, ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), created.Secret)
+
+	// subscribed events are queued
+	w = projectRequest(router, "POST", fmt.Sprintf("/projects/%d/tasks", project.ID), programmerToken, map[string]interface{}{"title": "Review"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = projectRequest(router, "PUT", fmt.Sprintf("/projects/%d/status", project.ID), ownerToken, map[string]string{"status": "in-progress"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "PUT", fmt.Sprintf("/projects/%d/status", project.ID), programmerToken, map[string]string{"status": "completed"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	deliveriesPath := fmt.Sprintf("%s/%d/deliveries", webhooksPath, created.ID)
+	w = projectRequest(router, "GET", deliveriesPath, ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var deliveries controllers.WebhookDeliveryListResponse
+	json.Unmarshal(w.Body.Bytes(), &deliveries)
+	assert.Equal(t, int64(2), deliveries.Total)
+	assert.Equal(t, "project.status_changed", deliveries.Deliveries[0].Event)
+	assert.Contains(t, deliveries.Deliveries[0].Payload, `"previous_status":"open"`)
+	assert.Equal(t, "task.created", deliveries.Deliveries[1].Event)
+	assert.Equal(t, "pending", deliveries.Deliveries[1].Status)
+
+	// redelivering queues a copy
+	w = projectRequest(router, "POST", fmt.Sprintf("%s/%d/redeliver", deliveriesPath, deliveries.Deliveries[1].ID), ownerToken, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var redelivery controllers.WebhookDeliveryDetail
+	json.Unmarshal(w.Body.Bytes(), &redelivery)
+	assert.NotEqual(t, deliveries.Deliveries[1].ID, redelivery.ID)
+	assert.Equal(t, deliveries.Deliveries[1].Payload, redelivery.Payload)
+
+	// inactive webhooks receive nothing new
+	w = projectRequest(router, "PUT", fmt.Sprintf("%s/%d", webhooksPath, created.ID), ownerToken, map[string]interface{}{
+		"url":    "https://hooks.example.com/grid",
+		"events": []string{"task.created"},
+		"active": false,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	projectRequest(router, "POST", fmt.Sprintf("/projects/%d/tasks", project.ID), programmerToken, map[string]interface{}{"title": "Ignored"})
+
+	var count int64
+	database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", created.ID).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	w = projectRequest(router, "DELETE", fmt.Sprintf("%s/%d", webhooksPath, created.ID), ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = projectRequest(router, "GET", deliveriesPath, ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
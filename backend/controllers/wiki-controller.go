@@ -0,0 +1,605 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/database"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WikiPageSummary struct {
+	Slug      string    `json:"slug" example:"getting-started"`
+	Title     string    `json:"title" example:"Getting Started"`
+	Revision  int       `json:"revision" example:"3"`
+	AuthorID  uint      `json:"author_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WikiPageListResponse struct {
+	Pages []WikiPageSummary `json:"pages"`
+}
+
+type WikiPageDetail struct {
+	Slug      string    `json:"slug" example:"getting-started"`
+	Title     string    `json:"title" example:"Getting Started"`
+	Content   string    `json:"content" example:"# Setup\nRun the *pipeline*."`
+	HTML      string    `json:"html" example:"<h1>Setup</h1>\n<p>Run the <em>pipeline</em>.</p>"`
+	Revision  int       `json:"revision" example:"3"`
+	AuthorID  uint      `json:"author_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WikiRevisionDetail struct {
+	Revision    int       `json:"revision" example:"2"`
+	Title       string    `json:"title"`
+	Content     string    `json:"content,omitempty"`
+	HTML        string    `json:"html,omitempty"`
+	Summary     string    `json:"summary" example:"Fix typo"`
+	AuthorID    uint      `json:"author_id"`
+	AuthorEmail string    `json:"author_email"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WikiRevisionListResponse struct {
+	Revisions []WikiRevisionDetail `json:"revisions"`
+}
+
+type WikiDiffResponse struct {
+	From      int              `json:"from" example:"1"`
+	To        int              `json:"to" example:"2"`
+	Additions int              `json:"additions"`
+	Deletions int              `json:"deletions"`
+	Lines     []utils.DiffLine `json:"lines"`
+}
+
+type WikiPageCreationRequest struct {
+	Title   string `json:"title" binding:"required,max=200"`
+	Slug    string `json:"slug" binding:"max=100"`
+	Content string `json:"content" binding:"max=100000"`
+}
+
+type WikiPageEditRequest struct {
+	Title        string `json:"title" binding:"required,max=200"`
+	Content      string `json:"content" binding:"max=100000"`
+	Summary      string `json:"summary" binding:"max=300"`
+	BaseRevision int    `json:"base_revision" binding:"required"`
+}
+
+type WikiRevisionRestoreRequest struct {
+	BaseRevision int `json:"base_revision" binding:"required"`
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify converts a page title into a URL friendly identifier
+func slugify(title string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
+
+var (
+	// errStaleRevision signals that a page was modified since the client loaded it
+	errStaleRevision = errors.New("stale revision")
+	errSlugTaken     = errors.New("slug already taken")
+)
+
+// saveWikiRevision applies a new revision to a page if it is still at baseRevision.
+// The conditional update guarantees that concurrent edits based on the same revision cannot both succeed.
+// The audit event is recorded in the same transaction.
+func saveWikiRevision(c *gin.Context, page *models.WikiPage, baseRevision int, title, content, summary string, authorID uint, audit services.AuditEntry) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.WikiPage{}).
+			Where("id = ? AND revision = ?", page.ID, baseRevision).
+			Updates(map[string]interface{}{
+				"title":     title,
+				"content":   content,
+				"revision":  baseRevision + 1,
+				"author_id": authorID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStaleRevision
+		}
+
+		revision := models.WikiRevision{
+			PageID:   page.ID,
+			Revision: baseRevision + 1,
+			Title:    title,
+			Content:  content,
+			AuthorID: authorID,
+			Summary:  summary,
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(c, tx, audit); err != nil {
+			return err
+		}
+		return tx.First(page, page.ID).Error
+	})
+}
+
+// wikiAuditSnapshot describes a page in the audit log, the content itself is kept in the revision history
+func wikiAuditSnapshot(page models.WikiPage) gin.H {
+	return gin.H{"slug": page.Slug, "title": page.Title, "revision": page.Revision}
+}
+
+// findWikiPage loads a page of a project by slug, writing a 404 response when it does not exist
+func findWikiPage(c *gin.Context, projectID uint) (models.WikiPage, bool) {
+	var page models.WikiPage
+	if err := database.DB.Where("project_id = ? AND slug = ?", projectID, c.Param("slug")).First(&page).Error; err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Wiki page not found"})
+		return page, false
+	}
+	return page, true
+}
+
+// findWikiRevision loads a single revision of a page, writing a 404 response when it does not exist
+func findWikiRevision(c *gin.Context, pageID uint, revisionParam string) (models.WikiRevision, bool) {
+	var revision models.WikiRevision
+	number, err := strconv.Atoi(revisionParam)
+	if err == nil {
+		err = database.DB.Preload("Author").Where("page_id = ? AND revision = ?", pageID, number).First(&revision).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Revision not found"})
+		return revision, false
+	}
+	return revision, true
+}
+
+// respondWithWikiPage renders a page to sanitized HTML and writes it with its revision as ETag
+func respondWithWikiPage(c *gin.Context, status int, page models.WikiPage) {
+	html, err := utils.RenderMarkdown(page.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render wiki page"})
+		return
+	}
+
+	c.Header("ETag", fmt.Sprintf(`"%d"`, page.Revision))
+	c.JSON(status, WikiPageDetail{
+		Slug:      page.Slug,
+		Title:     page.Title,
+		Content:   page.Content,
+		HTML:      html,
+		Revision:  page.Revision,
+		AuthorID:  page.AuthorID,
+		UpdatedAt: page.UpdatedAt,
+	})
+}
+
+// respondWithStaleRevision reports an edit conflict together with the page's current revision
+func respondWithStaleRevision(c *gin.Context, pageID uint) {
+	var current models.WikiPage
+	database.DB.First(&current, pageID)
+	c.JSON(http.StatusConflict, ErrorResponse{
+		Error: fmt.Sprintf("The page was modified by someone else, current revision is %d", current.Revision),
+	})
+}
+
+// ListWikiPages godoc
+// @Summary      List project wiki pages
+// @Description  Lists all wiki pages of a project. Only collaborators can read the wiki.
+// @Tags         Project Wiki
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Success      200 {object} WikiPageListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki [get]
+func ListWikiPages(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can read the project wiki")
+	if !ok {
+		return
+	}
+
+	var pages []models.WikiPage
+	if err := database.DB.Where("project_id = ?", project.ID).Order("title").Find(&pages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch wiki pages"})
+		return
+	}
+
+	response := make([]WikiPageSummary, len(pages))
+	for i, page := range pages {
+		response[i] = WikiPageSummary{
+			Slug:      page.Slug,
+			Title:     page.Title,
+			Revision:  page.Revision,
+			AuthorID:  page.AuthorID,
+			UpdatedAt: page.UpdatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, WikiPageListResponse{Pages: response})
+}
+
+// CreateWikiPage godoc
+// @Summary      Create a wiki page
+// @Description  Creates a wiki page from markdown content. The slug is derived from the title unless given.
+// @Tags         Project Wiki
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        request body WikiPageCreationRequest true "Page content"
+// @Success      201 {object} WikiPageDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki [post]
+func CreateWikiPage(c *gin.Context) {
+	var request WikiPageCreationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityEditWiki, "Your role doesn't allow editing the wiki")
+	if !ok {
+		return
+	}
+	userID := utils.InferUserID(c)
+
+	slug := slugify(request.Slug)
+	if slug == "" {
+		slug = slugify(request.Title)
+	}
+	if slug == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Page title must contain letters or digits"})
+		return
+	}
+
+	page := models.WikiPage{
+		ProjectID: project.ID,
+		Slug:      slug,
+		Title:     request.Title,
+		Content:   request.Content,
+		Revision:  1,
+		AuthorID:  userID,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.WikiPage{}).Where("project_id = ? AND slug = ?", project.ID, slug).Count(&existing)
+		if existing > 0 {
+			return errSlugTaken
+		}
+		if err := tx.Create(&page).Error; err != nil {
+			return err
+		}
+		err := tx.Create(&models.WikiRevision{
+			PageID:   page.ID,
+			Revision: 1,
+			Title:    page.Title,
+			Content:  page.Content,
+			AuthorID: userID,
+			Summary:  "Page created",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(c, tx, services.AuditEntry{
+			Action: models.AuditWikiPageCreated, TargetType: "wiki_page", TargetID: page.ID,
+			ProjectID: auditProject(project.ID), After: wikiAuditSnapshot(page),
+		})
+	})
+	if errors.Is(err, errSlugTaken) {
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "A wiki page with this slug already exists"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create wiki page"})
+		return
+	}
+
+	respondWithWikiPage(c, http.StatusCreated, page)
+}
+
+// RetrieveWikiPage godoc
+// @Summary      Get a wiki page
+// @Description  Returns the current revision of a wiki page as markdown and sanitized HTML. The ETag header holds the revision number.
+// @Tags         Project Wiki
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        slug path string true "Page slug"
+// @Success      200 {object} WikiPageDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki/{slug} [get]
+func RetrieveWikiPage(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can read the project wiki")
+	if !ok {
+		return
+	}
+	page, ok := findWikiPage(c, project.ID)
+	if !ok {
+		return
+	}
+
+	respondWithWikiPage(c, http.StatusOK, page)
+}
+
+// EditWikiPage godoc
+// @Summary      Edit a wiki page
+// @Description  Saves a new revision of a wiki page. base_revision must be the revision the edit is based on; if someone else saved in the meantime the request fails with 409 and nothing is overwritten.
+// @Tags         Project Wiki
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        slug path string true "Page slug"
+// @Param        request body WikiPageEditRequest true "New page content"
+// @Success      200 {object} WikiPageDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse "Conflict - The page was edited concurrently"
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki/{slug} [put]
+func EditWikiPage(c *gin.Context) {
+	var request WikiPageEditRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityEditWiki, "Your role doesn't allow editing the wiki")
+	if !ok {
+		return
+	}
+	page, ok := findWikiPage(c, project.ID)
+	if !ok {
+		return
+	}
+
+	edited := page
+	edited.Title, edited.Revision = request.Title, request.BaseRevision+1
+	err := saveWikiRevision(c, &page, request.BaseRevision, request.Title, request.Content, request.Summary, utils.InferUserID(c), services.AuditEntry{
+		Action: models.AuditWikiPageEdited, TargetType: "wiki_page", TargetID: page.ID,
+		ProjectID: auditProject(project.ID), Before: wikiAuditSnapshot(page), After: wikiAuditSnapshot(edited),
+	})
+	if errors.Is(err, errStaleRevision) {
+		respondWithStaleRevision(c, page.ID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save wiki page"})
+		return
+	}
+
+	respondWithWikiPage(c, http.StatusOK, page)
+}
+
+// ListWikiRevisions godoc
+// @Summary      List wiki page revisions
+// @Description  Lists the revision history of a wiki page, newest first.
+// @Tags         Project Wiki
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        slug path string true "Page slug"
+// @Success      200 {object} WikiRevisionListResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki/{slug}/revisions [get]
+func ListWikiRevisions(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can read the project wiki")
+	if !ok {
+		return
+	}
+	page, ok := findWikiPage(c, project.ID)
+	if !ok {
+		return
+	}
+
+	var revisions []models.WikiRevision
+	if err := database.DB.Preload("Author").Where("page_id = ?", page.ID).Order("revision DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to fetch revisions"})
+		return
+	}
+
+	response := make([]WikiRevisionDetail, len(revisions))
+	for i, revision := range revisions {
+		response[i] = WikiRevisionDetail{
+			Revision:    revision.Revision,
+			Title:       revision.Title,
+			Summary:     revision.Summary,
+			AuthorID:    revision.AuthorID,
+			AuthorEmail: revision.Author.Email,
+			CreatedAt:   revision.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, WikiRevisionListResponse{Revisions: response})
+}
+
+// RetrieveWikiRevision godoc
+// @Summary      Get a wiki page revision
+// @Description  Returns the content of a past revision of a wiki page.
+// @Tags         Project Wiki
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        slug path string true "Page slug"
+// @Param        revision path int true "Revision number"
+// @Success      200 {object} WikiRevisionDetail
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki/{slug}/revisions/{revision} [get]
+func RetrieveWikiRevision(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can read the project wiki")
+	if !ok {
+		return
+	}
+	page, ok := findWikiPage(c, project.ID)
+	if !ok {
+		return
+	}
+	revision, ok := findWikiRevision(c, page.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	html, err := utils.RenderMarkdown(revision.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to render wiki page"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WikiRevisionDetail{
+		Revision:    revision.Revision,
+		Title:       revision.Title,
+		Content:     revision.Content,
+		HTML:        html,
+		Summary:     revision.Summary,
+		AuthorID:    revision.AuthorID,
+		AuthorEmail: revision.Author.Email,
+		CreatedAt:   revision.CreatedAt,
+	})
+}
+
+// DiffWikiRevisions godoc
+// @Summary      Compare wiki page revisions
+// @Description  Returns a line-based diff between two revisions of a wiki page. Defaults to comparing the previous revision with the current one, or an empty page with the first revision. Revisions that differ too much can't be compared.
+// @Tags         Project Wiki
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        slug path string true "Page slug"
+// @Param        from query int false "Older revision number"
+// @Param        to query int false "Newer revision number"
+// @Success      200 {object} WikiDiffResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      422 {object} ErrorResponse
+// @Router       /projects/{id}/wiki/{slug}/diff [get]
+func DiffWikiRevisions(c *gin.Context) {
+	project, _, ok := requireProjectCapability(c, models.CapabilityView, "Only collaborators can read the project wiki")
+	if !ok {
+		return
+	}
+	page, ok := findWikiPage(c, project.ID)
+	if !ok {
+		return
+	}
+
+	to := c.DefaultQuery("to", strconv.Itoa(page.Revision))
+	toRevision, ok := findWikiRevision(c, page.ID, to)
+	if !ok {
+		return
+	}
+	// the first revision has no previous one and is compared with an empty page, reported as revision 0
+	var fromRevision models.WikiRevision
+	if from, given := c.GetQuery("from"); given || toRevision.Revision > 1 {
+		if !given {
+			from = strconv.Itoa(toRevision.Revision - 1)
+		}
+		if fromRevision, ok = findWikiRevision(c, page.ID, from); !ok {
+			return
+		}
+	}
+
+	lines, err := utils.DiffLines(fromRevision.Content, toRevision.Content)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, ErrorResponse{Error: fmt.Sprintf("Revisions that differ by more than %d lines can't be compared", utils.MaxDiffChanges)})
+		return
+	}
+	response := WikiDiffResponse{From: fromRevision.Revision, To: toRevision.Revision, Lines: lines}
+	for _, line := range lines {
+		switch line.Op {
+		case utils.DiffInsert:
+			response.Additions++
+		case utils.DiffDelete:
+			response.Deletions++
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreWikiRevision godoc
+// @Summary      Restore a wiki page revision
+// @Description  Restores the content of an old revision by saving it as a new revision. base_revision must match the page's current revision.
+// @Tags         Project Wiki
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path int true "Project ID"
+// @Param        slug path string true "Page slug"
+// @Param        revision path int true "Revision number to restore"
+// @Param        request body WikiRevisionRestoreRequest true "Current revision of the page"
+// @Success      200 {object} WikiPageDetail
+// @Failure      400 {object} ErrorResponse
+// @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
+// @Failure      409 {object} ErrorResponse "Conflict - The page was edited concurrently"
+// @Failure      500 {object} ErrorResponse
+// @Router       /projects/{id}/wiki/{slug}/revisions/{revision}/restore [post]
+func RestoreWikiRevision(c *gin.Context) {
+	var request WikiRevisionRestoreRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	project, _, ok := requireProjectCapability(c, models.CapabilityEditWiki, "Your role doesn't allow editing the wiki")
+	if !ok {
+		return
+	}
+	page, ok := findWikiPage(c, project.ID)
+	if !ok {
+		return
+	}
+	revision, ok := findWikiRevision(c, page.ID, c.Param("revision"))
+	if !ok {
+		return
+	}
+
+	summary := fmt.Sprintf("Restored revision %d", revision.Revision)
+	restored := page
+	restored.Title, restored.Revision = revision.Title, request.BaseRevision+1
+	after := wikiAuditSnapshot(restored)
+	after["restored_revision"] = revision.Revision
+	err := saveWikiRevision(c, &page, request.BaseRevision, revision.Title, revision.Content, summary, utils.InferUserID(c), services.AuditEntry{
+		Action: models.AuditWikiPageRestored, TargetType: "wiki_page", TargetID: page.ID,
+		ProjectID: auditProject(project.ID), Before: wikiAuditSnapshot(page), After: after,
+	})
+	if errors.Is(err, errStaleRevision) {
+		respondWithStaleRevision(c, page.ID)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to restore revision"})
+		return
+	}
+
+	respondWithWikiPage(c, http.StatusOK, page)
+}
@@ -0,0 +1,182 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"backend/controllers"
+	"backend/database"
+	"backend/middleware"
+	"backend/models"
+	"backend/utils"
+)
+
+type wikiFixture struct {
+	router        *gin.Engine
+	project       models.Project
+	ownerToken    string
+	editorToken   string
+	outsiderToken string
+}
+
+func setupWikiTest(t *testing.T) wikiFixture {
+	setupProjectsTest(t)
+	database.DB.AutoMigrate(&models.WikiPage{}, &models.WikiRevision{})
+	database.DB.Exec("DELETE FROM wiki_revisions")
+	database.DB.Exec("DELETE FROM wiki_pages")
+
+	owner := models.User{Email: "wiki_owner@example.com", Password: "password"}
+	editor := models.User{Email: "wiki_editor@example.com", Password: "password"}
+	outsider := models.User{Email: "wiki_outsider@example.com", Password: "password"}
+	database.DB.Create(&owner)
+	database.DB.Create(&editor)
+	database.DB.Create(&outsider)
+
+	project := models.Project{Title: "Wiki Project", OwnerID: owner.ID, Visibility: "private", Status: "open"}
+	database.DB.Create(&project)
+	database.DB.Create(&models.Collaborator{ProjectID: project.ID, UserID: editor.ID, Role: "editor"})
+
+	ownerToken, _ := utils.GenerateJWT(owner.ID, owner.Email)
+	editorToken, _ := utils.GenerateJWT(editor.ID, editor.Email)
+	outsiderToken, _ := utils.GenerateJWT(outsider.ID, outsider.Email)
+
+	router := gin.Default()
+	router.GET("/projects/:id/wiki", middleware.AuthRequired(), controllers.ListWikiPages)
+	router.POST("/projects/:id/wiki", middleware.AuthRequired(), controllers.CreateWikiPage)
+	router.GET("/projects/:id/wiki/:slug", middleware.AuthRequired(), controllers.RetrieveWikiPage)
+	router.PUT("/projects/:id/wiki/:slug", middleware.AuthRequired(), controllers.EditWikiPage)
+	router.GET("/projects/:id/wiki/:slug/diff", middleware.AuthRequired(), controllers.DiffWikiRevisions)
+	router.GET("/projects/:id/wiki/:slug/revisions", middleware.AuthRequired(), controllers.ListWikiRevisions)
+	router.GET("/projects/:id/wiki/:slug/revisions/:revision", middleware.AuthRequired(), controllers.RetrieveWikiRevision)
+	router.POST("/projects/:id/wiki/:slug/revisions/:revision/restore", middleware.AuthRequired(), controllers.RestoreWikiRevision)
+
+	return wikiFixture{router, project, ownerToken, editorToken, outsiderToken}
+}
+
+func wikiRequest(f wikiFixture, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, fmt.Sprintf("/projects/%d/wiki%s", f.project.ID, path), reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestWikiPageEditingAndHistory(t *testing.T) {
+	f := setupWikiTest(t)
+
+	// create page
+	w := wikiRequest(f, "POST", "", f.ownerToken, map[string]interface{}{
+		"title":   "Getting Started",
+		"content": "# Setup\n\nInstall Go.\n<script>alert(1)</script>",
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var page controllers.WikiPageDetail
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, "getting-started", page.Slug)
+	assert.Equal(t, 1, page.Revision)
+	assert.Contains(t, page.HTML, "<h1>Setup</h1>")
+	assert.NotContains(t, page.HTML, "<script>")
+
+	// duplicate slug is rejected
+	w = wikiRequest(f, "POST", "", f.editorToken, map[string]interface{}{"title": "Getting started"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// editor saves revision 2
+	w = wikiRequest(f, "PUT", "/getting-started", f.editorToken, map[string]interface{}{
+		"title":         "Getting Started",
+		"content":       "# Setup\n\nInstall Go 1.23.",
+		"summary":       "Pin Go version",
+		"base_revision": 1,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 2, page.Revision)
+
+	// a concurrent edit based on revision 1 is rejected instead of overwriting revision 2
+	w = wikiRequest(f, "PUT", "/getting-started", f.ownerToken, map[string]interface{}{
+		"title":         "Getting Started",
+		"content":       "lost update",
+		"base_revision": 1,
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "current revision is 2")
+
+	// revision history
+	w = wikiRequest(f, "GET", "/getting-started/revisions", f.ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history controllers.WikiRevisionListResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	assert.Len(t, history.Revisions, 2)
+	assert.Equal(t, "Pin Go version", history.Revisions[0].Summary)
+	assert.Equal(t, "wiki_editor@example.com", history.Revisions[0].AuthorEmail)
+
+	// diff between revisions
+	w = wikiRequest(f, "GET", "/getting-started/diff?from=1&to=2", f.ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var diff controllers.WikiDiffResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, 1, diff.Additions)
+	assert.Equal(t, 2, diff.Deletions)
+
+	// restore revision 1 as revision 3
+	w = wikiRequest(f, "POST", "/getting-started/revisions/1/restore", f.ownerToken, map[string]interface{}{"base_revision": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 3, page.Revision)
+	assert.Contains(t, page.Content, "Install Go.")
+
+	// list pages
+	w = wikiRequest(f, "GET", "", f.editorToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "getting-started")
+}
+
+func TestWikiRequiresCollaborator(t *testing.T) {
+	f := setupWikiTest(t)
+
+	w := wikiRequest(f, "POST", "", f.outsiderToken, map[string]interface{}{"title": "Intruder"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = wikiRequest(f, "GET", "", f.outsiderToken, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = wikiRequest(f, "GET", "/missing", f.ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWikiDiffOfFirstRevision(t *testing.T) {
+	f := setupWikiTest(t)
+	w := wikiRequest(f, "POST", "", f.ownerToken, map[string]interface{}{"title": "Protocols", "content": "Centrifuge\nIncubate"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// a page with a single revision is compared with an empty page
+	w = wikiRequest(f, "GET", "/protocols/diff", f.ownerToken, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var diff controllers.WikiDiffResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, 0, diff.From)
+	assert.Equal(t, 1, diff.To)
+	assert.Equal(t, 2, diff.Additions)
+	assert.Equal(t, 0, diff.Deletions)
+
+	// revisions that were asked for still have to exist
+	w = wikiRequest(f, "GET", "/protocols/diff?from=0&to=1", f.ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
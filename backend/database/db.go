@@ -3,6 +3,8 @@ package database
 import (
 	"backend/models"
 	"log"
+	"os"
+	"strings"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -22,8 +24,68 @@ func InitDatabase() {
 	DB.AutoMigrate(
 		&models.User{},
 		&models.UserProfile{},
+		&models.Institution{},
+		&models.InstitutionDomain{},
+		&models.Department{},
+		&models.Session{},
+		&models.LoginAttempt{},
+		&models.Group{},
+		&models.GroupMember{},
 		&models.Project{},
 		&models.Collaborator{},
+		&models.ProjectRole{},
 		&models.Invitation{},
+		&models.Attachment{},
+		&models.WikiPage{},
+		&models.WikiRevision{},
+		&models.Milestone{},
+		&models.Task{},
+		&models.DiscussionThread{},
+		&models.Comment{},
+		&models.CommentMention{},
+		&models.CommentEdit{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.AuditEvent{},
+		&models.PasswordResetToken{},
+		&models.EmailChangeRequest{},
+		&models.Report{},
+		&models.UserBlock{},
+		&models.Conversation{},
+		&models.ConversationParticipant{},
+		&models.Message{},
 	)
+
+	promoteAdmins(os.Getenv("ADMIN_EMAILS"))
+	backfillCollaborators()
+}
+
+// collaborators added before statuses were tracked are active members who joined when they were added
+func backfillCollaborators() {
+	err := DB.Model(&models.Collaborator{}).Where("status IS NULL OR status = ''").Update("status", models.CollaboratorStatusActive).Error
+	if err == nil {
+		err = DB.Model(&models.Collaborator{}).Where("joined_at IS NULL").Update("joined_at", gorm.Expr("created_at")).Error
+	}
+	if err != nil {
+		log.Printf("Failed to backfill collaborators: %v", err)
+	}
+}
+
+// grant administrator access to the accounts listed in ADMIN_EMAILS. Only verified addresses count: anyone
+// could sign up with a listed address nobody registered yet, but only its owner can verify it.
+func promoteAdmins(emails string) {
+	var admins []string
+	for _, email := range strings.Split(emails, ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			admins = append(admins, email)
+		}
+	}
+	if len(admins) == 0 {
+		return
+	}
+	if err := DB.Model(&models.User{}).Where("email IN ? AND email_verified_at IS NOT NULL", admins).Update("is_admin", true).Error; err != nil {
+		log.Printf("Failed to promote administrators: %v", err)
+	}
 }
@@ -0,0 +1,60 @@
+package email
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// Message is a single email with plain text and HTML alternatives
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string // additional headers, e.g. List-Unsubscribe
+}
+
+// Mailer abstracts the transport used to deliver email
+type Mailer interface {
+	Send(message Message) error
+}
+
+// global mailer instance
+var Sender Mailer
+
+// initialize the mailer from environment configuration
+func InitMailer() {
+	switch os.Getenv("MAIL_BACKEND") {
+	case "smtp":
+		port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+		if err != nil {
+			log.Fatal("Invalid SMTP_PORT: ", err)
+		}
+		Sender = &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("MAIL_FROM", "The Grid <no-reply@localhost>"),
+		}
+	default:
+		Sender = LogMailer{}
+	}
+}
+
+// LogMailer writes emails to the application log instead of sending them, for local development
+type LogMailer struct{}
+
+func (LogMailer) Send(message Message) error {
+	log.Printf("email to %s: %s\n%s", message.To, message.Subject, message.Text)
+	return nil
+}
+
+// getEnv returns the value of an environment variable or a fallback
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,60 @@
+package email
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMessage(t *testing.T) {
+	data, err := buildMessage("The Grid <no-reply@example.com>", Message{
+		To:      "jane.doe@ufl.edu",
+		Subject: "Nouvelle invitation – Protein Folding",
+		Text:    "You were invited",
+		HTML:    "<p>You were invited</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<http://api.test/unsubscribe>"},
+	}, time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.doe@ufl.edu", parsed.Header.Get("To"))
+	assert.Equal(t, "<http://api.test/unsubscribe>", parsed.Header.Get("List-Unsubscribe"))
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Nouvelle invitation – Protein Folding", subject)
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(part)
+		bodies = append(bodies, part.Header.Get("Content-Type")+"|"+string(content))
+	}
+	assert.Equal(t, []string{
+		"text/plain; charset=utf-8|You were invited",
+		"text/html; charset=utf-8|<p>You were invited</p>",
+	}, bodies)
+}
+
+func TestBuildPlainTextMessage(t *testing.T) {
+	data, err := buildMessage("no-reply@example.com", Message{To: "jane.doe@ufl.edu", Subject: "Reset", Text: "Reset link"}, time.Now())
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "text/plain")
+	assert.NotContains(t, string(data), "text/html")
+}
@@ -3,8 +3,14 @@ package main
 import (
 	"backend/database"
 	_ "backend/docs"
+	"backend/email"
+	"backend/middleware"
 	"backend/routes"
+	"backend/services"
+	"backend/storage"
+	"backend/utils"
 
+	"context"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -20,8 +26,19 @@ import (
 func main() {
 	// initialize database
 	database.InitDatabase()
-	// initialize router
-	router := gin.Default()
+	// initialize file storage
+	storage.InitStorage()
+	// initialize email delivery
+	email.InitMailer()
+	emailInterval := time.Duration(utils.GetEnvInt64("NOTIFICATION_EMAIL_INTERVAL_SECONDS", 60)) * time.Second
+	go services.NewEmailWorker(database.DB, email.Sender).Run(context.Background(), emailInterval)
+	// deliver outbound webhooks
+	go services.NewWebhookWorker(database.DB).Run(context.Background(), 5*time.Second)
+	// anonymize accounts whose deletion grace period has ended
+	go services.NewAccountDeletionWorker(database.DB).Run(context.Background(), time.Hour)
+	// initialize router, logging requests without their query strings
+	router := gin.New()
+	router.Use(middleware.RequestLogger(), gin.Recovery())
 	// enable CORS
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:4200"}, // frontend hosting port
@@ -34,7 +51,13 @@ func main() {
 	// register routes
 	routes.AuthRoutes(router)
 	routes.UsersRoutes(router)
+	routes.InstitutionsRoutes(router)
+	routes.GroupsRoutes(router)
 	routes.ProjectsRoutes(router)
+	routes.NotificationsRoutes(router)
+	routes.ReportsRoutes(router)
+	routes.MessagesRoutes(router)
+	routes.AdminRoutes(router)
 	// swagger endpoint
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	// start server
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"backend/database"
+	"backend/models"
+	"backend/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminOnly restricts a route to platform administrators. It must run after AuthRequired.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authUserID := utils.InferUserID(c)
+		if authUserID == 0 {
+			c.JSON(http.StatusUnauthorized, AuthResponse{Error: "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		// check the database rather than the token so revoked admins lose access immediately
+		var user models.User
+		if err := database.DB.Select("id", "is_admin").First(&user, authUserID).Error; err != nil || !user.IsAdmin {
+			c.JSON(http.StatusForbidden, AuthResponse{Error: "Administrator access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
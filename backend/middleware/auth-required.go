@@ -1,9 +1,14 @@
 package middleware
 
 import (
+	"backend/database"
+	"backend/services"
 	"backend/utils"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,36 +21,82 @@ type AuthResponse struct {
 // AuthRequired validates JWT tokens and sets user ID in context
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, AuthResponse{Error: "Authorization header is required"})
+		if status, message := authenticate(c); status != 0 {
+			c.JSON(status, AuthResponse{Error: message})
 			c.Abort()
 			return
 		}
 
-		// Check for Bearer prefix
-		parts := strings.SplitN(authHeader, " ", 2)
-		if !(len(parts) == 2 && parts[0] == "Bearer") {
-			c.JSON(http.StatusUnauthorized, AuthResponse{Error: "Authorization header format must be Bearer {token}"})
-			c.Abort()
-			return
-		}
+		c.Next()
+	}
+}
 
-		// Parse and validate the token
-		tokenString := parts[1]
-		claims, err := utils.ParseJWT(tokenString)
-		
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, AuthResponse{Error: "Invalid or expired token"})
-			c.Abort()
-			return
+// OptionalAuth identifies the user when a valid token is sent and otherwise lets the request through anonymously,
+// for public routes that show more to signed in users
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			authenticate(c)
 		}
 
-		// Set user information in context
-		c.Set(utils.UserIDKey, claims.UserID)
-		c.Set(utils.UserEmailKey, claims.Email)
-		
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// authenticate validates the bearer token of a request and sets the user information in context.
+// On failure it returns the status and message to respond with.
+func authenticate(c *gin.Context) (int, string) {
+	// Get the Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return http.StatusUnauthorized, "Authorization header is required"
+	}
+
+	// Check for Bearer prefix
+	parts := strings.SplitN(authHeader, " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		return http.StatusUnauthorized, "Authorization header format must be Bearer {token}"
+	}
+
+	// Parse and validate the token
+	tokenString := parts[1]
+	claims, err := utils.ParseJWT(tokenString)
+	if err != nil {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+	return authenticateClaims(c, claims)
+}
+
+// authenticateClaims checks that the account and session the claims belong to can still be used and sets the user
+// information in context. On failure it returns the status and message to respond with.
+func authenticateClaims(c *gin.Context, claims *utils.Claims) (int, string) {
+	// reject tokens of accounts that were disabled or must reset their password since the token was issued
+	var issuedAt *time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = &claims.IssuedAt.Time
+	}
+	now := time.Now()
+	session, err := services.CheckTokenAccess(database.DB, claims.UserID, claims.SessionID, issuedAt, now)
+	switch {
+	case errors.Is(err, services.ErrAccountDisabled):
+		return http.StatusForbidden, "This account has been disabled"
+	case errors.Is(err, services.ErrPasswordResetRequired):
+		return http.StatusForbidden, "A password reset is required, check your email for a reset link"
+	case err != nil:
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+	if session.ID != 0 {
+		if err := services.TouchSession(database.DB, session, now); err != nil {
+			log.Println("Failed to update session:", err)
+		}
+	}
+
+	// Set user information in context
+	c.Set(utils.UserIDKey, claims.UserID)
+	c.Set(utils.UserEmailKey, claims.Email)
+	c.Set(utils.SessionIDKey, claims.SessionID)
+	if issuedAt != nil {
+		c.Set(utils.TokenIssuedAtKey, *issuedAt)
+	}
+	return 0, ""
+}
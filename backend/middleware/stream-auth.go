@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"backend/services"
+	"backend/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// StreamAuthRequired authenticates like AuthRequired, but also accepts a stream ticket in the "ticket" query
+// parameter from clients that cannot set request headers, such as the browser EventSource API
+func StreamAuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" || c.GetHeader("Authorization") != "" {
+			AuthRequired()(c)
+			return
+		}
+
+		status, message := http.StatusUnauthorized, "Invalid or expired stream ticket"
+		if userID, sessionID, issuedAt, err := services.ParseStreamTicket(ticket, time.Now()); err == nil {
+			// the ticket stands in for the token it was issued to, as of when it was issued
+			status, message = authenticateClaims(c, &utils.Claims{
+				UserID: userID, SessionID: sessionID, RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(issuedAt)},
+			})
+		}
+		if status != 0 {
+			c.JSON(status, AuthResponse{Error: message})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
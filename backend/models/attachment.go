@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// Attachment is one stored version of a file shared within a project.
+// Uploading a file with an existing filename creates a new version instead of replacing it.
+type Attachment struct {
+	gorm.Model
+	ProjectID   uint   `gorm:"not null;uniqueIndex:idx_attachment_version" json:"project_id"`
+	UploaderID  uint   `gorm:"not null" json:"uploader_id"`
+	Filename    string `gorm:"not null;uniqueIndex:idx_attachment_version" json:"filename"`
+	Version     int    `gorm:"not null;uniqueIndex:idx_attachment_version" json:"version"`
+	ContentType string `json:"content_type"`
+	Size        int64  `gorm:"not null" json:"size"`
+	Checksum    string `gorm:"not null" json:"checksum"` // hex encoded SHA-256 of the contents
+	StorageKey  string `gorm:"not null" json:"-"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditUserRegistered           AuditAction = "user.registered"
+	AuditUserBlocked              AuditAction = "user.blocked"
+	AuditUserUnblocked            AuditAction = "user.unblocked"
+	AuditUserDisabled             AuditAction = "user.disabled"
+	AuditUserEnabled              AuditAction = "user.enabled"
+	AuditPasswordResetForced      AuditAction = "user.password_reset_forced"
+	AuditPasswordReset            AuditAction = "user.password_reset"
+	AuditPasswordChanged          AuditAction = "user.password_changed"
+	AuditSessionEnded             AuditAction = "user.session_ended"
+	AuditLoginReported            AuditAction = "user.login_reported"
+	AuditEmailVerified            AuditAction = "user.email_verified"
+	AuditEmailChangeRequested     AuditAction = "user.email_change_requested"
+	AuditEmailChanged             AuditAction = "user.email_changed"
+	AuditAccountDeletionScheduled AuditAction = "user.deletion_scheduled"
+	AuditAccountDeletionCancelled AuditAction = "user.deletion_cancelled"
+	AuditAccountDeleted           AuditAction = "user.deleted"
+	AuditDataExported             AuditAction = "user.data_exported"
+	AuditProfileUpdated           AuditAction = "profile.updated"
+	AuditAvatarUploaded           AuditAction = "avatar.uploaded"
+	AuditAvatarDeleted            AuditAction = "avatar.deleted"
+	AuditNotificationPrefsUpdated AuditAction = "notification_preferences.updated"
+	AuditEmailUnsubscribed        AuditAction = "email.unsubscribed"
+	AuditNotificationRead         AuditAction = "notification.read"
+	AuditNotificationsReadAll     AuditAction = "notifications.read_all"
+	AuditProjectCreated           AuditAction = "project.created"
+	AuditProjectStatusChanged     AuditAction = "project.status_changed"
+	AuditProjectDeleted           AuditAction = "project.deleted"
+	AuditProjectTransferred       AuditAction = "project.ownership_transferred"
+	AuditProjectGroupChanged      AuditAction = "project.group_changed"
+	AuditInvitationCreated        AuditAction = "invitation.created"
+	AuditInvitationAccepted       AuditAction = "invitation.accepted"
+	AuditInvitationRejected       AuditAction = "invitation.rejected"
+	AuditCollaboratorRoleChanged  AuditAction = "collaborator.role_changed"
+	AuditCollaboratorStatusSet    AuditAction = "collaborator.status_changed"
+	AuditProjectRoleCreated       AuditAction = "project_role.created"
+	AuditProjectRoleUpdated       AuditAction = "project_role.updated"
+	AuditProjectRoleDeleted       AuditAction = "project_role.deleted"
+	AuditAttachmentUploaded       AuditAction = "attachment.uploaded"
+	AuditAttachmentDeleted        AuditAction = "attachment.deleted"
+	AuditWikiPageCreated          AuditAction = "wiki_page.created"
+	AuditWikiPageEdited           AuditAction = "wiki_page.edited"
+	AuditWikiPageRestored         AuditAction = "wiki_page.restored"
+	AuditTaskCreated              AuditAction = "task.created"
+	AuditTaskUpdated              AuditAction = "task.updated"
+	AuditTaskMoved                AuditAction = "task.moved"
+	AuditTaskDeleted              AuditAction = "task.deleted"
+	AuditMilestoneCreated         AuditAction = "milestone.created"
+	AuditMilestoneUpdated         AuditAction = "milestone.updated"
+	AuditMilestoneDeleted         AuditAction = "milestone.deleted"
+	AuditDiscussionCreated        AuditAction = "discussion.created"
+	AuditCommentCreated           AuditAction = "comment.created"
+	AuditCommentEdited            AuditAction = "comment.edited"
+	AuditCommentDeleted           AuditAction = "comment.deleted"
+	AuditWebhookCreated           AuditAction = "webhook.created"
+	AuditWebhookUpdated           AuditAction = "webhook.updated"
+	AuditWebhookDeleted           AuditAction = "webhook.deleted"
+	AuditWebhookRedelivered       AuditAction = "webhook.redelivered"
+	AuditMessagingSettingsUpdated AuditAction = "messaging_settings.updated"
+	AuditConversationCreated      AuditAction = "conversation.created"
+	AuditConversationRead         AuditAction = "conversation.read"
+	AuditConversationMuted        AuditAction = "conversation.muted"
+	AuditMessageSent              AuditAction = "message.sent"
+	AuditReportCreated            AuditAction = "report.created"
+	AuditReportResolved           AuditAction = "report.resolved"
+	AuditContentHidden            AuditAction = "content.hidden"
+	AuditInstitutionCreated       AuditAction = "institution.created"
+	AuditInstitutionUpdated       AuditAction = "institution.updated"
+	AuditInstitutionDeleted       AuditAction = "institution.deleted"
+	AuditAffiliationsNormalized   AuditAction = "institution.affiliations_normalized"
+	AuditDepartmentCreated        AuditAction = "department.created"
+	AuditDepartmentUpdated        AuditAction = "department.updated"
+	AuditDepartmentDeleted        AuditAction = "department.deleted"
+	AuditGroupCreated             AuditAction = "group.created"
+	AuditGroupUpdated             AuditAction = "group.updated"
+	AuditGroupDeleted             AuditAction = "group.deleted"
+	AuditGroupMemberAdded         AuditAction = "group.member_added"
+	AuditGroupMemberRoleChanged   AuditAction = "group.member_role_changed"
+	AuditGroupMemberRemoved       AuditAction = "group.member_removed"
+)
+
+// ErrAuditEventImmutable is returned when trying to change or remove a recorded audit event
+var ErrAuditEventImmutable = errors.New("audit events are append-only")
+
+// AuditEvent records a single mutating action. Events are never updated or deleted.
+type AuditEvent struct {
+	ID         uint        `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time   `gorm:"index" json:"created_at"`
+	ActorID    *uint       `gorm:"index" json:"actor_id"` // nil for actions without a logged in user, e.g. unsubscribe links
+	Actor      *User       `gorm:"foreignKey:ActorID" json:"-"`
+	Action     AuditAction `gorm:"not null;index" json:"action"`
+	TargetType string      `gorm:"not null" json:"target_type"`
+	TargetID   uint        `json:"target_id"`
+	ProjectID  *uint       `gorm:"index" json:"project_id"`
+	Changes    string      `gorm:"type:text" json:"changes"` // JSON object of changed fields with their before and after values
+	IP         string      `json:"ip"`
+	UserAgent  string      `json:"user_agent"`
+}
+
+func (e *AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
+
+func (e *AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditEventImmutable
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserBlock records that a user blocked another. Blocked users cannot invite, mention or message the blocker
+// and don't see the blocker's restricted profile fields.
+type UserBlock struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	BlockerID uint      `gorm:"not null;uniqueIndex:idx_user_block" json:"blocker_id"`
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_user_block;index" json:"blocked_id"`
+	Blocked   User      `gorm:"foreignKey:BlockedID" json:"-"`
+}
@@ -1,12 +1,43 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CollaboratorStatus string
+
+const (
+	CollaboratorStatusActive  CollaboratorStatus = "active"
+	CollaboratorStatusOnLeave CollaboratorStatus = "on-leave"
+	// former members keep the credit for their work but can only read the project
+	CollaboratorStatusAlumni CollaboratorStatus = "alumni"
+)
+
+// IsCurrent reports whether the collaborator is still part of the team, even if on leave
+func (s CollaboratorStatus) IsCurrent() bool {
+	return s != CollaboratorStatusAlumni
+}
 
 type Collaborator struct {
 	gorm.Model
-	ProjectID uint        `gorm:"not null;index" json:"project_id"`
-	UserID    uint        `gorm:"not null;index" json:"user_id"`
-	User      UserProfile `gorm:"foreignKey:UserID" json:"user"`
-	Role      string      `gorm:"not null" json:"role"`
-	Status    string      `json:"status"`
+	ProjectID uint               `gorm:"not null;index" json:"project_id"`
+	UserID    uint               `gorm:"not null;index" json:"user_id"`
+	User      UserProfile        `gorm:"foreignKey:UserID;references:UserID" json:"user"`
+	Role      string             `gorm:"not null" json:"role"`
+	Status    CollaboratorStatus `gorm:"not null;default:active" json:"status"`
+	JoinedAt  *time.Time         `json:"joined_at"`
+	LeftAt    *time.Time         `json:"left_at"` // set when the collaborator became alumni
+}
+
+func (c *Collaborator) BeforeCreate(tx *gorm.DB) error {
+	if c.Status == "" {
+		c.Status = CollaboratorStatusActive
+	}
+	if c.JoinedAt == nil {
+		now := time.Now()
+		c.JoinedAt = &now
+	}
+	return nil
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DiscussionThread is a conversation topic within a project.
+// The opening post is stored as the thread's first top-level comment.
+type DiscussionThread struct {
+	gorm.Model
+	ProjectID uint      `gorm:"not null;index" json:"project_id"`
+	AuthorID  uint      `gorm:"not null" json:"author_id"`
+	Author    User      `gorm:"foreignKey:AuthorID" json:"-"`
+	Title     string    `gorm:"not null" json:"title"`
+	Comments  []Comment `gorm:"foreignKey:ThreadID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// Comment is a post in a discussion thread. Replies reference their parent comment.
+type Comment struct {
+	gorm.Model
+	ThreadID uint             `gorm:"not null;index" json:"thread_id"`
+	ParentID *uint            `gorm:"index" json:"parent_id"`
+	AuthorID uint             `gorm:"not null" json:"author_id"`
+	Author   User             `gorm:"foreignKey:AuthorID" json:"-"`
+	Body     string           `gorm:"type:text;not null" json:"body"` // markdown
+	EditedAt *time.Time       `json:"edited_at"`
+	HiddenAt *time.Time       `json:"-"` // set by moderators, hidden comments are shown as removed
+	Mentions []CommentMention `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE;" json:"-"`
+	Edits    []CommentEdit    `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// CommentMention records a collaborator mentioned in a comment
+type CommentMention struct {
+	gorm.Model
+	CommentID uint `gorm:"not null;index" json:"comment_id"`
+	UserID    uint `gorm:"not null;index" json:"user_id"`
+	User      User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// CommentEdit keeps the body a comment had before an edit
+type CommentEdit struct {
+	gorm.Model
+	CommentID    uint   `gorm:"not null;index" json:"comment_id"`
+	PreviousBody string `gorm:"type:text" json:"previous_body"`
+}
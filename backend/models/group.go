@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type GroupRole string
+
+const (
+	GroupRoleMember  GroupRole = "member"
+	GroupRoleManager GroupRole = "manager"
+	GroupRolePI      GroupRole = "pi" // principal investigator
+)
+
+// ranks of group roles, higher ranks include the permissions of lower ones
+var groupRoleRanks = map[GroupRole]int{
+	GroupRoleMember:  1,
+	GroupRoleManager: 2,
+	GroupRolePI:      3,
+}
+
+// AtLeast reports whether r grants at least the permissions of other
+func (r GroupRole) AtLeast(other GroupRole) bool {
+	rank, ok := groupRoleRanks[r]
+	return ok && rank >= groupRoleRanks[other]
+}
+
+// Group is a research group or lab. Its PIs and managers manage the projects the group owns,
+// and every member collaborates on them with the group's default project role.
+type Group struct {
+	gorm.Model
+	Name          string       `gorm:"not null" json:"name"`
+	Description   string       `json:"description"`
+	InstitutionID *uint        `gorm:"index" json:"institution_id"`
+	Institution   *Institution `json:"-"`
+	// role group members hold on the group's projects unless they were given a higher one
+	DefaultProjectRole CollaboratorRole `gorm:"not null;default:programmer" json:"default_project_role"`
+	Members            []GroupMember    `gorm:"constraint:OnDelete:CASCADE" json:"members"`
+}
+
+type GroupMember struct {
+	ID        uint        `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	GroupID   uint        `gorm:"not null;uniqueIndex:idx_group_member" json:"group_id"`
+	UserID    uint        `gorm:"not null;uniqueIndex:idx_group_member;index" json:"user_id"`
+	User      UserProfile `gorm:"foreignKey:UserID;references:UserID" json:"user"`
+	Role      GroupRole   `gorm:"not null" json:"role"`
+}
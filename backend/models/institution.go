@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Institution is a university or research organization. Users whose verified email address belongs to one of
+// its domains are shown as verified members.
+type Institution struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `gorm:"not null;uniqueIndex" json:"name"` // canonical name
+	// other names the institution goes by, e.g. "UF" or "Univ. of Florida"; stored as JSON string
+	Aliases     string              `gorm:"type:text" json:"aliases"`
+	City        string              `json:"city"`
+	Region      string              `json:"region"` // state or province
+	Country     string              `json:"country"`
+	Domains     []InstitutionDomain `gorm:"constraint:OnDelete:CASCADE" json:"domains"`
+	Departments []Department        `gorm:"constraint:OnDelete:CASCADE" json:"departments"`
+}
+
+// GetAliases returns the alternative names of the institution
+func (i *Institution) GetAliases() []string {
+	return decodeAliases(i.Aliases)
+}
+
+// SetAliases stores the alternative names of the institution
+func (i *Institution) SetAliases(aliases []string) {
+	i.Aliases = encodeAliases(aliases)
+}
+
+// InstitutionDomain is an email domain of an institution. Its subdomains belong to the institution as well.
+type InstitutionDomain struct {
+	ID            uint   `gorm:"primarykey" json:"-"`
+	InstitutionID uint   `gorm:"not null;index" json:"-"`
+	Domain        string `gorm:"not null;uniqueIndex" json:"domain"`
+}
+
+// Department is a department, school or lab of an institution
+type Department struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	InstitutionID uint      `gorm:"not null;uniqueIndex:idx_department_name" json:"institution_id"`
+	Name          string    `gorm:"not null;uniqueIndex:idx_department_name" json:"name"` // canonical name
+	// other names the department goes by, e.g. "CISE"; stored as JSON string
+	Aliases  string `gorm:"type:text" json:"aliases"`
+	Location string `json:"location"` // building or campus
+}
+
+// GetAliases returns the alternative names of the department
+func (d *Department) GetAliases() []string {
+	return decodeAliases(d.Aliases)
+}
+
+// SetAliases stores the alternative names of the department
+func (d *Department) SetAliases(aliases []string) {
+	d.Aliases = encodeAliases(aliases)
+}
+
+func decodeAliases(value string) []string {
+	var aliases []string
+	if value == "" {
+		return aliases
+	}
+	if err := json.Unmarshal([]byte(value), &aliases); err != nil {
+		log.Println("Error unmarshaling Aliases:", err)
+	}
+	return aliases
+}
+
+func encodeAliases(aliases []string) string {
+	if aliases == nil {
+		aliases = []string{}
+	}
+	aliasesJSON, err := json.Marshal(aliases)
+	if err != nil {
+		log.Println("Error marshaling Aliases:", err)
+		return ""
+	}
+	return string(aliasesJSON)
+}
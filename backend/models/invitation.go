@@ -19,6 +19,19 @@ const (
 	CollaboratorRoleOwner      CollaboratorRole = "owner"
 )
 
+// ranks of collaborator roles, higher ranks include the permissions of lower ones
+var collaboratorRoleRanks = map[CollaboratorRole]int{
+	CollaboratorRoleProgrammer: 1,
+	CollaboratorRoleEditor:     2,
+	CollaboratorRoleOwner:      3,
+}
+
+// AtLeast reports whether r grants at least the permissions of other
+func (r CollaboratorRole) AtLeast(other CollaboratorRole) bool {
+	rank, ok := collaboratorRoleRanks[r]
+	return ok && rank >= collaboratorRoleRanks[other]
+}
+
 type Invitation struct {
 	gorm.Model
 	ProjectID    uint             `json:"project_id"`
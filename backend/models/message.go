@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MessagePrivacy controls who may start a conversation with a user
+type MessagePrivacy string
+
+const (
+	MessagePrivacyEveryone      MessagePrivacy = "everyone"
+	MessagePrivacyCollaborators MessagePrivacy = "collaborators" // only users sharing a project
+	MessagePrivacyNobody        MessagePrivacy = "nobody"
+)
+
+// largest number of people in a group conversation, including its creator
+const MaxConversationParticipants = 10
+
+// Conversation is a one-to-one or small group message thread
+type Conversation struct {
+	gorm.Model
+	CreatorID     uint                      `gorm:"not null" json:"creator_id"`
+	Title         string                    `json:"title"`
+	IsGroup       bool                      `gorm:"not null;default:false" json:"is_group"`
+	LastMessageAt *time.Time                `gorm:"index" json:"last_message_at"`
+	Participants  []ConversationParticipant `gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE;" json:"participants"`
+}
+
+// ConversationParticipant links a user to a conversation and tracks what they have read
+type ConversationParticipant struct {
+	gorm.Model
+	ConversationID    uint       `gorm:"not null;uniqueIndex:idx_conversation_participant" json:"conversation_id"`
+	UserID            uint       `gorm:"not null;uniqueIndex:idx_conversation_participant;index" json:"user_id"`
+	User              User       `gorm:"foreignKey:UserID" json:"-"`
+	LastReadMessageID uint       `gorm:"not null;default:0" json:"last_read_message_id"`
+	LastReadAt        *time.Time `json:"last_read_at"`
+	Muted             bool       `gorm:"not null;default:false" json:"muted"` // muted conversations don't create notifications
+}
+
+type Message struct {
+	gorm.Model
+	ConversationID uint   `gorm:"not null;index" json:"conversation_id"`
+	SenderID       uint   `gorm:"not null" json:"sender_id"`
+	Body           string `gorm:"type:text;not null" json:"body"`
+}
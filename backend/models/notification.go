@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationType string
+
+const (
+	NotificationInvitationReceived NotificationType = "invitation_received"
+	NotificationInvitationAccepted NotificationType = "invitation_accepted"
+	NotificationInvitationRejected NotificationType = "invitation_rejected"
+	NotificationRoleChanged        NotificationType = "role_changed"
+	NotificationMention            NotificationType = "mention"
+	NotificationTaskAssigned       NotificationType = "task_assigned"
+	NotificationReportResolved     NotificationType = "report_resolved"
+	NotificationMessage            NotificationType = "message"
+	NotificationGroupMembership    NotificationType = "group_membership"
+	// always emailed right away, so it has no email preference
+	NotificationUnfamiliarLogin NotificationType = "unfamiliar_login"
+)
+
+// NotificationTypes lists every notification type users can set preferences for
+var NotificationTypes = []NotificationType{
+	NotificationInvitationReceived,
+	NotificationInvitationAccepted,
+	NotificationInvitationRejected,
+	NotificationRoleChanged,
+	NotificationMention,
+	NotificationTaskAssigned,
+	NotificationReportResolved,
+	NotificationMessage,
+	NotificationGroupMembership,
+}
+
+type EmailFrequency string
+
+const (
+	EmailFrequencyImmediate EmailFrequency = "immediate"
+	EmailFrequencyDaily     EmailFrequency = "daily"
+	EmailFrequencyWeekly    EmailFrequency = "weekly"
+	EmailFrequencyOff       EmailFrequency = "off"
+)
+
+// DefaultEmailFrequency is used for notification types a user has no preference for.
+// Invitations wait on a response, so they are emailed right away; everything else goes into the daily digest.
+func DefaultEmailFrequency(notificationType NotificationType) EmailFrequency {
+	if notificationType == NotificationInvitationReceived {
+		return EmailFrequencyImmediate
+	}
+	return EmailFrequencyDaily
+}
+
+type Notification struct {
+	gorm.Model
+	UserID    uint             `gorm:"not null;index:idx_notification_user" json:"user_id"`
+	ActorID   *uint            `json:"actor_id"`
+	ProjectID *uint            `json:"project_id"`
+	Type      NotificationType `gorm:"not null" json:"type"`
+	Message   string           `gorm:"not null" json:"message"`
+	Link      string           `json:"link"` // API path of the resource the notification refers to
+	ReadAt    *time.Time       `gorm:"index" json:"read_at"`
+	EmailedAt *time.Time       `gorm:"index" json:"-"` // set once emailed, or skipped because email is turned off
+}
+
+type NotificationPreference struct {
+	gorm.Model
+	UserID    uint             `gorm:"not null;uniqueIndex:idx_notification_preference" json:"user_id"`
+	Type      NotificationType `gorm:"not null;uniqueIndex:idx_notification_preference" json:"type"`
+	Frequency EmailFrequency   `gorm:"not null" json:"frequency"`
+}
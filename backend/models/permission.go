@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+)
+
+// Capability is a single thing a collaborator may do on a project
+type Capability string
+
+const (
+	CapabilityView             Capability = "view"              // read tasks, milestones, files, the wiki, discussions and activity
+	CapabilityComment          Capability = "comment"           // start discussions and comment on them
+	CapabilityEditWiki         Capability = "edit_wiki"         // create, edit and restore wiki pages
+	CapabilityManageTasks      Capability = "manage_tasks"      // create, edit and move tasks
+	CapabilityDeleteTasks      Capability = "delete_tasks"      // delete tasks
+	CapabilityManageMilestones Capability = "manage_milestones" // create, edit and delete milestones
+	CapabilityUploadFiles      Capability = "upload_files"      // upload files and new versions of them
+	CapabilityDeleteFiles      Capability = "delete_files"      // delete files with all their versions
+	CapabilityEditMetadata     Capability = "edit_metadata"     // change the project's status
+	CapabilityInvite           Capability = "invite"            // invite collaborators
+	CapabilityManageRoles      Capability = "manage_roles"      // change collaborator roles and define custom roles
+	CapabilityManageWebhooks   Capability = "manage_webhooks"   // configure webhooks and redeliver events
+	CapabilityTransferProject  Capability = "transfer_project"  // transfer ownership or move the project into a group
+	CapabilityDeleteProject    Capability = "delete_project"    // delete the project
+)
+
+// Capabilities lists every capability, in the order they are presented
+var Capabilities = []Capability{
+	CapabilityView, CapabilityComment, CapabilityEditWiki, CapabilityManageTasks, CapabilityDeleteTasks,
+	CapabilityManageMilestones, CapabilityUploadFiles, CapabilityDeleteFiles, CapabilityEditMetadata, CapabilityInvite,
+	CapabilityManageRoles, CapabilityManageWebhooks, CapabilityTransferProject, CapabilityDeleteProject,
+}
+
+// BuiltInRoles lists the roles every project has, from the least to the most privileged
+var BuiltInRoles = []CollaboratorRole{CollaboratorRoleProgrammer, CollaboratorRoleEditor, CollaboratorRoleOwner}
+
+// capability matrix of the built-in roles
+var roleCapabilities = map[CollaboratorRole][]Capability{
+	CollaboratorRoleProgrammer: {
+		CapabilityView, CapabilityComment, CapabilityEditWiki, CapabilityManageTasks, CapabilityUploadFiles,
+	},
+	CollaboratorRoleEditor: {
+		CapabilityView, CapabilityComment, CapabilityEditWiki, CapabilityManageTasks, CapabilityDeleteTasks,
+		CapabilityManageMilestones, CapabilityUploadFiles, CapabilityDeleteFiles,
+	},
+	CollaboratorRoleOwner: Capabilities,
+}
+
+// IsBuiltIn reports whether r is one of the roles every project has, as opposed to a project's custom role
+func (r CollaboratorRole) IsBuiltIn() bool {
+	_, ok := roleCapabilities[r]
+	return ok
+}
+
+// BuiltInCapabilities returns the capabilities of a built-in role, or nil for other roles
+func (r CollaboratorRole) BuiltInCapabilities() []Capability {
+	return slices.Clone(roleCapabilities[r])
+}
+
+// IsValid reports whether c is a known capability
+func (c Capability) IsValid() bool {
+	return slices.Contains(Capabilities, c)
+}
+
+// ProjectRole is a custom collaborator role defined by a project, granting the capabilities picked for it
+type ProjectRole struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	ProjectID    uint      `gorm:"not null;uniqueIndex:idx_project_role_name" json:"project_id"`
+	Name         string    `gorm:"not null;uniqueIndex:idx_project_role_name" json:"name"`
+	Description  string    `json:"description"`
+	Capabilities string    `gorm:"type:text" json:"-"` // JSON array of capabilities
+}
+
+func (r *ProjectRole) GetCapabilities() []Capability {
+	var capabilities []Capability
+	if r.Capabilities == "" {
+		return capabilities
+	}
+	if err := json.Unmarshal([]byte(r.Capabilities), &capabilities); err != nil {
+		log.Println("Error unmarshaling Capabilities:", err)
+	}
+	return capabilities
+}
+
+func (r *ProjectRole) SetCapabilities(capabilities []Capability) {
+	if capabilities == nil {
+		capabilities = []Capability{}
+	}
+	capabilitiesJSON, err := json.Marshal(capabilities)
+	if err != nil {
+		log.Println("Error marshaling Capabilities:", err)
+		return
+	}
+	r.Capabilities = string(capabilitiesJSON)
+}
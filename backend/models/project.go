@@ -4,6 +4,12 @@ import (
 	"encoding/json"
 	"gorm.io/gorm"
 	"log"
+	"time"
+)
+
+const (
+	ProjectVisibilityPrivate = "private"
+	ProjectVisibilityPublic  = "public"
 )
 
 type Project struct {
@@ -14,7 +20,9 @@ type Project struct {
 	RequiredSkills string         `gorm:"type:text" json:"required_skills"` // store as JSON string
 	Visibility     string         `gorm:"not null" json:"visibility"`
 	Status         string         `gorm:"not null" json:"status"`
+	HiddenAt       *time.Time     `json:"-"` // set by moderators, hidden projects are left out of the public directory
 	Collaborators  []Collaborator `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE;" json:"collaborators"`
+	GroupID        *uint          `gorm:"index" json:"group_id"` // group owning the project, whose PIs and managers share the owner's permissions
 }
 
 // Convert RequiredSkills from JSON to []string when reading from DB
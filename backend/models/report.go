@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportTargetType string
+
+const (
+	ReportTargetProject ReportTargetType = "project"
+	ReportTargetProfile ReportTargetType = "profile"
+	ReportTargetComment ReportTargetType = "comment"
+)
+
+type ReportReason string
+
+const (
+	ReportReasonSpam          ReportReason = "spam"
+	ReportReasonHarassment    ReportReason = "harassment"
+	ReportReasonInappropriate ReportReason = "inappropriate"
+	ReportReasonImpersonation ReportReason = "impersonation"
+	ReportReasonOther         ReportReason = "other"
+)
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "open"
+	ReportStatusResolved ReportStatus = "resolved"
+)
+
+// ReportResolution is the action a moderator took on a report
+type ReportResolution string
+
+const (
+	ReportResolutionDismissed       ReportResolution = "dismiss"
+	ReportResolutionContentHidden   ReportResolution = "hide_content"
+	ReportResolutionAuthorSuspended ReportResolution = "suspend_author"
+)
+
+// Report flags a project, profile or comment for review by administrators
+type Report struct {
+	gorm.Model
+	ReporterID     uint             `gorm:"not null;index" json:"reporter_id"`
+	Reporter       User             `gorm:"foreignKey:ReporterID" json:"-"`
+	TargetType     ReportTargetType `gorm:"not null;index:idx_report_target" json:"target_type"`
+	TargetID       uint             `gorm:"not null;index:idx_report_target" json:"target_id"`
+	AuthorID       uint             `gorm:"not null" json:"author_id"` // owner of the reported content
+	Reason         ReportReason     `gorm:"not null" json:"reason"`
+	Details        string           `gorm:"type:text" json:"details"`
+	Status         ReportStatus     `gorm:"not null;index" json:"status"`
+	Resolution     ReportResolution `json:"resolution"`
+	ResolutionNote string           `gorm:"type:text" json:"resolution_note"`
+	ResolvedByID   *uint            `json:"resolved_by_id"`
+	ResolvedAt     *time.Time       `json:"resolved_at"`
+}
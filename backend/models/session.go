@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Session is a login from one device. Tokens issued at login carry the session ID,
+// so ending a session signs that device out.
+type Session struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	LastSeenAt time.Time  `gorm:"not null" json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	EndedAt    *time.Time `json:"ended_at"`
+}
+
+type LoginFailureReason string
+
+const (
+	LoginFailureInvalidPassword       LoginFailureReason = "invalid_password"
+	LoginFailureAccountDisabled       LoginFailureReason = "account_disabled"
+	LoginFailurePasswordResetRequired LoginFailureReason = "password_reset_required"
+)
+
+// LoginAttempt records a successful or failed login to an existing account
+type LoginAttempt struct {
+	ID            uint               `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UserID        uint               `gorm:"not null;index" json:"user_id"`
+	IPAddress     string             `json:"ip_address"`
+	UserAgent     string             `json:"user_agent"`
+	Succeeded     bool               `gorm:"not null" json:"succeeded"`
+	FailureReason LoginFailureReason `json:"failure_reason,omitempty"`
+	// set for successful logins from a device or network the account hadn't logged in from before
+	Unfamiliar bool `gorm:"not null;default:false" json:"unfamiliar"`
+	// set when the user reported the login as not being them
+	ReportedAt *time.Time `json:"reported_at"`
+}
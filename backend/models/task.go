@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"
+	TaskStatusInProgress TaskStatus = "in-progress"
+	TaskStatusReview     TaskStatus = "review"
+	TaskStatusDone       TaskStatus = "done"
+)
+
+// TaskStatuses lists the task board columns in display order
+var TaskStatuses = []TaskStatus{TaskStatusTodo, TaskStatusInProgress, TaskStatusReview, TaskStatusDone}
+
+// Milestone groups tasks of a project towards a common goal
+type Milestone struct {
+	gorm.Model
+	ProjectID   uint       `gorm:"not null;index" json:"project_id"`
+	Title       string     `gorm:"not null" json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+// Task is a unit of work on a project's task board
+type Task struct {
+	gorm.Model
+	ProjectID   uint       `gorm:"not null;index" json:"project_id"`
+	MilestoneID *uint      `gorm:"index" json:"milestone_id"`
+	Milestone   *Milestone `gorm:"foreignKey:MilestoneID;constraint:OnDelete:SET NULL;" json:"-"`
+	CreatorID   uint       `gorm:"not null" json:"creator_id"`
+	AssigneeID  *uint      `gorm:"index" json:"assignee_id"`
+	Title       string     `gorm:"not null" json:"title"`
+	Description string     `gorm:"type:text" json:"description"`
+	Status      TaskStatus `gorm:"not null" json:"status"`
+	Position    int        `gorm:"not null" json:"position"` // order within the status column
+	DueDate     *time.Time `json:"due_date"`
+	Labels      string     `gorm:"type:text" json:"labels"` // store as JSON string
+}
+
+// Convert Labels from JSON to []string when reading from DB
+func (t *Task) GetLabels() []string {
+	labels := []string{}
+	if t.Labels == "" {
+		return labels
+	}
+	if err := json.Unmarshal([]byte(t.Labels), &labels); err != nil {
+		log.Println("Error unmarshaling Labels:", err)
+	}
+	return labels
+}
+
+// Convert []string to JSON before saving Labels to DB
+func (t *Task) SetLabels(labels []string) {
+	if labels == nil {
+		labels = []string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		log.Println("Error marshaling Labels:", err)
+		return
+	}
+	t.Labels = string(labelsJSON)
+}
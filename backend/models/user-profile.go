@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type UserProfile struct {
 	gorm.Model
@@ -13,4 +17,21 @@ type UserProfile struct {
 	Projects    string `json:"projects"`
 	Location    string `json:"location" example:"Gainesville, FL"`
 	GitHub      string `json:"github" example:"https://github.com/johndoe"`
+	// storage key prefix of the current avatar thumbnails, empty when no avatar was uploaded
+	AvatarKey       string     `json:"-"`
+	AvatarUpdatedAt *time.Time `json:"-"`
+	// set by moderators, hidden profiles only show the account itself
+	HiddenAt *time.Time `json:"-"`
+	// who may start conversations with this user
+	MessagePrivacy MessagePrivacy `json:"message_privacy" gorm:"not null;default:everyone"`
+	// institution the user's verified email address belongs to; unlike Affiliation and Role it can't be self-declared
+	InstitutionID         *uint        `json:"-" gorm:"index"`
+	Institution           *Institution `json:"-"`
+	InstitutionVerifiedAt *time.Time   `json:"-"`
+	// registered institution and department the self-declared Affiliation refers to, picked by the user or
+	// matched from the affiliation text
+	AffiliationInstitutionID *uint        `json:"-" gorm:"index"`
+	AffiliationInstitution   *Institution `json:"-" gorm:"foreignKey:AffiliationInstitutionID"`
+	DepartmentID             *uint        `json:"-" gorm:"index"`
+	Department               *Department  `json:"-"`
 }
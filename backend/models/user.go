@@ -1,10 +1,48 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	Email    string      `json:"email" gorm:"unique;not null"`
-	Password string      `json:"password" gorm:"not null"`
-	Profile  UserProfile `json:"profile" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Email    string `json:"email" gorm:"unique;not null"`
+	Password string `json:"password" gorm:"not null"`
+	IsAdmin  bool   `json:"is_admin" gorm:"not null;default:false"`
+	// set by administrators; disabled accounts can neither log in nor use previously issued tokens
+	DisabledAt *time.Time `json:"disabled_at"`
+	// set when an administrator forces a password reset, cleared once the user picks a new password
+	PasswordResetRequired bool `json:"password_reset_required" gorm:"not null;default:false"`
+	// set once the user proved they receive mail at Email, through a verification, password reset or email change link
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// tokens issued before the password last changed are rejected; kept to whole seconds like token issue times
+	PasswordChangedAt *time.Time `json:"-"`
+	// when the account is due to be anonymized; set when the user asks to delete their account and cleared if they cancel
+	DeletionScheduledAt *time.Time  `json:"deletion_scheduled_at" gorm:"index"`
+	Profile             UserProfile `json:"profile" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+// PasswordResetToken is a single use token emailed to a user to choose a new password.
+// Only a hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// EmailChangeRequest holds a new email address until the user confirms it from a link sent to that address.
+// Only a hash of the token is stored.
+type EmailChangeRequest struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	NewEmail    string     `gorm:"not null" json:"new_email"`
+	TokenHash   string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
 }
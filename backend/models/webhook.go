@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventCollaboratorJoined WebhookEvent = "collaborator.joined"
+	WebhookEventStatusChanged      WebhookEvent = "project.status_changed"
+	WebhookEventTaskCreated        WebhookEvent = "task.created"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []WebhookEvent{
+	WebhookEventCollaboratorJoined,
+	WebhookEventStatusChanged,
+	WebhookEventTaskCreated,
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+type Webhook struct {
+	gorm.Model
+	ProjectID uint   `gorm:"not null;index" json:"project_id"`
+	CreatorID uint   `gorm:"not null" json:"creator_id"`
+	URL       string `gorm:"not null" json:"url"`
+	Secret    string `gorm:"not null" json:"-"`
+	Events    string `gorm:"type:text" json:"events"` // store as JSON string
+	Active    bool   `gorm:"not null;default:true" json:"active"`
+}
+
+// Convert Events from JSON to []WebhookEvent when reading from DB
+func (w *Webhook) GetEvents() []WebhookEvent {
+	var events []WebhookEvent
+	if w.Events == "" {
+		return events
+	}
+	if err := json.Unmarshal([]byte(w.Events), &events); err != nil {
+		log.Println("Error unmarshaling Events:", err)
+	}
+	return events
+}
+
+// Convert []WebhookEvent to JSON before saving Events to DB
+func (w *Webhook) SetEvents(events []WebhookEvent) {
+	eventsJSON, err := json.Marshal(events)
+	if err != nil {
+		log.Println("Error marshaling Events:", err)
+		return
+	}
+	w.Events = string(eventsJSON)
+}
+
+// WebhookDelivery is a single event sent to a webhook, together with the outcome of its latest attempt
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint                  `gorm:"not null;index" json:"webhook_id"`
+	Event          WebhookEvent          `gorm:"not null" json:"event"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"not null;index" json:"status"`
+	Attempts       int                   `gorm:"not null" json:"attempts"`
+	NextAttemptAt  *time.Time            `gorm:"index" json:"next_attempt_at"`
+	ResponseStatus int                   `json:"response_status"`
+	ResponseBody   string                `gorm:"type:text" json:"response_body"`
+	Error          string                `json:"error"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+}
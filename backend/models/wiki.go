@@ -0,0 +1,28 @@
+package models
+
+import "gorm.io/gorm"
+
+// WikiPage holds the current state of a project wiki page.
+// Revision is incremented on every edit and doubles as the optimistic concurrency token.
+type WikiPage struct {
+	gorm.Model
+	ProjectID uint           `gorm:"not null;uniqueIndex:idx_wiki_slug" json:"project_id"`
+	Slug      string         `gorm:"not null;uniqueIndex:idx_wiki_slug" json:"slug"`
+	Title     string         `gorm:"not null" json:"title"`
+	Content   string         `gorm:"type:text" json:"content"` // markdown source
+	Revision  int            `gorm:"not null" json:"revision"`
+	AuthorID  uint           `gorm:"not null" json:"author_id"` // author of the latest revision
+	Revisions []WikiRevision `gorm:"foreignKey:PageID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// WikiRevision is an immutable snapshot of a wiki page after an edit
+type WikiRevision struct {
+	gorm.Model
+	PageID   uint   `gorm:"not null;uniqueIndex:idx_wiki_revision" json:"page_id"`
+	Revision int    `gorm:"not null;uniqueIndex:idx_wiki_revision" json:"revision"`
+	Title    string `gorm:"not null" json:"title"`
+	Content  string `gorm:"type:text" json:"content"`
+	AuthorID uint   `gorm:"not null" json:"author_id"`
+	Author   User   `gorm:"foreignKey:AuthorID" json:"-"`
+	Summary  string `json:"summary"` // optional edit summary
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"backend/controllers"
+	"backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(router *gin.Engine) {
+	admin := router.Group("/admin", middleware.AuthRequired(), middleware.AdminOnly())
+	{
+		admin.GET("/audit-events", controllers.QueryAuditEvents)
+		admin.GET("/stats", controllers.GetPlatformStats)
+		admin.GET("/users", controllers.ListUsers)
+		admin.POST("/users/:id/disable", controllers.DisableUser)
+		admin.POST("/users/:id/enable", controllers.EnableUser)
+		admin.POST("/users/:id/force-password-reset", controllers.ForcePasswordReset)
+		admin.DELETE("/projects/:id", controllers.AdminDeleteProject)
+		admin.GET("/reports", controllers.ListModerationQueue)
+		admin.POST("/reports/:id/resolve", controllers.ResolveReport)
+		admin.POST("/institutions", controllers.CreateInstitution)
+		admin.PUT("/institutions/:id", controllers.UpdateInstitution)
+		admin.DELETE("/institutions/:id", controllers.DeleteInstitution)
+		admin.POST("/institutions/normalize-affiliations", controllers.NormalizeAffiliations)
+		admin.POST("/institutions/:id/departments", controllers.CreateDepartment)
+		admin.PUT("/institutions/:id/departments/:departmentId", controllers.UpdateDepartment)
+		admin.DELETE("/institutions/:id/departments/:departmentId", controllers.DeleteDepartment)
+	}
+}
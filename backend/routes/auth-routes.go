@@ -11,5 +11,10 @@ func AuthRoutes(router *gin.Engine) {
 	{
 		auth.POST("/register", controllers.RegisterUser)
 		auth.POST("/login", controllers.LoginUser)
+		auth.POST("/reset-password", controllers.ResetPassword)
+		auth.POST("/confirm-email", controllers.ConfirmEmailChange)
+		auth.POST("/verify-email", controllers.VerifyEmail)
+		auth.GET("/not-me", controllers.ConfirmLoginReport)
+		auth.POST("/not-me", controllers.ReportLogin)
 	}
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"backend/controllers"
+	"backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GroupsRoutes(router *gin.Engine) {
+	groups := router.Group("/groups")
+	{
+		groups.GET("", controllers.ListGroups)
+		groups.POST("", middleware.AuthRequired(), controllers.CreateGroup)
+		groups.GET("/:id", controllers.GetGroup)
+		groups.PUT("/:id", middleware.AuthRequired(), controllers.UpdateGroup)
+		groups.DELETE("/:id", middleware.AuthRequired(), controllers.DeleteGroup)
+		groups.GET("/:id/projects", middleware.OptionalAuth(), controllers.ListGroupProjects)
+		groups.POST("/:id/members", middleware.AuthRequired(), controllers.AddGroupMember)
+		groups.PUT("/:id/members/:userId", middleware.AuthRequired(), controllers.UpdateGroupMemberRole)
+		groups.DELETE("/:id/members/:userId", middleware.AuthRequired(), controllers.RemoveGroupMember)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"backend/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func InstitutionsRoutes(router *gin.Engine) {
+	institutions := router.Group("/institutions")
+	{
+		institutions.GET("", controllers.ListInstitutions)
+		institutions.GET("/:id", controllers.GetInstitution)
+		institutions.GET("/:id/members", controllers.ListInstitutionMembers)
+		institutions.GET("/:id/projects", controllers.ListInstitutionProjects)
+	}
+}
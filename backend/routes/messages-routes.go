@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"backend/controllers"
+	"backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func MessagesRoutes(router *gin.Engine) {
+	conversations := router.Group("/conversations", middleware.AuthRequired())
+	{
+		conversations.GET("", controllers.ListConversations)
+		conversations.POST("", controllers.CreateConversation)
+		conversations.GET("/:id", controllers.GetConversation)
+		conversations.GET("/:id/messages", controllers.ListMessages)
+		conversations.POST("/:id/messages", controllers.SendMessage)
+		conversations.POST("/:id/read", controllers.MarkConversationRead)
+		conversations.PUT("/:id/mute", controllers.MuteConversation)
+	}
+}
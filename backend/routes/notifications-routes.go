@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"backend/controllers"
+	"backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func NotificationsRoutes(router *gin.Engine) {
+	notifications := router.Group("/notifications")
+	{
+		notifications.GET("", middleware.AuthRequired(), controllers.ListNotifications)
+		notifications.GET("/stream", middleware.StreamAuthRequired(), controllers.StreamEvents)
+		notifications.POST("/stream/ticket", middleware.AuthRequired(), controllers.IssueStreamTicket)
+		notifications.GET("/unsubscribe", controllers.UnsubscribeFromEmails)
+		notifications.POST("/unsubscribe", controllers.UnsubscribeFromEmails)
+		notifications.GET("/unread-count", middleware.AuthRequired(), controllers.GetUnreadNotificationCount)
+		notifications.POST("/read-all", middleware.AuthRequired(), controllers.MarkAllNotificationsRead)
+		notifications.POST("/:id/read", middleware.AuthRequired(), controllers.MarkNotificationRead)
+	}
+}
@@ -14,8 +14,56 @@ func ProjectsRoutes(router *gin.Engine) {
 		projects.GET("/user", middleware.AuthRequired(), controllers.ListUserProjects)
 		projects.POST("", middleware.AuthRequired(), controllers.CreateProject)
 		projects.GET("/:id", controllers.RetrieveProject)
+		projects.DELETE("/:id", middleware.AuthRequired(), controllers.DeleteProject)
+		projects.POST("/:id/transfer", middleware.AuthRequired(), controllers.TransferProject)
+		projects.PUT("/:id/group", middleware.AuthRequired(), controllers.SetProjectGroup)
+		projects.PUT("/:id/status", middleware.AuthRequired(), controllers.UpdateProjectStatus)
+		projects.GET("/:id/collaborators", middleware.AuthRequired(), controllers.ListCollaborators)
 		projects.POST("/:id/collaborators", middleware.AuthRequired(), controllers.InviteCollaborator)
+		projects.PUT("/:id/collaborators/:userId", middleware.AuthRequired(), controllers.UpdateCollaboratorRole)
+		projects.PUT("/:id/collaborators/:userId/status", middleware.AuthRequired(), controllers.UpdateCollaboratorStatus)
+		projects.GET("/:id/permissions", middleware.AuthRequired(), controllers.GetProjectPermissions)
+		projects.GET("/:id/roles", middleware.AuthRequired(), controllers.ListProjectRoles)
+		projects.POST("/:id/roles", middleware.AuthRequired(), controllers.CreateProjectRole)
+		projects.PUT("/:id/roles/:roleId", middleware.AuthRequired(), controllers.UpdateProjectRole)
+		projects.DELETE("/:id/roles/:roleId", middleware.AuthRequired(), controllers.DeleteProjectRole)
 		projects.GET("/invitations", middleware.AuthRequired(), controllers.GetProjectInvitations)
 		projects.POST("/:id/collaborators/invitations/:invitationId/:action", middleware.AuthRequired(), controllers.RespondToProjectInvitation)
+		projects.GET("/:id/attachments", middleware.AuthRequired(), controllers.ListProjectAttachments)
+		projects.POST("/:id/attachments", middleware.AuthRequired(), controllers.UploadProjectAttachment)
+		projects.GET("/:id/attachments/:attachmentId", middleware.AuthRequired(), controllers.DownloadProjectAttachment)
+		projects.DELETE("/:id/attachments/:attachmentId", middleware.AuthRequired(), controllers.DeleteProjectAttachment)
+		projects.GET("/:id/wiki", middleware.AuthRequired(), controllers.ListWikiPages)
+		projects.POST("/:id/wiki", middleware.AuthRequired(), controllers.CreateWikiPage)
+		projects.GET("/:id/wiki/:slug", middleware.AuthRequired(), controllers.RetrieveWikiPage)
+		projects.PUT("/:id/wiki/:slug", middleware.AuthRequired(), controllers.EditWikiPage)
+		projects.GET("/:id/wiki/:slug/diff", middleware.AuthRequired(), controllers.DiffWikiRevisions)
+		projects.GET("/:id/wiki/:slug/revisions", middleware.AuthRequired(), controllers.ListWikiRevisions)
+		projects.GET("/:id/wiki/:slug/revisions/:revision", middleware.AuthRequired(), controllers.RetrieveWikiRevision)
+		projects.POST("/:id/wiki/:slug/revisions/:revision/restore", middleware.AuthRequired(), controllers.RestoreWikiRevision)
+		projects.GET("/:id/tasks", middleware.AuthRequired(), controllers.ListTasks)
+		projects.POST("/:id/tasks", middleware.AuthRequired(), controllers.CreateTask)
+		projects.GET("/:id/tasks/:taskId", middleware.AuthRequired(), controllers.RetrieveTask)
+		projects.PUT("/:id/tasks/:taskId", middleware.AuthRequired(), controllers.EditTask)
+		projects.POST("/:id/tasks/:taskId/move", middleware.AuthRequired(), controllers.MoveTask)
+		projects.DELETE("/:id/tasks/:taskId", middleware.AuthRequired(), controllers.DeleteTask)
+		projects.GET("/:id/milestones", middleware.AuthRequired(), controllers.ListMilestones)
+		projects.POST("/:id/milestones", middleware.AuthRequired(), controllers.CreateMilestone)
+		projects.PUT("/:id/milestones/:milestoneId", middleware.AuthRequired(), controllers.EditMilestone)
+		projects.DELETE("/:id/milestones/:milestoneId", middleware.AuthRequired(), controllers.DeleteMilestone)
+		projects.GET("/:id/discussions", middleware.AuthRequired(), controllers.ListDiscussionThreads)
+		projects.POST("/:id/discussions", middleware.AuthRequired(), controllers.CreateDiscussionThread)
+		projects.GET("/:id/discussions/:threadId", middleware.AuthRequired(), controllers.RetrieveDiscussionThread)
+		projects.POST("/:id/discussions/:threadId/comments", middleware.AuthRequired(), controllers.CreateComment)
+		projects.PUT("/:id/discussions/:threadId/comments/:commentId", middleware.AuthRequired(), controllers.EditComment)
+		projects.DELETE("/:id/discussions/:threadId/comments/:commentId", middleware.AuthRequired(), controllers.DeleteComment)
+		projects.GET("/:id/discussions/:threadId/comments/:commentId/history", middleware.AuthRequired(), controllers.GetCommentHistory)
+		projects.GET("/:id/webhooks", middleware.AuthRequired(), controllers.ListWebhooks)
+		projects.POST("/:id/webhooks", middleware.AuthRequired(), controllers.CreateWebhook)
+		projects.PUT("/:id/webhooks/:webhookId", middleware.AuthRequired(), controllers.EditWebhook)
+		projects.DELETE("/:id/webhooks/:webhookId", middleware.AuthRequired(), controllers.DeleteWebhook)
+		projects.GET("/:id/webhooks/:webhookId/deliveries", middleware.AuthRequired(), controllers.ListWebhookDeliveries)
+		projects.POST("/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", middleware.AuthRequired(), controllers.RedeliverWebhook)
+		projects.GET("/:id/activity", middleware.AuthRequired(), controllers.ListProjectActivity)
 	}
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"backend/controllers"
+	"backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ReportsRoutes(router *gin.Engine) {
+	reports := router.Group("/reports")
+	{
+		reports.GET("", middleware.AuthRequired(), controllers.ListMyReports)
+		reports.POST("", middleware.AuthRequired(), controllers.CreateReport)
+	}
+}
@@ -10,7 +10,27 @@ import (
 func UsersRoutes(router *gin.Engine) {
 	users := router.Group("/users")
 	{
-		users.GET("/:id/profile", controllers.RetrieveUserProfile)
+		users.GET("", controllers.SearchUsers)
+		users.DELETE("/me", middleware.AuthRequired(), controllers.DeleteAccount)
+		users.GET("/me/sessions", middleware.AuthRequired(), controllers.ListSessions)
+		users.DELETE("/me/sessions/:id", middleware.AuthRequired(), controllers.EndSession)
+		users.GET("/me/login-history", middleware.AuthRequired(), controllers.ListLoginHistory)
+		users.POST("/me/password", middleware.AuthRequired(), controllers.ChangePassword)
+		users.POST("/me/email", middleware.AuthRequired(), controllers.ChangeEmail)
+		users.POST("/me/verify-email", middleware.AuthRequired(), controllers.RequestEmailVerification)
+		users.POST("/me/cancel-deletion", middleware.AuthRequired(), controllers.CancelAccountDeletion)
+		users.GET("/me/export", middleware.AuthRequired(), controllers.ExportAccountData)
+		users.GET("/:id/profile", middleware.OptionalAuth(), controllers.RetrieveUserProfile)
 		users.PUT("/:id/profile", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.EditUserProfile)
+		users.GET("/:id/avatar", controllers.GetUserAvatar)
+		users.POST("/:id/avatar", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UploadUserAvatar)
+		users.DELETE("/:id/avatar", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.DeleteUserAvatar)
+		users.GET("/:id/notification-preferences", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.GetNotificationPreferences)
+		users.PUT("/:id/notification-preferences", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UpdateNotificationPreferences)
+		users.GET("/:id/blocks", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.ListBlockedUsers)
+		users.POST("/:id/blocks", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.BlockUser)
+		users.DELETE("/:id/blocks/:blockedId", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UnblockUser)
+		users.GET("/:id/messaging-settings", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.GetMessagingSettings)
+		users.PUT("/:id/messaging-settings", middleware.AuthRequired(), middleware.SameUserOnly(), controllers.UpdateMessagingSettings)
 	}
 }
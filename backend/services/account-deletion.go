@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend/email"
+	"backend/models"
+	"backend/storage"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOwnsProjects is returned when deleting an account that still owns projects
+	ErrOwnsProjects = errors.New("account still owns projects")
+	// ErrLeadsGroups is returned when deleting an account that is the only PI of groups
+	ErrLeadsGroups = errors.New("account is the only PI of groups")
+)
+
+// AccountDeletionGracePeriod returns how long an account scheduled for deletion can still be restored
+func AccountDeletionGracePeriod() time.Duration {
+	return time.Duration(utils.GetEnvInt64("ACCOUNT_DELETION_GRACE_DAYS", 30)) * 24 * time.Hour
+}
+
+// OwnedProjects returns the projects owned by a user. They must be transferred or deleted before the account can be deleted.
+func OwnedProjects(db *gorm.DB, userID uint) ([]models.Project, error) {
+	var projects []models.Project
+	err := db.Where("owner_id = ?", userID).Order("id").Find(&projects).Error
+	return projects, err
+}
+
+// LedGroups returns the groups a user is the only PI of. Another member must become PI before the account can be deleted.
+func LedGroups(db *gorm.DB, userID uint) ([]models.Group, error) {
+	led := db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ? AND role = ?", userID, models.GroupRolePI)
+	coLed := db.Model(&models.GroupMember{}).Select("group_id").Where("user_id <> ? AND role = ?", userID, models.GroupRolePI)
+	var groups []models.Group
+	err := db.Where("id IN (?) AND id NOT IN (?)", led, coLed).Order("id").Find(&groups).Error
+	return groups, err
+}
+
+// deletedEmail replaces the email of an anonymized account, keeping the column unique and freeing the address for a new sign up
+func deletedEmail(userID uint) string {
+	return fmt.Sprintf("deleted-user-%d@deleted.invalid", userID)
+}
+
+// AnonymizeUser removes the personal data of an account and signs it out for good. Content the user authored in projects,
+// like comments, tasks and wiki revisions, is kept but no longer attributed to them, and their email address is redacted
+// from comments, their edit history and audit events. Returns the storage prefix of the
+// user's avatar, if any, for the caller to delete once the transaction is committed. Call it within a transaction.
+func AnonymizeUser(db *gorm.DB, user models.User) (string, error) {
+	var owned int64
+	if err := db.Model(&models.Project{}).Where("owner_id = ?", user.ID).Count(&owned).Error; err != nil {
+		return "", err
+	}
+	if owned > 0 {
+		return "", ErrOwnsProjects
+	}
+	// groups must keep a PI, see CheckGroupKeepsPI
+	led, err := LedGroups(db, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(led) > 0 {
+		return "", ErrLeadsGroups
+	}
+
+	var profile models.UserProfile
+	db.Where("user_id = ?", user.ID).Limit(1).Find(&profile)
+
+	// comments mention the user by email, and audit events record it, e.g. when signing up or being invited
+	err = errors.Join(
+		redactEmailColumn(db, "comments", "body", user.Email, deletedEmail(user.ID)),
+		redactEmailColumn(db, "comment_edits", "previous_body", user.Email, deletedEmail(user.ID)),
+		redactEmailColumn(db, "audit_events", "changes", user.Email, deletedEmail(user.ID)),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	err = errors.Join(
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.CommentMention{}).Error,
+		db.Model(&models.Task{}).Where("assignee_id = ?", user.ID).Update("assignee_id", nil).Error,
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Collaborator{}).Error,
+		db.Where("user_id = ?", user.ID).Delete(&models.GroupMember{}).Error,
+		db.Unscoped().Where("inviter_id = ? AND status = ?", user.ID, models.InvitationStatusPending).Delete(&models.Invitation{}).Error,
+		db.Model(&models.Invitation{}).Where("email = ?", user.Email).Update("email", deletedEmail(user.ID)).Error,
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.Notification{}).Error,
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.NotificationPreference{}).Error,
+		db.Where("blocker_id = ? OR blocked_id = ?", user.ID, user.ID).Delete(&models.UserBlock{}).Error,
+		db.Where("user_id = ?", user.ID).Delete(&models.PasswordResetToken{}).Error,
+		db.Where("user_id = ?", user.ID).Delete(&models.EmailChangeRequest{}).Error,
+		db.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error,
+		db.Where("user_id = ?", user.ID).Delete(&models.LoginAttempt{}).Error,
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.ConversationParticipant{}).Error,
+		db.Unscoped().Where("user_id = ?", user.ID).Delete(&models.UserProfile{}).Error,
+		db.Model(&user).Updates(map[string]interface{}{
+			"email": deletedEmail(user.ID), "password": "", "is_admin": false, "deletion_scheduled_at": nil, "email_verified_at": nil,
+		}).Error,
+		db.Delete(&user).Error,
+	)
+	if err != nil {
+		return "", err
+	}
+	return profile.AvatarKey, nil
+}
+
+// redactEmailColumn redacts an email address wherever it appears in a text column, see redactEmail. Rows are
+// updated with raw SQL, which also covers audit events that can't be changed through the model otherwise.
+func redactEmailColumn(db *gorm.DB, table, column, address, replacement string) error {
+	var rows []struct {
+		ID   uint
+		Text string
+	}
+	// LIKE only narrows the rows down, redactEmail decides what actually matches
+	err := db.Table(table).Select("id, "+column+" AS text").
+		Where("LOWER("+column+") LIKE ?", "%"+strings.ToLower(address)+"%").Find(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if redacted := redactEmail(row.Text, address, replacement); redacted != row.Text {
+			if err := db.Exec("UPDATE "+table+" SET "+column+" = ? WHERE id = ?", redacted, row.ID).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// redactEmail replaces an email address in text whatever its case, leaving longer addresses that merely contain it
+// alone. Mentions of the address become "@deleted-user", other occurrences the replacement address.
+func redactEmail(text, address, replacement string) string {
+	pattern := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(address))
+	var redacted strings.Builder
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		start, end := match[0], match[1]
+		if start > 0 && isEmailLocalChar(text[start-1]) {
+			continue
+		}
+		// a trailing dot only continues the domain if more of it follows, otherwise it ends a sentence
+		if end < len(text) && (isEmailDomainChar(text[end]) || text[end] == '.' && end+1 < len(text) && isEmailDomainChar(text[end+1])) {
+			continue
+		}
+		if start > 0 && text[start-1] == '@' {
+			redacted.WriteString(text[last : start-1])
+			redacted.WriteString("@deleted-user")
+		} else {
+			redacted.WriteString(text[last:start])
+			redacted.WriteString(replacement)
+		}
+		last = end
+	}
+	redacted.WriteString(text[last:])
+	return redacted.String()
+}
+
+func isEmailLocalChar(c byte) bool {
+	return isEmailDomainChar(c) || strings.IndexByte("._%+", c) >= 0
+}
+
+func isEmailDomainChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-'
+}
+
+// AccountDeletionWorker anonymizes accounts whose deletion grace period has ended
+type AccountDeletionWorker struct {
+	DB *gorm.DB
+}
+
+// NewAccountDeletionWorker creates a worker that purges accounts due for deletion
+func NewAccountDeletionWorker(db *gorm.DB) *AccountDeletionWorker {
+	return &AccountDeletionWorker{DB: db}
+}
+
+// Run purges due accounts every interval until ctx is cancelled
+func (w *AccountDeletionWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.PurgeDue(ctx, time.Now()); err != nil {
+				log.Println("Failed to delete accounts:", err)
+			}
+		}
+	}
+}
+
+// PurgeDue anonymizes every account due for deletion at now. Accounts that own projects again, e.g. after accepting a
+// transfer during the grace period, are kept until their projects are transferred or deleted, and accounts that became
+// the only PI of a group are kept until another member becomes PI.
+func (w *AccountDeletionWorker) PurgeDue(ctx context.Context, now time.Time) error {
+	var users []models.User
+	if err := w.DB.Where("deletion_scheduled_at <= ?", now).Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		var avatarKey string
+		err := w.DB.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if avatarKey, err = AnonymizeUser(tx, user); err != nil {
+				return err
+			}
+			return RecordAudit(tx, AuditContext{ActorID: user.ID}, AuditEntry{
+				Action: models.AuditAccountDeleted, TargetType: "user", TargetID: user.ID,
+			})
+		})
+		if errors.Is(err, ErrOwnsProjects) {
+			log.Printf("Account %d is due for deletion but still owns projects", user.ID)
+			continue
+		}
+		if errors.Is(err, ErrLeadsGroups) {
+			log.Printf("Account %d is due for deletion but is still the only PI of groups", user.ID)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if avatarKey != "" && storage.Store != nil {
+			for _, size := range utils.AvatarSizes {
+				storage.Store.Delete(ctx, utils.AvatarBlobKey(avatarKey, size))
+			}
+		}
+	}
+	return nil
+}
+
+// SendAccountDeletionEmail confirms to the user that their account is scheduled for deletion
+func SendAccountDeletionEmail(user models.User, due time.Time) error {
+	text := fmt.Sprintf("Your account is scheduled for deletion on %s.\n\n"+
+		"Until then you can log in and cancel the deletion from your account settings. After that date your profile "+
+		"and personal data are removed, and content you contributed to projects is kept without your name.\n",
+		due.UTC().Format("January 2, 2006"))
+
+	return email.Sender.Send(email.Message{
+		To:      user.Email,
+		Subject: "Your account is scheduled for deletion",
+		Text:    text,
+	})
+}
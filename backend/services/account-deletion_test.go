@@ -0,0 +1,72 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestPurgeDueAccounts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:account_deletion?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Project{}, &models.Collaborator{}, &models.Invitation{}, &models.Notification{},
+		&models.NotificationPreference{}, &models.UserBlock{}, &models.PasswordResetToken{}, &models.EmailChangeRequest{}, &models.Task{}, &models.Comment{}, &models.CommentEdit{}, &models.CommentMention{},
+		&models.ConversationParticipant{}, &models.Session{}, &models.LoginAttempt{}, &models.Group{}, &models.GroupMember{}, &models.AuditEvent{})
+
+	now := time.Now()
+	later := now.Add(time.Hour)
+	due := models.User{Email: "due@example.com", Password: "hash", DeletionScheduledAt: &now}
+	pending := models.User{Email: "pending@example.com", Password: "hash", DeletionScheduledAt: &later}
+	owner := models.User{Email: "owner@example.com", Password: "hash", DeletionScheduledAt: &now}
+	db.Create(&due)
+	db.Create(&pending)
+	db.Create(&owner)
+	db.Create(&models.Project{Title: "Still owned", OwnerID: owner.ID})
+	db.Create(&models.Invitation{InviterID: pending.ID, Email: due.Email, Status: models.InvitationStatusRejected})
+	comment := models.Comment{Body: "Thanks @Due@Example.com, cc overdue@example.com"}
+	db.Create(&comment)
+	db.Create(&models.CommentMention{CommentID: comment.ID, UserID: due.ID})
+	edit := models.CommentEdit{CommentID: comment.ID, PreviousBody: "Ask due@example.com."}
+	db.Create(&edit)
+	registered := models.AuditEvent{Action: models.AuditUserRegistered, TargetType: "user", TargetID: due.ID, Changes: `{"after":{"email":"due@example.com"}}`}
+	db.Create(&registered)
+
+	assert.NoError(t, services.NewAccountDeletionWorker(db).PurgeDue(context.Background(), now))
+
+	// only the due account without projects is anonymized
+	var remaining []models.User
+	db.Order("id").Find(&remaining)
+	assert.Len(t, remaining, 2)
+	assert.Equal(t, pending.ID, remaining[0].ID)
+	assert.Equal(t, owner.ID, remaining[1].ID)
+
+	var anonymized models.User
+	db.Unscoped().First(&anonymized, due.ID)
+	assert.Equal(t, "deleted-user-1@deleted.invalid", anonymized.Email)
+	assert.Empty(t, anonymized.Password)
+
+	var invitation models.Invitation
+	db.First(&invitation)
+	assert.Equal(t, anonymized.Email, invitation.Email)
+
+	// the email is redacted wherever it was recorded, whatever its case
+	db.First(&comment, comment.ID)
+	assert.Equal(t, "Thanks @deleted-user, cc overdue@example.com", comment.Body)
+	db.First(&edit, edit.ID)
+	assert.Equal(t, "Ask deleted-user-1@deleted.invalid.", edit.PreviousBody)
+	db.First(&registered, registered.ID)
+	assert.Equal(t, `{"after":{"email":"deleted-user-1@deleted.invalid"}}`, registered.Changes)
+
+	var events int64
+	db.Model(&models.AuditEvent{}).Where("action = ? AND target_id = ?", models.AuditAccountDeleted, due.ID).Count(&events)
+	assert.Equal(t, int64(1), events)
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// minimum similarity between an affiliation and an institution or department name for them to be linked
+const affiliationMatchThreshold = 0.85
+
+var (
+	// ErrInstitutionNotFound is returned when a profile is linked to an institution that isn't registered
+	ErrInstitutionNotFound = errors.New("institution not found")
+	// ErrDepartmentNotFound is returned when a profile is linked to a department the institution doesn't have
+	ErrDepartmentNotFound = errors.New("department not found")
+)
+
+// separators between the parts of an affiliation, e.g. "Dept. of CS, Univ. of Florida" or "CISE - UF"
+var affiliationSeparator = regexp.MustCompile(`(?i)[,;|/()]|\s+(?:-|–|—|at)\s+`)
+
+// common abbreviations in affiliations, expanded before comparing
+var affiliationAbbreviations = map[string]string{
+	"univ":   "university",
+	"uni":    "university",
+	"dept":   "department",
+	"inst":   "institute",
+	"tech":   "technology",
+	"natl":   "national",
+	"intl":   "international",
+	"ctr":    "center",
+	"centre": "center",
+	"lab":    "laboratory",
+	"labs":   "laboratories",
+	"coll":   "college",
+	"sch":    "school",
+	"sci":    "science",
+	"eng":    "engineering",
+	"engr":   "engineering",
+}
+
+var affiliationStopwords = map[string]bool{"the": true, "of": true, "at": true, "and": true, "for": true, "in": true, "de": true}
+
+// words naming the kind of unit rather than the unit itself, ignored when comparing departments
+var departmentUnitWords = map[string]bool{"department": true, "school": true, "faculty": true, "division": true, "college": true}
+
+// AffiliationMatch is the registered institution and department an affiliation text refers to
+type AffiliationMatch struct {
+	Institution models.Institution
+	// nil when none of the institution's departments is mentioned
+	Department *models.Department
+	// similarity of the institution name, 1 for an exact name or alias
+	Score float64
+}
+
+// CanonicalAffiliation returns the affiliation text for an institution and optional department, e.g.
+// "Computer Science, University of Florida"
+func CanonicalAffiliation(institution models.Institution, department *models.Department) string {
+	if department == nil {
+		return institution.Name
+	}
+	return department.Name + ", " + institution.Name
+}
+
+// normalizeAffiliation reduces a name to lowercase words without punctuation, abbreviations or filler words,
+// so "Univ. of Florida" and "University Of Florida" compare equal
+func normalizeAffiliation(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words := make([]string, 0, len(fields))
+	for _, word := range fields {
+		if expanded, ok := affiliationAbbreviations[word]; ok {
+			word = expanded
+		}
+		if !affiliationStopwords[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// withoutUnitWords drops words like "department" from a normalized name
+func withoutUnitWords(name string) string {
+	words := strings.Fields(name)
+	kept := words[:0]
+	for _, word := range words {
+		if !departmentUnitWords[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// similarity compares two normalized names from 0 to 1, ignoring word order
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	sortWords := func(s string) string {
+		words := strings.Fields(s)
+		sort.Strings(words)
+		return strings.Join(words, " ")
+	}
+	return max(levenshteinRatio(a, b), levenshteinRatio(sortWords(a), sortWords(b)))
+}
+
+// levenshteinRatio is one minus the edit distance relative to the length of the longer string
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// affiliationParts splits an affiliation into its normalized parts, with the whole text as the first part
+func affiliationParts(text string) []string {
+	parts := []string{normalizeAffiliation(text)}
+	for _, part := range affiliationSeparator.Split(text, -1) {
+		if normalized := normalizeAffiliation(part); normalized != "" && normalized != parts[0] {
+			parts = append(parts, normalized)
+		}
+	}
+	return parts
+}
+
+// bestNameMatch returns the highest similarity between any of the parts and any of the names, and which part it was
+func bestNameMatch(parts []string, names []string, normalize func(string) string) (float64, int) {
+	best, bestPart := 0.0, -1
+	for i, part := range parts {
+		for _, name := range names {
+			if score := similarity(normalize(part), normalize(normalizeAffiliation(name))); score > best {
+				best, bestPart = score, i
+			}
+		}
+	}
+	return best, bestPart
+}
+
+// matchAffiliation finds the institution, and within it the department, named in an affiliation text.
+// institutions must have their departments loaded. Returns nil when no institution is similar enough.
+func matchAffiliation(institutions []models.Institution, text string) *AffiliationMatch {
+	parts := affiliationParts(text)
+	if parts[0] == "" {
+		return nil
+	}
+	identity := func(s string) string { return s }
+
+	var match *AffiliationMatch
+	matchedPart := -1
+	for _, institution := range institutions {
+		score, part := bestNameMatch(parts, append([]string{institution.Name}, institution.GetAliases()...), identity)
+		if score >= affiliationMatchThreshold && (match == nil || score > match.Score) {
+			match = &AffiliationMatch{Institution: institution, Score: score}
+			matchedPart = part
+		}
+	}
+	if match == nil {
+		return nil
+	}
+
+	// the department is named in another part than the institution, or in the only part there is
+	departmentParts := parts
+	if len(parts) > 1 {
+		departmentParts = make([]string, 0, len(parts))
+		for i, part := range parts[1:] {
+			if i+1 != matchedPart {
+				departmentParts = append(departmentParts, part)
+			}
+		}
+	}
+	bestDepartment := 0.0
+	for i, department := range match.Institution.Departments {
+		score, _ := bestNameMatch(departmentParts, append([]string{department.Name}, department.GetAliases()...), withoutUnitWords)
+		if score >= affiliationMatchThreshold && score > bestDepartment {
+			bestDepartment = score
+			match.Department = &match.Institution.Departments[i]
+		}
+	}
+	return match
+}
+
+// loadInstitutionsForMatching returns every institution with its departments
+func loadInstitutionsForMatching(db *gorm.DB) ([]models.Institution, error) {
+	var institutions []models.Institution
+	err := db.Preload("Departments").Order("id").Find(&institutions).Error
+	return institutions, err
+}
+
+// MatchAffiliation finds the registered institution and department a free-text affiliation refers to,
+// tolerating abbreviations, word order and typos. Returns nil when nothing is similar enough.
+func MatchAffiliation(db *gorm.DB, text string) (*AffiliationMatch, error) {
+	institutions, err := loadInstitutionsForMatching(db)
+	if err != nil {
+		return nil, err
+	}
+	return matchAffiliation(institutions, text), nil
+}
+
+// ResolveAffiliation returns the institution and department a profile's affiliation is linked to. An explicitly
+// picked institution or department wins; otherwise the affiliation text is matched against registered institutions.
+func ResolveAffiliation(db *gorm.DB, affiliation string, institutionID, departmentID *uint) (*uint, *uint, error) {
+	if departmentID != nil {
+		var department models.Department
+		query := db.Where("id = ?", *departmentID)
+		if institutionID != nil {
+			query = query.Where("institution_id = ?", *institutionID)
+		}
+		if err := query.First(&department).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrDepartmentNotFound
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return &department.InstitutionID, &department.ID, nil
+	}
+	if institutionID != nil {
+		var institution models.Institution
+		if err := db.First(&institution, *institutionID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInstitutionNotFound
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return &institution.ID, nil, nil
+	}
+
+	match, err := MatchAffiliation(db, affiliation)
+	if err != nil || match == nil {
+		return nil, nil, err
+	}
+	if match.Department == nil {
+		return &match.Institution.ID, nil, nil
+	}
+	return &match.Institution.ID, &match.Department.ID, nil
+}
+
+// AffiliationNormalization describes how a profile's affiliation was, or would be, normalized
+type AffiliationNormalization struct {
+	UserID        uint    `json:"user_id"`
+	Affiliation   string  `json:"affiliation"`          // text as the user entered it
+	Normalized    string  `json:"normalized"`           // canonical text replacing it
+	InstitutionID uint    `json:"institution_id"`       // matched institution
+	DepartmentID  *uint   `json:"department_id"`        // matched department, if any
+	Score         float64 `json:"score" example:"0.93"` // similarity of the institution name
+}
+
+// NormalizeAffiliations links every profile with an affiliation text but no linked institution to the institution
+// and department the text matches, replacing the text with their canonical names. With dryRun set nothing is saved.
+// Returns the matched profiles and the number of affiliations that matched nothing.
+func NormalizeAffiliations(db *gorm.DB, dryRun bool) ([]AffiliationNormalization, int, error) {
+	institutions, err := loadInstitutionsForMatching(db)
+	if err != nil {
+		return nil, 0, err
+	}
+	var profiles []models.UserProfile
+	if err := db.Where("affiliation <> '' AND affiliation_institution_id IS NULL").Order("user_id").Find(&profiles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	normalized := []AffiliationNormalization{}
+	unmatched := 0
+	for _, profile := range profiles {
+		match := matchAffiliation(institutions, profile.Affiliation)
+		if match == nil {
+			unmatched++
+			continue
+		}
+		result := AffiliationNormalization{
+			UserID:        profile.UserID,
+			Affiliation:   profile.Affiliation,
+			Normalized:    CanonicalAffiliation(match.Institution, match.Department),
+			InstitutionID: match.Institution.ID,
+			Score:         match.Score,
+		}
+		if match.Department != nil {
+			result.DepartmentID = &match.Department.ID
+		}
+		normalized = append(normalized, result)
+
+		if dryRun {
+			continue
+		}
+		err := db.Model(&models.UserProfile{}).Where("id = ?", profile.ID).Updates(map[string]interface{}{
+			"affiliation":                result.Normalized,
+			"affiliation_institution_id": result.InstitutionID,
+			"department_id":              result.DepartmentID,
+		}).Error
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return normalized, unmatched, nil
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestMatchAffiliation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:affiliations?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Institution{}, &models.InstitutionDomain{}, &models.Department{})
+	for _, table := range []string{"users", "user_profiles", "institutions", "institution_domains", "departments"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	florida := models.Institution{Name: "University of Florida", Departments: []models.Department{{Name: "Department of Physics"}, {Name: "Mathematics"}}}
+	florida.SetAliases([]string{"UF", "UFL"})
+	central := models.Institution{Name: "University of Central Florida"}
+	mit := models.Institution{Name: "Massachusetts Institute of Technology"}
+	mit.SetAliases([]string{"MIT"})
+	db.Create(&florida)
+	db.Create(&central)
+	db.Create(&mit)
+
+	for text, expected := range map[string]string{
+		"University of Florida":              florida.Name,
+		"univ. of florida":                   florida.Name,
+		"Florida, University of":             florida.Name,
+		"Universty of Florda":                florida.Name,
+		"Physics Dept., UF":                  florida.Name,
+		"University of Central Florida":      central.Name,
+		"Massachusetts Inst. of Tech.":       mit.Name,
+		"PhD student at MIT":                 mit.Name,
+		"Dept of Mathematics - Univ Florida": florida.Name,
+	} {
+		match, err := services.MatchAffiliation(db, text)
+		assert.NoError(t, err)
+		if assert.NotNil(t, match, text) {
+			assert.Equal(t, expected, match.Institution.Name, text)
+		}
+	}
+	for _, text := range []string{"", "Florida State University", "Independent researcher", "Florida"} {
+		match, err := services.MatchAffiliation(db, text)
+		assert.NoError(t, err)
+		assert.Nil(t, match, text)
+	}
+
+	// departments are matched within the institution, ignoring words like "department"
+	match, _ := services.MatchAffiliation(db, "Physics Dept., UF")
+	if assert.NotNil(t, match) && assert.NotNil(t, match.Department) {
+		assert.Equal(t, "Department of Physics", match.Department.Name)
+	}
+	match, _ = services.MatchAffiliation(db, "Chemistry, University of Florida")
+	if assert.NotNil(t, match) {
+		assert.Nil(t, match.Department)
+		assert.Equal(t, 1.0, match.Score)
+	}
+	assert.Equal(t, "Mathematics, University of Florida", services.CanonicalAffiliation(florida, &florida.Departments[1]))
+
+	// explicit picks win over the text and are validated
+	institutionID, departmentID, err := services.ResolveAffiliation(db, "MIT", nil, &florida.Departments[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, florida.ID, *institutionID)
+	assert.Equal(t, florida.Departments[0].ID, *departmentID)
+	_, _, err = services.ResolveAffiliation(db, "", &mit.ID, &florida.Departments[0].ID)
+	assert.ErrorIs(t, err, services.ErrDepartmentNotFound)
+	_, _, err = services.ResolveAffiliation(db, "", new(uint), nil)
+	assert.ErrorIs(t, err, services.ErrInstitutionNotFound)
+	institutionID, departmentID, err = services.ResolveAffiliation(db, "Independent researcher", nil, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, institutionID)
+	assert.Nil(t, departmentID)
+
+	// bulk normalization leaves linked profiles alone
+	linked := models.UserProfile{UserID: 1, Affiliation: "UF", AffiliationInstitutionID: &mit.ID}
+	unlinked := models.UserProfile{UserID: 2, Affiliation: "Univ. of Florida, Mathematics"}
+	db.Create(&linked)
+	db.Create(&unlinked)
+	normalized, unmatched, err := services.NormalizeAffiliations(db, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, unmatched)
+	if assert.Len(t, normalized, 1) {
+		assert.Equal(t, uint(2), normalized[0].UserID)
+	}
+	db.First(&linked, linked.ID)
+	db.First(&unlinked, unlinked.ID)
+	assert.Equal(t, "UF", linked.Affiliation)
+	assert.Equal(t, mit.ID, *linked.AffiliationInstitutionID)
+	assert.Equal(t, "Mathematics, University of Florida", unlinked.Affiliation)
+	assert.Equal(t, florida.ID, *unlinked.AffiliationInstitutionID)
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// fields that change on every write and would only add noise to a diff
+var ignoredAuditFields = map[string]bool{"updated_at": true}
+
+// AuditEntry describes a mutating action. Before and After are snapshots of the target, usually the API
+// representation, and are reduced to the fields that changed. Leave Before nil for creations and After nil for deletions.
+type AuditEntry struct {
+	Action     models.AuditAction
+	TargetType string
+	TargetID   uint
+	ProjectID  *uint
+	Before     interface{}
+	After      interface{}
+}
+
+// AuditChange is the before and after value of a single field
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditContext identifies who performed an action and from where
+type AuditContext struct {
+	ActorID   uint
+	IP        string
+	UserAgent string
+}
+
+// RecordAudit appends an audit event. Pass the transaction performing the change so both are committed together.
+func RecordAudit(db *gorm.DB, context AuditContext, entry AuditEntry) error {
+	changes, err := json.Marshal(AuditChanges(entry.Before, entry.After))
+	if err != nil {
+		return err
+	}
+
+	event := models.AuditEvent{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		ProjectID:  entry.ProjectID,
+		Changes:    string(changes),
+		IP:         context.IP,
+		UserAgent:  context.UserAgent,
+	}
+	if context.ActorID != 0 {
+		event.ActorID = &context.ActorID
+	}
+	return db.Create(&event).Error
+}
+
+// AuditChanges compares the JSON representations of two snapshots field by field
+func AuditChanges(before, after interface{}) map[string]AuditChange {
+	beforeFields, afterFields := auditFields(before), auditFields(after)
+
+	changes := make(map[string]AuditChange)
+	for field, value := range afterFields {
+		if ignoredAuditFields[field] {
+			continue
+		}
+		if previous, ok := beforeFields[field]; !ok || !reflect.DeepEqual(previous, value) {
+			changes[field] = AuditChange{Before: beforeFields[field], After: value}
+		}
+	}
+	for field, value := range beforeFields {
+		if _, ok := afterFields[field]; !ok && !ignoredAuditFields[field] {
+			changes[field] = AuditChange{Before: value}
+		}
+	}
+	return changes
+}
+
+// auditFields flattens a snapshot into its top level JSON fields
+func auditFields(snapshot interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if snapshot == nil {
+		return fields
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// snapshots that aren't objects are compared as a whole
+		var value interface{}
+		json.Unmarshal(data, &value)
+		return map[string]interface{}{"value": value}
+	}
+	return fields
+}
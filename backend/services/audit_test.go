@@ -0,0 +1,32 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/services"
+)
+
+func TestAuditChanges(t *testing.T) {
+	type snapshot struct {
+		Title     string   `json:"title"`
+		Labels    []string `json:"labels"`
+		UpdatedAt string   `json:"updated_at"`
+	}
+
+	before := snapshot{Title: "Draft", Labels: []string{"a"}, UpdatedAt: "yesterday"}
+	after := snapshot{Title: "Draft", Labels: []string{"a", "b"}, UpdatedAt: "today"}
+	changes := services.AuditChanges(before, after)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, []interface{}{"a"}, changes["labels"].Before)
+	assert.Equal(t, []interface{}{"a", "b"}, changes["labels"].After)
+
+	// creations only have after values, deletions only before values
+	created := services.AuditChanges(nil, map[string]string{"title": "New"})
+	assert.Nil(t, created["title"].Before)
+	assert.Equal(t, "New", created["title"].After)
+	deleted := services.AuditChanges(map[string]string{"title": "Old"}, nil)
+	assert.Equal(t, "Old", deleted["title"].Before)
+	assert.Nil(t, deleted["title"].After)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// HasBlocked reports whether blockerID blocked blockedID
+func HasBlocked(db *gorm.DB, blockerID, blockedID uint) bool {
+	var count int64
+	db.Model(&models.UserBlock{}).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Count(&count)
+	return count > 0
+}
+
+// BlockedEitherWay reports whether either user blocked the other
+func BlockedEitherWay(db *gorm.DB, a, b uint) bool {
+	var count int64
+	db.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count)
+	return count > 0
+}
+
+// BlockersOf returns the users who blocked userID
+func BlockersOf(db *gorm.DB, userID uint) ([]uint, error) {
+	var blockers []uint
+	err := db.Model(&models.UserBlock{}).Where("blocked_id = ?", userID).Pluck("blocker_id", &blockers).Error
+	return blockers, err
+}
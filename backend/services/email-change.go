@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"backend/email"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// how long an emailed email change confirmation link stays valid
+const EmailChangeTokenTTL = 24 * time.Hour
+
+var (
+	// ErrInvalidEmailChangeToken is returned for unknown, expired or already used confirmation tokens
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+	// ErrEmailTaken is returned when the new address already belongs to another account
+	ErrEmailTaken = errors.New("email address is already in use")
+)
+
+// EmailInUse reports whether an email address belongs to an account, including deleted ones whose address is still reserved
+func EmailInUse(db *gorm.DB, address string) bool {
+	var count int64
+	db.Unscoped().Model(&models.User{}).Where("email = ?", address).Count(&count)
+	return count > 0
+}
+
+// IssueEmailChange creates a confirmation token for changing a user's email, replacing any earlier unconfirmed requests
+func IssueEmailChange(db *gorm.DB, userID uint, newEmail string, now time.Time) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := db.Where("user_id = ? AND confirmed_at IS NULL", userID).Delete(&models.EmailChangeRequest{}).Error; err != nil {
+		return "", err
+	}
+	err = db.Create(&models.EmailChangeRequest{
+		UserID:    userID,
+		NewEmail:  newEmail,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(EmailChangeTokenTTL),
+	}).Error
+	return token, err
+}
+
+// ConfirmEmailChange redeems a confirmation token: the user's email is replaced and pending invitations addressed to the
+// old email follow the account. Call it within a transaction. Returns the user as it was before the change.
+func ConfirmEmailChange(db *gorm.DB, token string, now time.Time) (models.User, string, error) {
+	var user models.User
+	var request models.EmailChangeRequest
+	err := db.Where("token_hash = ? AND confirmed_at IS NULL AND expires_at > ?", hashToken(token), now).First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, "", ErrInvalidEmailChangeToken
+	}
+	if err != nil {
+		return user, "", err
+	}
+	if err := db.First(&user, request.UserID).Error; err != nil {
+		return user, "", ErrInvalidEmailChangeToken
+	}
+	// the address may have been taken since the change was requested
+	if EmailInUse(db, request.NewEmail) {
+		return user, "", ErrEmailTaken
+	}
+
+	// guard against the same token being redeemed concurrently
+	result := db.Model(&models.EmailChangeRequest{}).Where("id = ? AND confirmed_at IS NULL", request.ID).Update("confirmed_at", now)
+	if result.Error != nil {
+		return user, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user, "", ErrInvalidEmailChangeToken
+	}
+
+	// confirming proves the user receives mail at the new address, which may belong to another institution
+	err = errors.Join(
+		db.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{"email": request.NewEmail, "email_verified_at": now}).Error,
+		db.Model(&models.Invitation{}).Where("email = ? AND status = ?", user.Email, models.InvitationStatusPending).Update("email", request.NewEmail).Error,
+	)
+	if err != nil {
+		return user, "", err
+	}
+	return user, request.NewEmail, RefreshInstitutionMembership(db, user.ID, now)
+}
+
+// SendEmailChangeConfirmation emails the confirmation link to the new address
+func SendEmailChangeConfirmation(newEmail, token string) error {
+	link := utils.GetEnv("APP_BASE_URL", "http://localhost:4200") + "/confirm-email?token=" + url.QueryEscape(token)
+	text := fmt.Sprintf("You asked to use this address for your account.\n\n"+
+		"Open the link below to confirm the change. It is valid for %d hours.\n\n%s\n\n"+
+		"Until you confirm, you keep logging in with your current email address.\n", int(EmailChangeTokenTTL.Hours()), link)
+
+	return email.Sender.Send(email.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Text:    text,
+	})
+}
+
+// SendEmailChangeNotice tells the current address that a change to another address was requested
+func SendEmailChangeNotice(oldEmail, newEmail string) error {
+	text := fmt.Sprintf("Someone asked to change the email address of your account to %s.\n\n"+
+		"The change only takes effect once it is confirmed from that address. If this wasn't you, change your password "+
+		"right away; your current email address keeps working until the change is confirmed.\n", newEmail)
+
+	return email.Sender.Send(email.Message{
+		To:      oldEmail,
+		Subject: "Your email address is being changed",
+		Text:    text,
+	})
+}
@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestConfirmEmailChange(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:email_changes?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Institution{}, &models.InstitutionDomain{}, &models.EmailChangeRequest{}, &models.Invitation{})
+
+	user := models.User{Email: "old@example.com", Password: "hash"}
+	db.Create(&user)
+	now := time.Now()
+
+	first, err := services.IssueEmailChange(db, user.ID, "first@example.com", now)
+	assert.NoError(t, err)
+	second, err := services.IssueEmailChange(db, user.ID, "taken@example.com", now)
+	assert.NoError(t, err)
+
+	// a new request replaces the previous one
+	_, _, err = services.ConfirmEmailChange(db, first, now)
+	assert.ErrorIs(t, err, services.ErrInvalidEmailChangeToken)
+
+	// the address was registered by someone else in the meantime
+	db.Create(&models.User{Email: "taken@example.com", Password: "hash"})
+	_, _, err = services.ConfirmEmailChange(db, second, now)
+	assert.ErrorIs(t, err, services.ErrEmailTaken)
+
+	third, _ := services.IssueEmailChange(db, user.ID, "new@example.com", now)
+	_, _, err = services.ConfirmEmailChange(db, third, now.Add(services.EmailChangeTokenTTL+time.Minute))
+	assert.ErrorIs(t, err, services.ErrInvalidEmailChangeToken)
+
+	previous, newEmail, err := services.ConfirmEmailChange(db, third, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, "old@example.com", previous.Email)
+	assert.Equal(t, "new@example.com", newEmail)
+}
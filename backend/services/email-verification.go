@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/email"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const emailVerificationPurpose = "email-verification"
+
+// how long an emailed verification link stays valid
+const EmailVerificationTokenTTL = 72 * time.Hour
+
+// ErrInvalidEmailVerification is returned for tampered or expired verification tokens, and for tokens
+// issued for an address the account no longer uses
+var ErrInvalidEmailVerification = errors.New("invalid or expired email verification token")
+
+// EmailVerificationToken returns a signed token proving the user received mail at their current address
+func EmailVerificationToken(user models.User, now time.Time) string {
+	return utils.SignValue(emailVerificationPurpose, fmt.Sprintf("%d:%d:%s", user.ID, now.Add(EmailVerificationTokenTTL).Unix(), user.Email))
+}
+
+// VerifyEmail redeems a verification token, marking the address as verified and linking the user to their institution
+func VerifyEmail(db *gorm.DB, token string, now time.Time) (models.User, error) {
+	var user models.User
+	value, ok := utils.VerifySignedValue(emailVerificationPurpose, token)
+	if !ok {
+		return user, ErrInvalidEmailVerification
+	}
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 {
+		return user, ErrInvalidEmailVerification
+	}
+	userID, err1 := strconv.ParseUint(parts[0], 10, 32)
+	expires, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil || now.After(time.Unix(expires, 0)) {
+		return user, ErrInvalidEmailVerification
+	}
+
+	if err := db.First(&user, userID).Error; err != nil || user.Email != parts[2] {
+		return user, ErrInvalidEmailVerification
+	}
+	return user, MarkEmailVerified(db, user.ID, now)
+}
+
+// MarkEmailVerified records that the user receives mail at their current address and refreshes their institution membership
+func MarkEmailVerified(db *gorm.DB, userID uint, now time.Time) error {
+	if err := db.Model(&models.User{}).Where("id = ? AND email_verified_at IS NULL", userID).Update("email_verified_at", now).Error; err != nil {
+		return err
+	}
+	return RefreshInstitutionMembership(db, userID, now)
+}
+
+// SendEmailVerification emails a verification link to the user's current address
+func SendEmailVerification(user models.User, token string) error {
+	link := utils.GetEnv("APP_BASE_URL", "http://localhost:4200") + "/verify-email?token=" + url.QueryEscape(token)
+	text := fmt.Sprintf("Open the link below to verify your email address. It is valid for %d hours.\n\n%s\n\n"+
+		"Verified addresses of a registered institution show a verified membership badge on your profile.\n",
+		int(EmailVerificationTokenTTL.Hours()), link)
+
+	return email.Sender.Send(email.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Text:    text,
+	})
+}
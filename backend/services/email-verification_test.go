@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestEmailVerificationToken(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:email_verification?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Institution{}, &models.InstitutionDomain{})
+	db.Exec("DELETE FROM users")
+
+	now := time.Now()
+	user := models.User{Email: "ada@example.com", Password: "hash"}
+	db.Create(&user)
+	token := services.EmailVerificationToken(user, now)
+
+	_, err = services.VerifyEmail(db, token, now.Add(services.EmailVerificationTokenTTL+time.Minute))
+	assert.ErrorIs(t, err, services.ErrInvalidEmailVerification)
+	_, err = services.VerifyEmail(db, token+"x", now)
+	assert.ErrorIs(t, err, services.ErrInvalidEmailVerification)
+
+	// links for an address the account no longer uses stop working
+	db.Model(&user).Update("email", "ada@elsewhere.com")
+	_, err = services.VerifyEmail(db, token, now)
+	assert.ErrorIs(t, err, services.ErrInvalidEmailVerification)
+
+	var current models.User
+	db.First(&current, user.ID)
+	verified, err := services.VerifyEmail(db, services.EmailVerificationToken(current, now), now)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, verified.ID)
+	db.First(&current, user.ID)
+	assert.NotNil(t, current.EmailVerifiedAt)
+}
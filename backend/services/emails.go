@@ -0,0 +1,248 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"net/url"
+	"sort"
+	texttemplate "text/template"
+	"time"
+
+	"backend/email"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// notifications that were never emailed within this window are dropped instead of sent late
+const maxEmailAge = 14 * 24 * time.Hour
+
+//go:embed templates
+var templateFiles embed.FS
+
+var (
+	htmlEmailTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/notification-email.html"))
+	textEmailTemplate = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/notification-email.txt"))
+)
+
+// EmailWorker batches pending notifications into emails according to each user's preferences
+type EmailWorker struct {
+	DB         *gorm.DB
+	Mailer     email.Mailer
+	APIURL     string // public URL of this API, used for unsubscribe links
+	AppURL     string // URL of the frontend
+	DigestHour int    // hour of the day (UTC) digests are sent at; weekly digests go out on Mondays
+}
+
+// NewEmailWorker creates a worker configured from the environment
+func NewEmailWorker(db *gorm.DB, mailer email.Mailer) *EmailWorker {
+	return &EmailWorker{
+		DB:         db,
+		Mailer:     mailer,
+		APIURL:     utils.GetEnv("API_BASE_URL", "http://localhost:8080"),
+		AppURL:     utils.GetEnv("APP_BASE_URL", "http://localhost:4200"),
+		DigestHour: int(utils.GetEnvInt64("DIGEST_HOUR", 8)),
+	}
+}
+
+// Run delivers pending notifications every interval until ctx is cancelled
+func (w *EmailWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.DeliverPending(time.Now()); err != nil {
+				log.Println("Failed to deliver notification emails:", err)
+			}
+		}
+	}
+}
+
+// lastDailyDigest returns the most recent daily digest time at or before now
+func (w *EmailWorker) lastDailyDigest(now time.Time) time.Time {
+	now = now.UTC()
+	digest := time.Date(now.Year(), now.Month(), now.Day(), w.DigestHour, 0, 0, 0, time.UTC)
+	if digest.After(now) {
+		digest = digest.AddDate(0, 0, -1)
+	}
+	return digest
+}
+
+// lastWeeklyDigest returns the most recent weekly digest time at or before now
+func (w *EmailWorker) lastWeeklyDigest(now time.Time) time.Time {
+	digest := w.lastDailyDigest(now)
+	for digest.Weekday() != time.Monday {
+		digest = digest.AddDate(0, 0, -1)
+	}
+	return digest
+}
+
+// DeliverPending emails every notification that is due at now. Immediate notifications are always due,
+// digest notifications once a digest time has passed since they were created. Because due notifications
+// are derived from the schedule rather than from the last run, missed runs catch up after a restart.
+func (w *EmailWorker) DeliverPending(now time.Time) error {
+	// drop notifications too old to be worth emailing
+	if err := w.DB.Model(&models.Notification{}).
+		Where("emailed_at IS NULL AND created_at < ?", now.Add(-maxEmailAge)).
+		Update("emailed_at", now).Error; err != nil {
+		return err
+	}
+
+	var pending []models.Notification
+	if err := w.DB.Where("emailed_at IS NULL").Order("id").Find(&pending).Error; err != nil {
+		return err
+	}
+
+	byUser := make(map[uint][]models.Notification)
+	for _, notification := range pending {
+		byUser[notification.UserID] = append(byUser[notification.UserID], notification)
+	}
+
+	userIDs := make([]uint, 0, len(byUser))
+	for userID := range byUser {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+
+	for _, userID := range userIDs {
+		if err := w.deliverToUser(userID, byUser[userID], now); err != nil {
+			// keep the notifications pending so the next run retries them
+			log.Printf("Failed to email notifications to user %d: %v", userID, err)
+		}
+	}
+	return nil
+}
+
+func (w *EmailWorker) deliverToUser(userID uint, pending []models.Notification, now time.Time) error {
+	preferences, err := EmailPreferences(w.DB, userID)
+	if err != nil {
+		return err
+	}
+
+	lastDaily, lastWeekly := w.lastDailyDigest(now), w.lastWeeklyDigest(now)
+	var due []models.Notification
+	var skipped []uint
+	digest, weekly := false, false
+	for _, notification := range pending {
+		switch preferences[notification.Type] {
+		case models.EmailFrequencyOff:
+			skipped = append(skipped, notification.ID)
+		case models.EmailFrequencyImmediate:
+			due = append(due, notification)
+		case models.EmailFrequencyWeekly:
+			if notification.CreatedAt.Before(lastWeekly) {
+				due = append(due, notification)
+				digest, weekly = true, true
+			}
+		default:
+			if notification.CreatedAt.Before(lastDaily) {
+				due = append(due, notification)
+				digest = true
+			}
+		}
+	}
+
+	if len(skipped) > 0 {
+		if err := w.DB.Model(&models.Notification{}).Where("id IN ?", skipped).Update("emailed_at", now).Error; err != nil {
+			return err
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	var user models.User
+	if err := w.DB.First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	period := "daily"
+	if weekly {
+		period = "weekly"
+	}
+	message, err := w.composeEmail(user, due, digest, period)
+	if err != nil {
+		return err
+	}
+	if err := w.Mailer.Send(message); err != nil {
+		return err
+	}
+
+	ids := make([]uint, len(due))
+	for i, notification := range due {
+		ids[i] = notification.ID
+	}
+	return w.DB.Model(&models.Notification{}).Where("id IN ?", ids).Update("emailed_at", now).Error
+}
+
+type emailTemplateData struct {
+	Recipient        string
+	Notifications    []models.Notification
+	Digest           bool
+	Period           string
+	AppURL           string
+	UnsubscribeURL   string
+	UnsubscribeLabel string
+}
+
+// composeEmail renders the HTML and text bodies of an email about one or more notifications
+func (w *EmailWorker) composeEmail(user models.User, notifications []models.Notification, digest bool, period string) (email.Message, error) {
+	// unsubscribe from a single type when the email is only about one, otherwise from everything
+	unsubscribeType, label := UnsubscribeAll, "Unsubscribe from all emails"
+	if sameType(notifications) {
+		unsubscribeType, label = string(notifications[0].Type), "Unsubscribe from emails like this"
+	}
+	unsubscribeURL := w.APIURL + "/notifications/unsubscribe?token=" + url.QueryEscape(UnsubscribeToken(user.ID, unsubscribeType))
+
+	data := emailTemplateData{
+		Recipient:        user.Email,
+		Notifications:    notifications,
+		Digest:           digest,
+		Period:           period,
+		AppURL:           w.AppURL,
+		UnsubscribeURL:   unsubscribeURL,
+		UnsubscribeLabel: label,
+	}
+
+	var html, text bytes.Buffer
+	if err := htmlEmailTemplate.Execute(&html, data); err != nil {
+		return email.Message{}, err
+	}
+	if err := textEmailTemplate.Execute(&text, data); err != nil {
+		return email.Message{}, err
+	}
+
+	subject := notifications[0].Message
+	if len(notifications) > 1 {
+		subject = fmt.Sprintf("You have %d new notifications on The Grid", len(notifications))
+	}
+
+	return email.Message{
+		To:      user.Email,
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+func sameType(notifications []models.Notification) bool {
+	for _, notification := range notifications[1:] {
+		if notification.Type != notifications[0].Type {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package services_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/email"
+	"backend/models"
+	"backend/services"
+)
+
+type fakeMailer struct {
+	sent []email.Message
+	err  error
+}
+
+func (m *fakeMailer) Send(message email.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+func setupEmailTest(t *testing.T) (*gorm.DB, *fakeMailer, *services.EmailWorker, models.User) {
+	db, err := gorm.Open(sqlite.Open("file:emails?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.Notification{}, &models.NotificationPreference{})
+	db.Exec("DELETE FROM notification_preferences")
+	db.Exec("DELETE FROM notifications")
+	db.Exec("DELETE FROM users")
+
+	user := models.User{Email: "digest@example.com", Password: "password"}
+	db.Create(&user)
+
+	mailer := &fakeMailer{}
+	worker := &services.EmailWorker{DB: db, Mailer: mailer, APIURL: "http://api.test", AppURL: "http://app.test", DigestHour: 8}
+	return db, mailer, worker, user
+}
+
+func createNotification(db *gorm.DB, userID uint, notificationType models.NotificationType, message string, createdAt time.Time) {
+	notification := models.Notification{UserID: userID, Type: notificationType, Message: message}
+	notification.CreatedAt = createdAt
+	db.Create(&notification)
+}
+
+func TestEmailDigestSchedule(t *testing.T) {
+	db, mailer, worker, user := setupEmailTest(t)
+	assert.NoError(t, services.SetEmailPreference(db, user.ID, models.NotificationTaskAssigned, models.EmailFrequencyWeekly))
+	assert.NoError(t, services.SetEmailPreference(db, user.ID, models.NotificationRoleChanged, models.EmailFrequencyOff))
+
+	// Wednesday 2024-05-15, 10:00 UTC
+	created := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	createNotification(db, user.ID, models.NotificationInvitationReceived, "You were invited", created)
+	createNotification(db, user.ID, models.NotificationMention, "You were mentioned", created)
+	createNotification(db, user.ID, models.NotificationTaskAssigned, "You were assigned", created)
+	createNotification(db, user.ID, models.NotificationRoleChanged, "Your role changed", created)
+
+	// invitations go out right away
+	assert.NoError(t, worker.DeliverPending(created.Add(time.Minute)))
+	assert.Len(t, mailer.sent, 1)
+	assert.Equal(t, "You were invited", mailer.sent[0].Subject)
+	assert.Equal(t, user.Email, mailer.sent[0].To)
+
+	// mentions wait for the next daily digest
+	assert.NoError(t, worker.DeliverPending(time.Date(2024, 5, 16, 7, 59, 0, 0, time.UTC)))
+	assert.Len(t, mailer.sent, 1)
+	assert.NoError(t, worker.DeliverPending(time.Date(2024, 5, 16, 8, 0, 0, 0, time.UTC)))
+	assert.Len(t, mailer.sent, 2)
+	assert.Contains(t, mailer.sent[1].Text, "daily summary")
+	assert.Contains(t, mailer.sent[1].HTML, "You were mentioned")
+	assert.NotContains(t, mailer.sent[1].Text, "You were assigned")
+
+	// weekly digests go out on Monday
+	assert.NoError(t, worker.DeliverPending(time.Date(2024, 5, 19, 9, 0, 0, 0, time.UTC)))
+	assert.Len(t, mailer.sent, 2)
+	assert.NoError(t, worker.DeliverPending(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)))
+	assert.Len(t, mailer.sent, 3)
+	assert.Contains(t, mailer.sent[2].Text, "weekly summary")
+	assert.Contains(t, mailer.sent[2].Text, "You were assigned")
+
+	// notifications with email turned off are never sent
+	for _, message := range mailer.sent {
+		assert.NotContains(t, message.Text, "Your role changed")
+	}
+	var pending int64
+	db.Model(&models.Notification{}).Where("emailed_at IS NULL").Count(&pending)
+	assert.Equal(t, int64(0), pending)
+}
+
+func TestEmailUnsubscribeLink(t *testing.T) {
+	db, mailer, worker, user := setupEmailTest(t)
+	now := time.Now()
+	createNotification(db, user.ID, models.NotificationInvitationReceived, "You were invited", now)
+
+	assert.NoError(t, worker.DeliverPending(now))
+	assert.Len(t, mailer.sent, 1)
+
+	link := strings.Trim(mailer.sent[0].Headers["List-Unsubscribe"], "<>")
+	parsed, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "/notifications/unsubscribe", parsed.Path)
+
+	userID, notificationType, ok := services.ParseUnsubscribeToken(parsed.Query().Get("token"))
+	assert.True(t, ok)
+	assert.Equal(t, user.ID, userID)
+	assert.Equal(t, string(models.NotificationInvitationReceived), notificationType)
+}
+
+func TestEmailFailuresAreRetried(t *testing.T) {
+	db, mailer, worker, user := setupEmailTest(t)
+	now := time.Now()
+	createNotification(db, user.ID, models.NotificationInvitationReceived, "You were invited", now)
+
+	mailer.err = errors.New("relay unavailable")
+	assert.NoError(t, worker.DeliverPending(now))
+	assert.Len(t, mailer.sent, 0)
+
+	mailer.err = nil
+	assert.NoError(t, worker.DeliverPending(now.Add(time.Minute)))
+	assert.Len(t, mailer.sent, 1)
+}
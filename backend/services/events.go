@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// types of events pushed to connected clients
+const (
+	EventNotification              = "notification"
+	EventCollaboratorAdded         = "project.collaborator_added"
+	EventCollaboratorRoleChanged   = "project.collaborator_role_changed"
+	EventCollaboratorStatusChanged = "project.collaborator_status_changed"
+	EventMessage                   = "message"
+	// tells a reconnected client it missed too much to replay and should refetch instead
+	EventReset = "reset"
+)
+
+const streamTicketPurpose = "stream-ticket"
+
+// StreamTicketTTL is how long a stream ticket can be used to open an event stream
+const StreamTicketTTL = time.Minute
+
+// ErrInvalidStreamTicket is returned for tampered or expired stream tickets
+var ErrInvalidStreamTicket = errors.New("invalid or expired stream ticket")
+
+// StreamTicket issues a ticket that opens the user's event stream for StreamTicketTTL. Clients that can't set
+// headers pass it in the URL instead of their token, which would stay usable for any request if it leaked from logs.
+func StreamTicket(userID, sessionID uint, now time.Time) string {
+	return utils.SignValue(streamTicketPurpose, fmt.Sprintf("%d:%d:%d", userID, sessionID, now.Unix()))
+}
+
+// ParseStreamTicket verifies a ticket created by StreamTicket and returns when it was issued
+func ParseStreamTicket(ticket string, now time.Time) (userID, sessionID uint, issuedAt time.Time, err error) {
+	value, ok := utils.VerifySignedValue(streamTicketPurpose, ticket)
+	if !ok {
+		return 0, 0, time.Time{}, ErrInvalidStreamTicket
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, 0, time.Time{}, ErrInvalidStreamTicket
+	}
+	user, err1 := strconv.ParseUint(parts[0], 10, 32)
+	session, err2 := strconv.ParseUint(parts[1], 10, 32)
+	issued, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || now.After(time.Unix(issued, 0).Add(StreamTicketTTL)) {
+		return 0, 0, time.Time{}, ErrInvalidStreamTicket
+	}
+	return uint(user), uint(session), time.Unix(issued, 0), nil
+}
+
+// CollaboratorEvent describes a change to the members of a project
+type CollaboratorEvent struct {
+	ProjectID uint   `json:"project_id"`
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+	Status    string `json:"status,omitempty"`
+}
+
+// PublishProjectEvent pushes an event to every connected member of a project.
+// Project events are not stored and can't be replayed, so callers should publish them after committing.
+func PublishProjectEvent(db *gorm.DB, projectID uint, eventType string, data interface{}) {
+	var project models.Project
+	if err := db.First(&project, projectID).Error; err != nil {
+		return
+	}
+
+	var memberIDs []uint
+	db.Model(&models.Collaborator{}).Where("project_id = ?", projectID).Pluck("user_id", &memberIDs)
+	if project.GroupID != nil {
+		var groupMemberIDs []uint
+		db.Model(&models.GroupMember{}).Where("group_id = ?", *project.GroupID).Pluck("user_id", &groupMemberIDs)
+		memberIDs = append(memberIDs, groupMemberIDs...)
+	}
+	if !slices.Contains(memberIDs, project.OwnerID) {
+		memberIDs = append(memberIDs, project.OwnerID)
+	}
+	slices.Sort(memberIDs)
+	memberIDs = slices.Compact(memberIDs)
+
+	event := Event{Type: eventType, Data: data}
+	for _, memberID := range memberIDs {
+		Events.Publish(memberID, event)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrLastGroupPI is returned when a change would leave a group without a PI
+var ErrLastGroupPI = errors.New("a group needs at least one PI")
+
+// GroupRoleOf returns the role a user holds in a group, or an empty role if they aren't a member
+func GroupRoleOf(db *gorm.DB, groupID, userID uint) models.GroupRole {
+	var member models.GroupMember
+	if err := db.Where("group_id = ? AND user_id = ?", groupID, userID).First(&member).Error; err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// GroupProjectRole returns the project role a user inherits on projects of a group: PIs and managers are
+// treated as owners and other members get the group's default project role. Non-members get an empty role.
+func GroupProjectRole(db *gorm.DB, groupID, userID uint) models.CollaboratorRole {
+	role := GroupRoleOf(db, groupID, userID)
+	if role == "" {
+		return ""
+	}
+	if role.AtLeast(models.GroupRoleManager) {
+		return models.CollaboratorRoleOwner
+	}
+	var group models.Group
+	if err := db.First(&group, groupID).Error; err != nil {
+		return ""
+	}
+	return group.DefaultProjectRole
+}
+
+// GroupProjectIDs selects the projects owned by groups the user is a member of
+func GroupProjectIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Model(&models.Project{}).Select("id").
+		Where("group_id IN (?)", db.Model(&models.GroupMember{}).Select("group_id").Where("user_id = ?", userID))
+}
+
+// CheckGroupKeepsPI returns ErrLastGroupPI when removing or demoting the member would leave the group without a PI
+func CheckGroupKeepsPI(db *gorm.DB, member models.GroupMember) error {
+	if member.Role != models.GroupRolePI {
+		return nil
+	}
+	var others int64
+	err := db.Model(&models.GroupMember{}).Where("group_id = ? AND role = ? AND id <> ?", member.GroupID, models.GroupRolePI, member.ID).Count(&others).Error
+	if err != nil {
+		return err
+	}
+	if others == 0 {
+		return ErrLastGroupPI
+	}
+	return nil
+}
+
+// NotifyGroupMembership tells a user they were added to a group or their role in it changed
+func NotifyGroupMembership(db *gorm.DB, group models.Group, userID, actorID uint, role models.GroupRole, added bool) (*models.Notification, error) {
+	message := fmt.Sprintf("Your role in %q is now %s", group.Name, role)
+	if added {
+		message = fmt.Sprintf("You were added to %q as %s", group.Name, role)
+	}
+	return Notify(db, models.Notification{
+		UserID:  userID,
+		ActorID: &actorID,
+		Type:    models.NotificationGroupMembership,
+		Message: message,
+		Link:    fmt.Sprintf("/groups/%d", group.ID),
+	})
+}
@@ -0,0 +1,52 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestGroupProjectRole(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:groups?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Group{}, &models.GroupMember{}, &models.Project{})
+	for _, table := range []string{"group_members", "groups", "projects"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	group := models.Group{Name: "Systems Lab", DefaultProjectRole: models.CollaboratorRoleEditor}
+	db.Create(&group)
+	pi := models.GroupMember{GroupID: group.ID, UserID: 1, Role: models.GroupRolePI}
+	manager := models.GroupMember{GroupID: group.ID, UserID: 2, Role: models.GroupRoleManager}
+	member := models.GroupMember{GroupID: group.ID, UserID: 3, Role: models.GroupRoleMember}
+	db.Create(&pi)
+	db.Create(&manager)
+	db.Create(&member)
+
+	assert.Equal(t, models.CollaboratorRoleOwner, services.GroupProjectRole(db, group.ID, pi.UserID))
+	assert.Equal(t, models.CollaboratorRoleOwner, services.GroupProjectRole(db, group.ID, manager.UserID))
+	assert.Equal(t, models.CollaboratorRoleEditor, services.GroupProjectRole(db, group.ID, member.UserID))
+	assert.Equal(t, models.CollaboratorRole(""), services.GroupProjectRole(db, group.ID, 4))
+
+	project := models.Project{Title: "Kernel", OwnerID: manager.UserID, GroupID: &group.ID}
+	db.Create(&project)
+	var ids []uint
+	db.Model(&models.Project{}).Where("id IN (?)", services.GroupProjectIDs(db, member.UserID)).Pluck("id", &ids)
+	assert.Equal(t, []uint{project.ID}, ids)
+	ids = nil
+	db.Model(&models.Project{}).Where("id IN (?)", services.GroupProjectIDs(db, 4)).Pluck("id", &ids)
+	assert.Empty(t, ids)
+
+	// the only PI can't leave until another member is promoted
+	assert.ErrorIs(t, services.CheckGroupKeepsPI(db, pi), services.ErrLastGroupPI)
+	assert.NoError(t, services.CheckGroupKeepsPI(db, manager))
+	db.Model(&manager).Update("role", models.GroupRolePI)
+	assert.NoError(t, services.CheckGroupKeepsPI(db, pi))
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"sync"
+)
+
+// size of each subscription's buffer; subscribers that fall further behind are disconnected
+const subscriptionBuffer = 32
+
+// Event is a message pushed to connected clients.
+// Events with an ID can be replayed after a reconnect, events without one are best effort.
+type Event struct {
+	ID   uint
+	Type string
+	Data interface{}
+}
+
+// Subscription receives the events published to a single user
+type Subscription struct {
+	UserID uint
+	Events <-chan Event
+	events chan Event
+}
+
+// Hub fans events out to every connection of a user
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[*Subscription]struct{}
+}
+
+// Events is the hub shared by the whole application
+var Events = NewHub()
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[uint]map[*Subscription]struct{})}
+}
+
+// Subscribe registers a new connection of a user
+func (h *Hub) Subscribe(userID uint) *Subscription {
+	events := make(chan Event, subscriptionBuffer)
+	subscription := &Subscription{UserID: userID, Events: events, events: events}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe removes a connection and closes its event channel. Calling it more than once is safe.
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscriptions := h.subscribers[subscription.UserID]
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(h.subscribers, subscription.UserID)
+	}
+	close(subscription.events)
+}
+
+// Publish delivers an event to every connection of a user without blocking.
+// Connections whose buffer is full are closed so the client reconnects and replays what it missed.
+func (h *Hub) Publish(userID uint, event Event) {
+	var stalled []*Subscription
+
+	h.mu.RLock()
+	for subscription := range h.subscribers[userID] {
+		select {
+		case subscription.events <- event:
+		default:
+			stalled = append(stalled, subscription)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, subscription := range stalled {
+		h.Unsubscribe(subscription)
+	}
+}
+
+// SubscriberCount returns the number of open connections of a user
+func (h *Hub) SubscriberCount(userID uint) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[userID])
+}
@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/services"
+)
+
+func TestHubFanOut(t *testing.T) {
+	hub := services.NewHub()
+	first := hub.Subscribe(1)
+	second := hub.Subscribe(1)
+	other := hub.Subscribe(2)
+
+	hub.Publish(1, services.Event{ID: 7, Type: "notification"})
+
+	assert.Equal(t, uint(7), (<-first.Events).ID)
+	assert.Equal(t, uint(7), (<-second.Events).ID)
+	assert.Len(t, other.Events, 0)
+
+	hub.Unsubscribe(first)
+	hub.Unsubscribe(first)
+	assert.Equal(t, 1, hub.SubscriberCount(1))
+
+	_, open := <-first.Events
+	assert.False(t, open)
+}
+
+func TestHubDropsStalledSubscribers(t *testing.T) {
+	hub := services.NewHub()
+	stalled := hub.Subscribe(1)
+
+	// never read from the subscription until its buffer overflows
+	for i := 0; i < 100; i++ {
+		hub.Publish(1, services.Event{ID: uint(i + 1)})
+	}
+	assert.Equal(t, 0, hub.SubscriberCount(1))
+
+	// buffered events are still readable before the channel reports closed
+	received := 0
+	for range stalled.Events {
+		received++
+	}
+	assert.Greater(t, received, 0)
+	assert.Less(t, received, 100)
+}
+
+func TestHubConcurrentAccess(t *testing.T) {
+	hub := services.NewHub()
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			subscription := hub.Subscribe(1)
+			for j := 0; j < 10; j++ {
+				select {
+				case <-subscription.Events:
+				default:
+				}
+			}
+			hub.Unsubscribe(subscription)
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				hub.Publish(1, services.Event{Type: "ping"})
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 0, hub.SubscriberCount(1))
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+// ErrInvalidDomain is returned for strings that aren't email domains
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// NormalizeDomain lowercases a domain and strips a leading "@", so both "UFL.edu" and "@ufl.edu" become "ufl.edu"
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+	if !domainPattern.MatchString(domain) {
+		return "", ErrInvalidDomain
+	}
+	return domain, nil
+}
+
+// MatchInstitution finds the institution an email address belongs to. The most specific domain wins, so a
+// department registered as its own institution under cs.example.edu takes precedence over example.edu.
+func MatchInstitution(db *gorm.DB, address string) (*models.Institution, error) {
+	_, host, found := strings.Cut(strings.ToLower(address), "@")
+	if !found || host == "" {
+		return nil, nil
+	}
+
+	// the host itself and every parent domain, e.g. cise.ufl.edu, ufl.edu and edu
+	var candidates []string
+	for domain := host; domain != ""; {
+		candidates = append(candidates, domain)
+		_, parent, found := strings.Cut(domain, ".")
+		if !found {
+			break
+		}
+		domain = parent
+	}
+
+	var domains []models.InstitutionDomain
+	if err := db.Where("domain IN ?", candidates).Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, nil
+	}
+	best := domains[0]
+	for _, domain := range domains[1:] {
+		if len(domain.Domain) > len(best.Domain) {
+			best = domain
+		}
+	}
+
+	var institution models.Institution
+	if err := db.First(&institution, best.InstitutionID).Error; err != nil {
+		return nil, err
+	}
+	return &institution, nil
+}
+
+// RefreshInstitutionMembership links a user's profile to the institution of their verified email address,
+// or unlinks it when the address is unverified or belongs to no institution
+func RefreshInstitutionMembership(db *gorm.DB, userID uint, now time.Time) error {
+	var user models.User
+	if err := db.Preload("Profile").First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var institution *models.Institution
+	if user.EmailVerifiedAt != nil {
+		var err error
+		if institution, err = MatchInstitution(db, user.Email); err != nil {
+			return err
+		}
+	}
+
+	updates := map[string]interface{}{"institution_id": nil, "institution_verified_at": nil}
+	if institution != nil {
+		updates["institution_id"] = institution.ID
+		updates["institution_verified_at"] = now
+		// membership of the same institution keeps its original verification time
+		if user.Profile.InstitutionID != nil && *user.Profile.InstitutionID == institution.ID {
+			updates["institution_verified_at"] = user.Profile.InstitutionVerifiedAt
+		}
+	}
+	return db.Model(&models.UserProfile{}).Where("user_id = ?", userID).Updates(updates).Error
+}
+
+// RefreshInstitutionMembers updates the membership of everyone affected by a change to an institution's domains:
+// current members and users with a verified address in any of the given domains
+func RefreshInstitutionMembers(db *gorm.DB, institutionID uint, domains []string, now time.Time) error {
+	query := db.Model(&models.User{}).Where("id IN (?)", db.Model(&models.UserProfile{}).Select("user_id").Where("institution_id = ?", institutionID))
+	for _, domain := range domains {
+		query = query.Or("email_verified_at IS NOT NULL AND (LOWER(email) LIKE ? OR LOWER(email) LIKE ?)", "%@"+domain, "%."+domain)
+	}
+
+	var userIDs []uint
+	if err := query.Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := RefreshInstitutionMembership(db, userID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	for input, expected := range map[string]string{"ufl.edu": "ufl.edu", " @CISE.UFL.edu ": "cise.ufl.edu", "ox.ac.uk": "ox.ac.uk"} {
+		domain, err := services.NormalizeDomain(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, domain)
+	}
+	for _, input := range []string{"", "edu", "ufl edu", "-ufl.edu", "ufl.edu/path", "user@ufl.edu"} {
+		_, err := services.NormalizeDomain(input)
+		assert.ErrorIs(t, err, services.ErrInvalidDomain, input)
+	}
+}
+
+func TestInstitutionMembership(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:institutions?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Institution{}, &models.InstitutionDomain{})
+	for _, table := range []string{"users", "user_profiles", "institutions", "institution_domains"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	university := models.Institution{Name: "University of Florida", Domains: []models.InstitutionDomain{{Domain: "ufl.edu"}}}
+	department := models.Institution{Name: "UF Computer Science", Domains: []models.InstitutionDomain{{Domain: "cise.ufl.edu"}}}
+	db.Create(&university)
+	db.Create(&department)
+
+	// the most specific domain wins
+	match, err := services.MatchInstitution(db, "ada@cise.ufl.edu")
+	assert.NoError(t, err)
+	if assert.NotNil(t, match) {
+		assert.Equal(t, department.ID, match.ID)
+	}
+	match, _ = services.MatchInstitution(db, "ada@med.ufl.edu")
+	if assert.NotNil(t, match) {
+		assert.Equal(t, university.ID, match.ID)
+	}
+	match, _ = services.MatchInstitution(db, "ada@notufl.edu")
+	assert.Nil(t, match)
+
+	now := time.Now()
+	user := models.User{Email: "ada@med.ufl.edu", Password: "hash"}
+	db.Create(&user)
+	db.Create(&models.UserProfile{UserID: user.ID})
+
+	// unverified addresses don't count
+	assert.NoError(t, services.RefreshInstitutionMembership(db, user.ID, now))
+	var profile models.UserProfile
+	db.Where("user_id = ?", user.ID).First(&profile)
+	assert.Nil(t, profile.InstitutionID)
+
+	assert.NoError(t, services.MarkEmailVerified(db, user.ID, now))
+	db.Where("user_id = ?", user.ID).First(&profile)
+	if assert.NotNil(t, profile.InstitutionID) {
+		assert.Equal(t, university.ID, *profile.InstitutionID)
+	}
+	verifiedAt := *profile.InstitutionVerifiedAt
+
+	// refreshing keeps the original verification time
+	assert.NoError(t, services.RefreshInstitutionMembership(db, user.ID, now.Add(time.Hour)))
+	db.Where("user_id = ?", user.ID).First(&profile)
+	assert.WithinDuration(t, verifiedAt, *profile.InstitutionVerifiedAt, time.Second)
+
+	// members lose their membership once the domain is removed
+	db.Where("institution_id = ?", university.ID).Delete(&models.InstitutionDomain{})
+	assert.NoError(t, services.RefreshInstitutionMembers(db, university.ID, []string{"ufl.edu"}, now))
+	db.Where("user_id = ?", user.ID).First(&profile)
+	assert.Nil(t, profile.InstitutionID)
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/email"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const loginReportPurpose = "login-report"
+
+// how long the "this wasn't me" link of an unfamiliar login email stays valid
+const LoginReportTTL = 7 * 24 * time.Hour
+
+// how many earlier successful logins are compared against to decide whether a login is unfamiliar
+const familiarLoginHistory = 100
+
+// ErrInvalidLoginReport is returned for tampered or expired "this wasn't me" links
+var ErrInvalidLoginReport = errors.New("invalid or expired link")
+
+// RecordFailedLogin records a failed login to an existing account
+func RecordFailedLogin(db *gorm.DB, userID uint, userAgent, ipAddress string, reason models.LoginFailureReason) error {
+	return db.Create(&models.LoginAttempt{
+		UserID:        userID,
+		UserAgent:     userAgent,
+		IPAddress:     ipAddress,
+		FailureReason: reason,
+	}).Error
+}
+
+// RecordSuccessfulLogin records a login and flags it as unfamiliar when the device or network wasn't used
+// for any of the account's recent logins. The very first login of an account is never unfamiliar.
+func RecordSuccessfulLogin(db *gorm.DB, userID uint, userAgent, ipAddress string) (models.LoginAttempt, error) {
+	var previous []models.LoginAttempt
+	err := db.Select("user_agent", "ip_address").Where("user_id = ? AND succeeded = ?", userID, true).
+		Order("id DESC").Limit(familiarLoginHistory).Find(&previous).Error
+	if err != nil {
+		return models.LoginAttempt{}, err
+	}
+
+	knownDevice, knownNetwork := false, false
+	for _, login := range previous {
+		knownDevice = knownDevice || DeviceName(login.UserAgent) == DeviceName(userAgent)
+		knownNetwork = knownNetwork || networkPrefix(login.IPAddress) == networkPrefix(ipAddress)
+	}
+
+	attempt := models.LoginAttempt{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		Succeeded:  true,
+		Unfamiliar: len(previous) > 0 && !(knownDevice && knownNetwork),
+	}
+	err = db.Create(&attempt).Error
+	return attempt, err
+}
+
+// networkPrefix stands in for the location of an IP address: its /16 network for IPv4 and /48 for IPv6
+func networkPrefix(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ipAddress
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(16, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// LoginReportToken returns the signed token of the "this wasn't me" link for a login
+func LoginReportToken(attempt models.LoginAttempt, now time.Time) string {
+	return utils.SignValue(loginReportPurpose, fmt.Sprintf("%d:%d:%d", attempt.ID, attempt.UserID, now.Add(LoginReportTTL).Unix()))
+}
+
+// ParseLoginReportToken verifies a token created by LoginReportToken
+func ParseLoginReportToken(token string, now time.Time) (attemptID, userID uint, err error) {
+	value, ok := utils.VerifySignedValue(loginReportPurpose, token)
+	if !ok {
+		return 0, 0, ErrInvalidLoginReport
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidLoginReport
+	}
+	attempt, err1 := strconv.ParseUint(parts[0], 10, 32)
+	user, err2 := strconv.ParseUint(parts[1], 10, 32)
+	expires, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || now.After(time.Unix(expires, 0)) {
+		return 0, 0, ErrInvalidLoginReport
+	}
+	return uint(attempt), uint(user), nil
+}
+
+// NotifyUnfamiliarLogin tells the user about a login from a new device or network, both in the app and by email.
+// The email goes out right away regardless of notification preferences and carries a link to report the login.
+// The notification is returned even if the email fails.
+func NotifyUnfamiliarLogin(db *gorm.DB, user models.User, attempt models.LoginAttempt) (*models.Notification, error) {
+	now := time.Now()
+	device := DeviceName(attempt.UserAgent)
+	notification, err := Notify(db, models.Notification{
+		UserID:    user.ID,
+		Type:      models.NotificationUnfamiliarLogin,
+		Message:   fmt.Sprintf("New login from %s (%s)", device, attempt.IPAddress),
+		Link:      "/users/me/login-history",
+		EmailedAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link := utils.GetEnv("API_BASE_URL", "http://localhost:8080") + "/auth/not-me?token=" + url.QueryEscape(LoginReportToken(attempt, now))
+	text := fmt.Sprintf("Your account was just logged in to from a device or network we haven't seen before.\n\n"+
+		"When: %s\nDevice: %s\nIP address: %s\n\n"+
+		"If this was you, there is nothing to do.\n\n"+
+		"If it wasn't, open the link below to secure your account. Confirming there signs out every session and requires a new password before anyone can log in again:\n\n%s\n",
+		attempt.CreatedAt.UTC().Format("January 2, 2006 15:04 UTC"), device, attempt.IPAddress, link)
+
+	return notification, email.Sender.Send(email.Message{
+		To:      user.Email,
+		Subject: "New login to your account",
+		Text:    text,
+	})
+}
+
+// LockAccountAfterReport handles a login reported as not being the user: it signs out every session and requires
+// a password reset. It returns the reset token to email, or an empty token when the login was already reported.
+func LockAccountAfterReport(db *gorm.DB, attemptID, userID uint, now time.Time) (string, error) {
+	result := db.Model(&models.LoginAttempt{}).Where("id = ? AND user_id = ? AND reported_at IS NULL", attemptID, userID).Update("reported_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return "", result.Error
+	}
+
+	err := errors.Join(
+		db.Model(&models.User{}).Where("id = ?", userID).Update("password_reset_required", true).Error,
+		EndOtherSessions(db, userID, 0, now),
+	)
+	if err != nil {
+		return "", err
+	}
+	return IssuePasswordReset(db, userID, now)
+}
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestRecordSuccessfulLogin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:login_history?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.LoginAttempt{})
+	db.Exec("DELETE FROM login_attempts")
+
+	firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+	newerFirefox := "Mozilla/5.0 (X11; Linux x86_64; rv:130.0) Gecko/20100101 Firefox/130.0"
+	chrome := "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+
+	first, err := services.RecordSuccessfulLogin(db, 1, firefox, "198.51.100.7")
+	assert.NoError(t, err)
+	assert.False(t, first.Unfamiliar)
+
+	// failed logins don't make a device familiar
+	assert.NoError(t, services.RecordFailedLogin(db, 1, chrome, "198.51.100.7", models.LoginFailureInvalidPassword))
+
+	tests := []struct {
+		name       string
+		userAgent  string
+		ipAddress  string
+		unfamiliar bool
+	}{
+		{"browser update on the same network", newerFirefox, "198.51.23.4", false},
+		{"another browser", chrome, "198.51.100.7", true},
+		{"another network", firefox, "203.0.113.9", true},
+		{"ipv6", firefox, "2001:db8:1234::1", true},
+		{"same ipv6 network", firefox, "2001:db8:1234:ffff::2", false},
+	}
+	for _, test := range tests {
+		attempt, err := services.RecordSuccessfulLogin(db, 1, test.userAgent, test.ipAddress)
+		assert.NoError(t, err)
+		assert.Equal(t, test.unfamiliar, attempt.Unfamiliar, test.name)
+	}
+
+	// history is kept per account
+	other, _ := services.RecordSuccessfulLogin(db, 2, chrome, "203.0.113.9")
+	assert.False(t, other.Unfamiliar)
+}
+
+func TestLoginReportToken(t *testing.T) {
+	now := time.Now()
+	attempt := models.LoginAttempt{ID: 12, UserID: 3}
+	token := services.LoginReportToken(attempt, now)
+
+	attemptID, userID, err := services.ParseLoginReportToken(token, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(12), attemptID)
+	assert.Equal(t, uint(3), userID)
+
+	_, _, err = services.ParseLoginReportToken(token, now.Add(services.LoginReportTTL+time.Minute))
+	assert.ErrorIs(t, err, services.ErrInvalidLoginReport)
+	_, _, err = services.ParseLoginReportToken(token+"x", now)
+	assert.ErrorIs(t, err, services.ErrInvalidLoginReport)
+	_, _, err = services.ParseLoginReportToken(services.UnsubscribeToken(3, "mention"), now)
+	assert.ErrorIs(t, err, services.ErrInvalidLoginReport)
+}
+
+func TestLockAccountAfterReport(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:login_reports?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.LoginAttempt{}, &models.Session{}, &models.PasswordResetToken{})
+	for _, table := range []string{"users", "login_attempts", "sessions", "password_reset_tokens"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	now := time.Now()
+	user := models.User{Email: "owner@example.com", Password: "hash"}
+	db.Create(&user)
+	services.StartSession(db, user.ID, "", "", now)
+	attempt, _ := services.RecordSuccessfulLogin(db, user.ID, "", "")
+
+	// reports are bound to the account the login belongs to
+	token, err := services.LockAccountAfterReport(db, attempt.ID, user.ID+1, now)
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+
+	token, err = services.LockAccountAfterReport(db, attempt.ID, user.ID, now)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	var locked models.User
+	db.First(&locked, user.ID)
+	assert.True(t, locked.PasswordResetRequired)
+	sessions, _ := services.ActiveSessions(db, user.ID, now)
+	assert.Empty(t, sessions)
+
+	// a second report of the same login does nothing
+	token, err = services.LockAccountAfterReport(db, attempt.ID, user.ID, now)
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+}
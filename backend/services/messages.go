@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMessagingBlocked is returned when either user blocked the other
+	ErrMessagingBlocked = errors.New("messaging is blocked between these users")
+	// ErrMessagingNotAllowed is returned when the recipient's privacy settings don't allow the sender to message them
+	ErrMessagingNotAllowed = errors.New("recipient does not accept messages from this user")
+)
+
+// SharesProject reports whether two users are members, owner, collaborator or member of the owning group, of a common project
+func SharesProject(db *gorm.DB, a, b uint) bool {
+	memberProjects := func(userID uint) *gorm.DB {
+		return db.Model(&models.Project{}).Select("id").
+			Where("owner_id = ? OR id IN (?) OR id IN (?)", userID,
+				db.Model(&models.Collaborator{}).Select("project_id").Where("user_id = ?", userID), GroupProjectIDs(db, userID))
+	}
+
+	var count int64
+	db.Model(&models.Project{}).Where("id IN (?) AND id IN (?)", memberProjects(a), memberProjects(b)).Count(&count)
+	return count > 0
+}
+
+// CanMessage checks whether sender may start a conversation with recipient
+func CanMessage(db *gorm.DB, senderID uint, recipient models.User) error {
+	if recipient.DisabledAt != nil {
+		return ErrMessagingNotAllowed
+	}
+	if BlockedEitherWay(db, senderID, recipient.ID) {
+		return ErrMessagingBlocked
+	}
+
+	privacy := models.MessagePrivacyEveryone
+	var profile models.UserProfile
+	if err := db.Where("user_id = ?", recipient.ID).First(&profile).Error; err == nil && profile.MessagePrivacy != "" {
+		privacy = profile.MessagePrivacy
+	}
+
+	switch privacy {
+	case models.MessagePrivacyNobody:
+		return ErrMessagingNotAllowed
+	case models.MessagePrivacyCollaborators:
+		if !SharesProject(db, senderID, recipient.ID) {
+			return ErrMessagingNotAllowed
+		}
+	}
+	return nil
+}
+
+// NotifyNewMessage notifies the other participants of a conversation about a new message. Participants who muted the
+// conversation or still have earlier unread messages in it are skipped, so a burst of messages creates one notification.
+func NotifyNewMessage(db *gorm.DB, conversation models.Conversation, message models.Message) ([]*models.Notification, error) {
+	var participants []models.ConversationParticipant
+	if err := db.Where("conversation_id = ? AND user_id <> ? AND muted = ?", conversation.ID, message.SenderID, false).Find(&participants).Error; err != nil {
+		return nil, err
+	}
+
+	var notifications []*models.Notification
+	for _, participant := range participants {
+		var unread int64
+		db.Model(&models.Message{}).
+			Where("conversation_id = ? AND id > ? AND id < ? AND sender_id <> ?", conversation.ID, participant.LastReadMessageID, message.ID, participant.UserID).
+			Count(&unread)
+		if unread > 0 {
+			continue
+		}
+
+		notification, err := Notify(db, models.Notification{
+			UserID:  participant.UserID,
+			ActorID: &message.SenderID,
+			Type:    models.NotificationMessage,
+			Message: "You have a new message",
+			Link:    fmt.Sprintf("/conversations/%d", conversation.ID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+// PublishMessage pushes a new message to every connected participant of its conversation, including the sender's other sessions.
+// Like project events, message events are not replayed, so callers should publish them after committing.
+func PublishMessage(db *gorm.DB, message models.Message) {
+	var participantIDs []uint
+	db.Model(&models.ConversationParticipant{}).Where("conversation_id = ?", message.ConversationID).Pluck("user_id", &participantIDs)
+
+	event := Event{Type: EventMessage, Data: message}
+	for _, participantID := range participantIDs {
+		Events.Publish(participantID, event)
+	}
+}
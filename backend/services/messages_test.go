@@ -0,0 +1,55 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestCanMessage(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:messaging?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Project{}, &models.Collaborator{}, &models.UserBlock{}, &models.GroupMember{})
+	for _, table := range []string{"user_blocks", "collaborators", "projects", "user_profiles", "users"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	sender := models.User{Email: "sender@example.com", Password: "password"}
+	recipient := models.User{Email: "recipient@example.com", Password: "password"}
+	db.Create(&sender)
+	db.Create(&recipient)
+
+	// users without a profile accept messages from everyone
+	assert.NoError(t, services.CanMessage(db, sender.ID, recipient))
+
+	db.Create(&models.UserProfile{UserID: recipient.ID, MessagePrivacy: models.MessagePrivacyCollaborators})
+	assert.ErrorIs(t, services.CanMessage(db, sender.ID, recipient), services.ErrMessagingNotAllowed)
+
+	// a shared project makes them collaborators, whichever side owns it
+	project := models.Project{Title: "Shared", OwnerID: sender.ID}
+	db.Create(&project)
+	db.Create(&models.Collaborator{ProjectID: project.ID, UserID: recipient.ID, Role: "programmer"})
+	assert.True(t, services.SharesProject(db, recipient.ID, sender.ID))
+	assert.NoError(t, services.CanMessage(db, sender.ID, recipient))
+
+	db.Model(&models.UserProfile{}).Where("user_id = ?", recipient.ID).Update("message_privacy", models.MessagePrivacyNobody)
+	assert.ErrorIs(t, services.CanMessage(db, sender.ID, recipient), services.ErrMessagingNotAllowed)
+
+	// blocks apply in both directions
+	db.Model(&models.UserProfile{}).Where("user_id = ?", recipient.ID).Update("message_privacy", models.MessagePrivacyEveryone)
+	db.Create(&models.UserBlock{BlockerID: sender.ID, BlockedID: recipient.ID})
+	assert.ErrorIs(t, services.CanMessage(db, sender.ID, recipient), services.ErrMessagingBlocked)
+	db.Exec("DELETE FROM user_blocks")
+
+	now := time.Now()
+	recipient.DisabledAt = &now
+	assert.ErrorIs(t, services.CanMessage(db, sender.ID, recipient), services.ErrMessagingNotAllowed)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Notify records a notification for its recipient and returns it, or nil if the recipient isn't notified.
+// Users are never notified about their own actions. Pass the result to PublishNotifications once committed.
+func Notify(db *gorm.DB, notification models.Notification) (*models.Notification, error) {
+	if notification.UserID == 0 {
+		return nil, nil
+	}
+	if notification.ActorID != nil && *notification.ActorID == notification.UserID {
+		return nil, nil
+	}
+	if err := db.Create(&notification).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// PublishNotifications pushes notifications to their recipients' connected clients; anyone who misses one replays it
+// by ID. Only publish committed notifications: clients skip IDs they've seen, and a rolled back ID can be reused.
+func PublishNotifications(notifications ...*models.Notification) {
+	for _, notification := range notifications {
+		if notification != nil {
+			Events.Publish(notification.UserID, Event{ID: notification.ID, Type: EventNotification, Data: *notification})
+		}
+	}
+}
+
+// NotifyInvitationReceived notifies the invited user, if they already have an account
+func NotifyInvitationReceived(db *gorm.DB, invitation models.Invitation, project models.Project) (*models.Notification, error) {
+	var invitee models.User
+	if err := db.Where("email = ?", invitation.Email).First(&invitee).Error; err != nil {
+		return nil, nil
+	}
+
+	return Notify(db, models.Notification{
+		UserID:    invitee.ID,
+		ActorID:   &invitation.InviterID,
+		ProjectID: &project.ID,
+		Type:      models.NotificationInvitationReceived,
+		Message:   fmt.Sprintf("You were invited to join %q as %s", project.Title, invitation.Role),
+		Link:      "/projects/invitations",
+	})
+}
+
+// NotifyInvitationResponse notifies the inviter that an invitation was accepted or rejected
+func NotifyInvitationResponse(db *gorm.DB, invitation models.Invitation, project models.Project, inviteeID uint) (*models.Notification, error) {
+	notificationType := models.NotificationInvitationRejected
+	verb := "declined"
+	if invitation.Status == models.InvitationStatusAccepted {
+		notificationType = models.NotificationInvitationAccepted
+		verb = "accepted"
+	}
+
+	return Notify(db, models.Notification{
+		UserID:    invitation.InviterID,
+		ActorID:   &inviteeID,
+		ProjectID: &project.ID,
+		Type:      notificationType,
+		Message:   fmt.Sprintf("%s %s your invitation to %q", invitation.Email, verb, project.Title),
+		Link:      fmt.Sprintf("/projects/%d", project.ID),
+	})
+}
+
+// NotifyRoleChanged notifies a collaborator that their role on a project changed
+func NotifyRoleChanged(db *gorm.DB, project models.Project, userID, actorID uint, role models.CollaboratorRole) (*models.Notification, error) {
+	return Notify(db, models.Notification{
+		UserID:    userID,
+		ActorID:   &actorID,
+		ProjectID: &project.ID,
+		Type:      models.NotificationRoleChanged,
+		Message:   fmt.Sprintf("Your role on %q is now %s", project.Title, role),
+		Link:      fmt.Sprintf("/projects/%d", project.ID),
+	})
+}
+
+// NotifyMentions notifies every mentioned user about a discussion comment
+func NotifyMentions(db *gorm.DB, project models.Project, comment models.Comment, users []models.User) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+	for _, user := range users {
+		notification, err := Notify(db, models.Notification{
+			UserID:    user.ID,
+			ActorID:   &comment.AuthorID,
+			ProjectID: &project.ID,
+			Type:      models.NotificationMention,
+			Message:   fmt.Sprintf("You were mentioned in a discussion on %q", project.Title),
+			Link:      fmt.Sprintf("/projects/%d/discussions/%d", project.ID, comment.ThreadID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+// NotifyTaskAssigned notifies the assignee of a task
+func NotifyTaskAssigned(db *gorm.DB, project models.Project, task models.Task, actorID uint) (*models.Notification, error) {
+	if task.AssigneeID == nil {
+		return nil, nil
+	}
+
+	return Notify(db, models.Notification{
+		UserID:    *task.AssigneeID,
+		ActorID:   &actorID,
+		ProjectID: &project.ID,
+		Type:      models.NotificationTaskAssigned,
+		Message:   fmt.Sprintf("You were assigned %q on %q", task.Title, project.Title),
+		Link:      fmt.Sprintf("/projects/%d/tasks/%d", project.ID, task.ID),
+	})
+}
+
+// NotifyReportResolved tells a reporter what came of their report
+func NotifyReportResolved(db *gorm.DB, report models.Report, targetDescription string) (*models.Notification, error) {
+	outcome := "no action was taken"
+	switch report.Resolution {
+	case models.ReportResolutionContentHidden:
+		outcome = "the content has been removed"
+	case models.ReportResolutionAuthorSuspended:
+		outcome = "the author's account has been suspended"
+	}
+
+	return Notify(db, models.Notification{
+		UserID:  report.ReporterID,
+		Type:    models.NotificationReportResolved,
+		Message: fmt.Sprintf("Your report about %s was reviewed and %s", targetDescription, outcome),
+		Link:    "/reports",
+	})
+}
@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestNotificationsPublishOnlyOnceCommitted(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:notifications?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.Notification{})
+	db.Exec("DELETE FROM notifications")
+
+	subscription := services.Events.Subscribe(42)
+	defer services.Events.Unsubscribe(subscription)
+
+	// a notification recorded in a rolled back transaction never reaches the client
+	errRollback := errors.New("rollback")
+	var notification *models.Notification
+	err = db.Transaction(func(tx *gorm.DB) error {
+		notification, err = services.Notify(tx, models.Notification{UserID: 42, Type: models.NotificationMention, Message: "rolled back"})
+		assert.NoError(t, err)
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	assert.NotNil(t, notification)
+	assert.Len(t, subscription.Events, 0)
+
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// users aren't notified about their own actions
+	actor := uint(42)
+	notification, err = services.Notify(db, models.Notification{UserID: 42, ActorID: &actor, Type: models.NotificationMention})
+	assert.NoError(t, err)
+	assert.Nil(t, notification)
+
+	notification, err = services.Notify(db, models.Notification{UserID: 42, Type: models.NotificationMention, Message: "committed"})
+	assert.NoError(t, err)
+	services.PublishNotifications(nil, notification)
+	event := <-subscription.Events
+	assert.Equal(t, notification.ID, event.ID)
+	assert.Equal(t, services.EventNotification, event.Type)
+}
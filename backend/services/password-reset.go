@@ -0,0 +1,109 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"backend/email"
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// how long an emailed password reset link stays valid
+const PasswordResetTokenTTL = 24 * time.Hour
+
+// ErrInvalidResetToken is returned for unknown, expired or already used reset tokens
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+// hashToken returns the stored form of an emailed single use token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssuePasswordReset creates a reset token for a user, replacing any earlier unused ones
+func IssuePasswordReset(db *gorm.DB, userID uint, now time.Time) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	if err := db.Where("user_id = ? AND used_at IS NULL", userID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+		return "", err
+	}
+	err = db.Create(&models.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(PasswordResetTokenTTL),
+	}).Error
+	return token, err
+}
+
+// RedeemPasswordReset marks a reset token as used and returns the user it was issued to
+func RedeemPasswordReset(db *gorm.DB, token string, now time.Time) (uint, error) {
+	var reset models.PasswordResetToken
+	err := db.Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", hashToken(token), now).First(&reset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrInvalidResetToken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// guard against the same token being redeemed concurrently
+	result := db.Model(&models.PasswordResetToken{}).Where("id = ? AND used_at IS NULL", reset.ID).Update("used_at", now)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrInvalidResetToken
+	}
+	return reset.UserID, nil
+}
+
+// SetPassword stores a new password hash and signs out every session that logged in with the previous password
+// except keepSessionID, which may be zero. Outstanding reset links stop working as well.
+func SetPassword(db *gorm.DB, userID uint, hashedPassword string, keepSessionID uint, now time.Time) error {
+	// token issue times are whole seconds, so tokens issued later in the same second stay valid
+	changedAt := now.Truncate(time.Second)
+	return errors.Join(
+		db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":                hashedPassword,
+			"password_reset_required": false,
+			"password_changed_at":     changedAt,
+		}).Error,
+		db.Where("user_id = ? AND used_at IS NULL", userID).Delete(&models.PasswordResetToken{}).Error,
+		EndOtherSessions(db, userID, keepSessionID, now),
+	)
+}
+
+// SendPasswordChangedEmail tells the user their password was changed, in case it wasn't them
+func SendPasswordChangedEmail(user models.User) error {
+	text := "The password of your account was just changed and every other session was signed out.\n\n" +
+		"If you didn't change it, reset your password right away or contact an administrator.\n"
+
+	return email.Sender.Send(email.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Text:    text,
+	})
+}
+
+// SendPasswordResetEmail emails a reset link to the user. reason is the opening sentence explaining why a reset is required.
+func SendPasswordResetEmail(user models.User, token, reason string) error {
+	link := utils.GetEnv("APP_BASE_URL", "http://localhost:4200") + "/reset-password?token=" + url.QueryEscape(token)
+	text := fmt.Sprintf("%s\n\n"+
+		"Open the link below to set a new password. It is valid for %d hours.\n\n%s\n\n"+
+		"You won't be able to log in until your password has been reset.\n", reason, int(PasswordResetTokenTTL.Hours()), link)
+
+	return email.Sender.Send(email.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Text:    text,
+	})
+}
@@ -0,0 +1,43 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestPasswordResetTokens(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:password_resets?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.PasswordResetToken{})
+	db.Exec("DELETE FROM password_reset_tokens")
+
+	now := time.Now()
+	first, err := services.IssuePasswordReset(db, 7, now)
+	assert.NoError(t, err)
+	second, err := services.IssuePasswordReset(db, 7, now)
+	assert.NoError(t, err)
+
+	// issuing a new token invalidates the previous one
+	_, err = services.RedeemPasswordReset(db, first, now)
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+
+	// tokens expire
+	_, err = services.RedeemPasswordReset(db, second, now.Add(services.PasswordResetTokenTTL+time.Minute))
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+
+	userID, err := services.RedeemPasswordReset(db, second, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), userID)
+
+	_, err = services.RedeemPasswordReset(db, second, now.Add(time.Hour))
+	assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"errors"
+	"slices"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownRole is returned when assigning a role that is neither built in nor defined by the project
+	ErrUnknownRole = errors.New("role is not defined for this project")
+	// ErrRoleEscalation is returned when granting a role with capabilities the granting user doesn't hold
+	ErrRoleEscalation = errors.New("cannot grant capabilities you don't hold")
+	// ErrRoleInUse is returned when deleting a custom role that collaborators or pending invitations still hold
+	ErrRoleInUse = errors.New("role is still assigned")
+)
+
+// ProjectAccess is the role a user holds on a project and the capabilities it grants them
+type ProjectAccess struct {
+	// empty if the user doesn't collaborate on the project
+	Role         models.CollaboratorRole
+	Capabilities []models.Capability
+	// status of the user's collaborator record, empty if they only collaborate through the project's group
+	Status models.CollaboratorStatus
+}
+
+// IsMember reports whether the user collaborates on the project in any role
+func (a ProjectAccess) IsMember() bool {
+	return a.Role != ""
+}
+
+// Can reports whether the user holds the capability
+func (a ProjectAccess) Can(capability models.Capability) bool {
+	return slices.Contains(a.Capabilities, capability)
+}
+
+// CanAll reports whether the user holds every one of the capabilities
+func (a ProjectAccess) CanAll(capabilities []models.Capability) bool {
+	for _, capability := range capabilities {
+		if !a.Can(capability) {
+			return false
+		}
+	}
+	return true
+}
+
+// RoleCapabilities returns the capabilities a built-in role or one of the project's custom roles grants.
+// Returns ErrUnknownRole if the project defines no such role.
+func RoleCapabilities(db *gorm.DB, projectID uint, role models.CollaboratorRole) ([]models.Capability, error) {
+	if role.IsBuiltIn() {
+		return role.BuiltInCapabilities(), nil
+	}
+	var custom models.ProjectRole
+	if err := db.Where("project_id = ? AND name = ?", projectID, string(role)).First(&custom).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnknownRole
+	} else if err != nil {
+		return nil, err
+	}
+	return custom.GetCapabilities(), nil
+}
+
+// NormalizeCapabilities orders capabilities like the capability matrix and drops duplicates. Viewing the
+// project is always included since every collaborator can see what they work on.
+func NormalizeCapabilities(capabilities []models.Capability) []models.Capability {
+	normalized := []models.Capability{}
+	for _, capability := range models.Capabilities {
+		if capability == models.CapabilityView || slices.Contains(capabilities, capability) {
+			normalized = append(normalized, capability)
+		}
+	}
+	return normalized
+}
+
+// ProjectAccessOf resolves the role and capabilities a user holds on a project. The project owner holds every
+// capability. Members of the group owning the project hold the capabilities of the role they inherit from the
+// group on top of those of their own role. Alumni keep their role but can only view the project.
+func ProjectAccessOf(db *gorm.DB, project models.Project, userID uint) ProjectAccess {
+	if project.OwnerID == userID {
+		return ProjectAccess{
+			Role: models.CollaboratorRoleOwner, Capabilities: models.CollaboratorRoleOwner.BuiltInCapabilities(), Status: models.CollaboratorStatusActive,
+		}
+	}
+
+	var access ProjectAccess
+	var collaborator models.Collaborator
+	if err := db.Where("project_id = ? AND user_id = ?", project.ID, userID).First(&collaborator).Error; err == nil {
+		access.Role = models.CollaboratorRole(collaborator.Role)
+		access.Status = collaborator.Status
+		if collaborator.Status.IsCurrent() {
+			// a role that no longer resolves grants nothing beyond membership
+			access.Capabilities, _ = RoleCapabilities(db, project.ID, access.Role)
+		}
+	}
+	if project.GroupID != nil {
+		if inherited := GroupProjectRole(db, *project.GroupID, userID); inherited != "" {
+			if inherited.AtLeast(access.Role) {
+				access.Role = inherited
+			}
+			access.Capabilities = append(access.Capabilities, inherited.BuiltInCapabilities()...)
+		}
+	}
+	if access.Role != "" {
+		access.Capabilities = NormalizeCapabilities(access.Capabilities)
+	}
+	return access
+}
+
+// CheckCanGrantRole verifies that a role can be assigned on the project by a user with the given access: the
+// role must exist, and the user must hold every capability it grants so roles can't be used to escalate
+func CheckCanGrantRole(db *gorm.DB, projectID uint, granter ProjectAccess, role models.CollaboratorRole) error {
+	capabilities, err := RoleCapabilities(db, projectID, role)
+	if err != nil {
+		return err
+	}
+	if !granter.CanAll(capabilities) {
+		return ErrRoleEscalation
+	}
+	return nil
+}
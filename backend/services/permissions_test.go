@@ -0,0 +1,70 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestProjectAccessOf(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:permissions?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.User{}, &models.UserProfile{}, &models.Group{}, &models.GroupMember{}, &models.Project{}, &models.Collaborator{}, &models.ProjectRole{})
+	for _, table := range []string{"project_roles", "collaborators", "group_members", "groups", "projects"} {
+		db.Exec("DELETE FROM " + table)
+	}
+
+	group := models.Group{Name: "Systems Lab", DefaultProjectRole: models.CollaboratorRoleProgrammer}
+	db.Create(&group)
+	project := models.Project{Title: "Kernel", OwnerID: 1, GroupID: &group.ID}
+	db.Create(&project)
+	reviewer := models.ProjectRole{ProjectID: project.ID, Name: "reviewer"}
+	reviewer.SetCapabilities([]models.Capability{models.CapabilityView, models.CapabilityComment, models.CapabilityManageMilestones})
+	db.Create(&reviewer)
+	db.Create(&models.Collaborator{ProjectID: project.ID, UserID: 2, Role: "editor"})
+	db.Create(&models.Collaborator{ProjectID: project.ID, UserID: 3, Role: "reviewer"})
+	db.Create(&models.Collaborator{ProjectID: project.ID, UserID: 4, Role: "reviewer"})
+	db.Create(&models.GroupMember{GroupID: group.ID, UserID: 4, Role: models.GroupRoleMember})
+
+	owner := services.ProjectAccessOf(db, project, 1)
+	assert.Equal(t, models.CollaboratorRoleOwner, owner.Role)
+	assert.True(t, owner.CanAll(models.Capabilities))
+
+	editor := services.ProjectAccessOf(db, project, 2)
+	assert.True(t, editor.Can(models.CapabilityDeleteTasks))
+	assert.False(t, editor.Can(models.CapabilityInvite))
+
+	custom := services.ProjectAccessOf(db, project, 3)
+	assert.Equal(t, models.CollaboratorRole("reviewer"), custom.Role)
+	assert.True(t, custom.Can(models.CapabilityManageMilestones))
+	assert.False(t, custom.Can(models.CapabilityManageTasks))
+
+	// group members hold the capabilities of their own role and of the one they inherit
+	both := services.ProjectAccessOf(db, project, 4)
+	assert.True(t, both.Can(models.CapabilityManageMilestones))
+	assert.True(t, both.Can(models.CapabilityManageTasks))
+
+	// alumni can only read, unless the group still grants them more
+	db.Create(&models.Collaborator{ProjectID: project.ID, UserID: 6, Role: "editor", Status: models.CollaboratorStatusAlumni})
+	alumni := services.ProjectAccessOf(db, project, 6)
+	assert.Equal(t, models.CollaboratorRoleEditor, alumni.Role)
+	assert.Equal(t, []models.Capability{models.CapabilityView}, alumni.Capabilities)
+	db.Create(&models.GroupMember{GroupID: group.ID, UserID: 6, Role: models.GroupRoleMember})
+	assert.True(t, services.ProjectAccessOf(db, project, 6).Can(models.CapabilityManageTasks))
+
+	outsider := services.ProjectAccessOf(db, project, 5)
+	assert.False(t, outsider.IsMember())
+	assert.False(t, outsider.Can(models.CapabilityView))
+
+	assert.ErrorIs(t, services.CheckCanGrantRole(db, project.ID, custom, models.CollaboratorRoleEditor), services.ErrRoleEscalation)
+	assert.NoError(t, services.CheckCanGrantRole(db, project.ID, editor, "reviewer"))
+	assert.NoError(t, services.CheckCanGrantRole(db, project.ID, owner, "reviewer"))
+	assert.ErrorIs(t, services.CheckCanGrantRole(db, project.ID, owner, "ghost"), services.ErrUnknownRole)
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// purpose of signed unsubscribe tokens
+const unsubscribePurpose = "unsubscribe"
+
+// UnsubscribeAll is used in place of a notification type to turn off every email
+const UnsubscribeAll = "all"
+
+// EmailPreferences returns how often a user is emailed about each notification type, falling back to defaults
+func EmailPreferences(db *gorm.DB, userID uint) (map[models.NotificationType]models.EmailFrequency, error) {
+	preferences := make(map[models.NotificationType]models.EmailFrequency, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = models.DefaultEmailFrequency(notificationType)
+	}
+
+	var stored []models.NotificationPreference
+	if err := db.Where("user_id = ?", userID).Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	for _, preference := range stored {
+		preferences[preference.Type] = preference.Frequency
+	}
+	return preferences, nil
+}
+
+// SetEmailPreference stores how often a user is emailed about a notification type
+func SetEmailPreference(db *gorm.DB, userID uint, notificationType models.NotificationType, frequency models.EmailFrequency) error {
+	preference := models.NotificationPreference{UserID: userID, Type: notificationType, Frequency: frequency}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"frequency", "updated_at", "deleted_at"}),
+	}).Create(&preference).Error
+}
+
+// UnsubscribeToken returns a token that turns off emails of one notification type, or of all types
+// when notificationType is UnsubscribeAll, without requiring the user to log in
+func UnsubscribeToken(userID uint, notificationType string) string {
+	return utils.SignValue(unsubscribePurpose, fmt.Sprintf("%d:%s", userID, notificationType))
+}
+
+// ParseUnsubscribeToken verifies a token created by UnsubscribeToken
+func ParseUnsubscribeToken(token string) (userID uint, notificationType string, ok bool) {
+	value, ok := utils.VerifySignedValue(unsubscribePurpose, token)
+	if !ok {
+		return 0, "", false
+	}
+	id, notificationType, found := strings.Cut(value, ":")
+	parsed, err := strconv.ParseUint(id, 10, 32)
+	if !found || err != nil {
+		return 0, "", false
+	}
+	return uint(parsed), notificationType, true
+}
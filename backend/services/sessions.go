@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// sessions are marked as seen at most this often so that every request doesn't write to the database
+const sessionSeenInterval = time.Minute
+
+var (
+	// ErrSessionNotFound is returned for sessions that don't exist, belong to another user or already ended
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrTokenRevoked is returned for tokens of deleted accounts, ended sessions or issued before a password change
+	ErrTokenRevoked = errors.New("token revoked")
+	// ErrAccountDisabled is returned for tokens of accounts an administrator disabled
+	ErrAccountDisabled = errors.New("account disabled")
+	// ErrPasswordResetRequired is returned for tokens of accounts that must reset their password first
+	ErrPasswordResetRequired = errors.New("password reset required")
+)
+
+// CheckTokenAccess verifies that a token issued at issuedAt for the user, and for sessionID unless it is zero,
+// can still be used. It returns the token's session, if any, and an error when the token has been revoked since.
+func CheckTokenAccess(db *gorm.DB, userID, sessionID uint, issuedAt *time.Time, now time.Time) (models.Session, error) {
+	var user models.User
+	if err := db.Select("id", "disabled_at", "password_reset_required", "password_changed_at").First(&user, userID).Error; err != nil {
+		return models.Session{}, ErrTokenRevoked
+	}
+	// changing the password signs out every session that logged in with the old one
+	if user.PasswordChangedAt != nil && (issuedAt == nil || issuedAt.Before(*user.PasswordChangedAt)) {
+		return models.Session{}, ErrTokenRevoked
+	}
+	if user.DisabledAt != nil {
+		return models.Session{}, ErrAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return models.Session{}, ErrPasswordResetRequired
+	}
+
+	// tokens issued at login are revoked when their session ends
+	if sessionID == 0 {
+		return models.Session{}, nil
+	}
+	session, err := ActiveSession(db, userID, sessionID, now)
+	if err != nil {
+		return models.Session{}, ErrTokenRevoked
+	}
+	return session, nil
+}
+
+// StartSession records a login from a device. The session lasts as long as the token issued with it.
+func StartSession(db *gorm.DB, userID uint, userAgent, ipAddress string, now time.Time) (models.Session, error) {
+	session := models.Session{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(utils.TokenTTL),
+	}
+	err := db.Create(&session).Error
+	return session, err
+}
+
+// ActiveSession loads a session of the user that has neither ended nor expired
+func ActiveSession(db *gorm.DB, userID, sessionID uint, now time.Time) (models.Session, error) {
+	var session models.Session
+	err := db.Where("id = ? AND user_id = ? AND ended_at IS NULL AND expires_at > ?", sessionID, userID, now).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, ErrSessionNotFound
+	}
+	return session, err
+}
+
+// ActiveSessions lists the user's sessions that have neither ended nor expired, most recently used first
+func ActiveSessions(db *gorm.DB, userID uint, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := db.Where("user_id = ? AND ended_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC, id DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchSession updates when the session was last used, at most once per sessionSeenInterval
+func TouchSession(db *gorm.DB, session models.Session, now time.Time) error {
+	if now.Sub(session.LastSeenAt) < sessionSeenInterval {
+		return nil
+	}
+	return db.Model(&models.Session{}).Where("id = ?", session.ID).Update("last_seen_at", now).Error
+}
+
+// EndSession signs one of the user's devices out
+func EndSession(db *gorm.DB, userID, sessionID uint, now time.Time) error {
+	result := db.Model(&models.Session{}).Where("id = ? AND user_id = ? AND ended_at IS NULL", sessionID, userID).Update("ended_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// EndOtherSessions signs every device of the user out except the session keepID, which may be zero
+func EndOtherSessions(db *gorm.DB, userID, keepID uint, now time.Time) error {
+	return db.Model(&models.Session{}).Where("user_id = ? AND id <> ? AND ended_at IS NULL", userID, keepID).Update("ended_at", now).Error
+}
+
+// DeviceName gives a short description of the browser and operating system in a user agent, e.g. "Firefox on Windows"
+func DeviceName(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	firstMatch := func(candidates [][2]string) string {
+		for _, candidate := range candidates {
+			if strings.Contains(ua, candidate[0]) {
+				return candidate[1]
+			}
+		}
+		return ""
+	}
+
+	// order matters, e.g. Edge and Chrome user agents both mention Safari
+	browser := firstMatch([][2]string{
+		{"edg/", "Edge"}, {"opr/", "Opera"}, {"firefox/", "Firefox"}, {"chrome/", "Chrome"}, {"crios/", "Chrome"},
+		{"safari/", "Safari"}, {"curl/", "curl"}, {"postman", "Postman"},
+	})
+	system := firstMatch([][2]string{
+		{"android", "Android"}, {"iphone", "iOS"}, {"ipad", "iOS"}, {"windows", "Windows"}, {"mac os", "macOS"},
+		{"cros", "ChromeOS"}, {"linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "Unknown device"
+}
@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func TestSessions(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:sessions?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.Session{})
+	db.Exec("DELETE FROM sessions")
+
+	now := time.Now()
+	laptop, err := services.StartSession(db, 1, "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0", "10.0.0.1", now.Add(-time.Hour))
+	assert.NoError(t, err)
+	phone, _ := services.StartSession(db, 1, "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 Version/17.5 Mobile/15E148 Safari/604.1", "10.0.0.2", now)
+	other, _ := services.StartSession(db, 2, "curl/8.5.0", "10.0.0.3", now)
+
+	sessions, err := services.ActiveSessions(db, 1, now)
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 2) {
+		assert.Equal(t, phone.ID, sessions[0].ID)
+	}
+
+	// last seen is only written once per interval
+	assert.NoError(t, services.TouchSession(db, phone, now.Add(10*time.Second)))
+	assert.NoError(t, services.TouchSession(db, laptop, now))
+	var touched models.Session
+	db.First(&touched, phone.ID)
+	assert.WithinDuration(t, now, touched.LastSeenAt, time.Second)
+	db.First(&touched, laptop.ID)
+	assert.WithinDuration(t, now, touched.LastSeenAt, time.Second)
+
+	// sessions of other users can't be ended
+	assert.ErrorIs(t, services.EndSession(db, 1, other.ID, now), services.ErrSessionNotFound)
+	assert.NoError(t, services.EndSession(db, 1, laptop.ID, now))
+	assert.ErrorIs(t, services.EndSession(db, 1, laptop.ID, now), services.ErrSessionNotFound)
+	_, err = services.ActiveSession(db, 1, laptop.ID, now)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+
+	// sessions expire along with their token
+	_, err = services.ActiveSession(db, 1, phone.ID, phone.ExpiresAt.Add(time.Second))
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+
+	second, _ := services.StartSession(db, 1, "", "10.0.0.4", now)
+	assert.NoError(t, services.EndOtherSessions(db, 1, second.ID, now))
+	sessions, _ = services.ActiveSessions(db, 1, now)
+	if assert.Len(t, sessions, 1) {
+		assert.Equal(t, second.ID, sessions[0].ID)
+	}
+	_, err = services.ActiveSession(db, 2, other.ID, now)
+	assert.NoError(t, err)
+}
+
+func TestDeviceName(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0": "Edge on Windows",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36":         "Chrome on macOS",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0":                                                        "Firefox on Linux",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36":         "Chrome on Android",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 Version/17.5 Mobile/15E148 Safari/604.1":           "Safari on iOS",
+		"curl/8.5.0": "curl",
+		"":           "Unknown device",
+	}
+	for userAgent, expected := range tests {
+		assert.Equal(t, expected, services.DeviceName(userAgent), userAgent)
+	}
+}
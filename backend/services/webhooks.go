@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// header carrying the hex encoded HMAC-SHA256 of the request body, keyed with the webhook secret
+	WebhookSignatureHeader = "X-Grid-Signature-256"
+	WebhookEventHeader     = "X-Grid-Event"
+	WebhookDeliveryHeader  = "X-Grid-Delivery"
+
+	// longest response body kept in the delivery log
+	maxLoggedResponse = 4 << 10
+)
+
+// WebhookPayload is the JSON document posted to webhooks
+type WebhookPayload struct {
+	Event     models.WebhookEvent `json:"event"`
+	ProjectID uint                `json:"project_id"`
+	CreatedAt time.Time           `json:"created_at"`
+	Data      interface{}         `json:"data"`
+}
+
+// EnqueueWebhookEvent queues a delivery of an event to every active webhook of a project subscribed to it.
+// Pass the transaction that performs the change so deliveries only exist for committed changes.
+func EnqueueWebhookEvent(db *gorm.DB, projectID uint, event models.WebhookEvent, data interface{}) error {
+	var webhooks []models.Webhook
+	if err := db.Where("project_id = ? AND active = ?", projectID, true).Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(WebhookPayload{Event: event, ProjectID: projectID, CreatedAt: now, Data: data})
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if !slices.Contains(webhook.GetEvents(), event) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the value of the signature header for a payload
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookWorker posts queued webhook deliveries, retrying failures with exponential backoff
+type WebhookWorker struct {
+	DB          *gorm.DB
+	Client      *http.Client
+	MaxAttempts int
+	BaseBackoff time.Duration // delay before the first retry, doubled for each one after it
+}
+
+// NewWebhookWorker creates a worker configured from the environment.
+// Unless WEBHOOK_ALLOW_PRIVATE_NETWORKS is set, webhooks can't reach loopback or private addresses.
+func NewWebhookWorker(db *gorm.DB) *WebhookWorker {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if utils.GetEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") != "true" {
+		dialer.Control = rejectPrivateAddresses
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &WebhookWorker{
+		DB: db,
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			// redirects could lead to addresses that weren't checked
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		MaxAttempts: int(utils.GetEnvInt64("WEBHOOK_MAX_ATTEMPTS", 6)),
+		BaseBackoff: 30 * time.Second,
+	}
+}
+
+// rejectPrivateAddresses stops connections to internal addresses, checked after DNS resolution
+func rejectPrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("webhook address %s is not allowed", host)
+	}
+	return nil
+}
+
+// Run delivers due webhooks every interval until ctx is cancelled
+func (w *WebhookWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.DeliverDue(ctx, time.Now()); err != nil {
+				log.Println("Failed to deliver webhooks:", err)
+			}
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due at now
+func (w *WebhookWorker) DeliverDue(ctx context.Context, now time.Time) error {
+	var deliveries []models.WebhookDelivery
+	err := w.DB.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("id").
+		Find(&deliveries).Error
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		var webhook models.Webhook
+		if err := w.DB.First(&webhook, delivery.WebhookID).Error; err != nil {
+			// the webhook was deleted after the event was queued
+			w.DB.Model(&delivery).Updates(map[string]interface{}{"status": models.WebhookDeliveryFailed, "error": "webhook deleted", "next_attempt_at": nil})
+			continue
+		}
+		if err := w.attempt(ctx, webhook, &delivery, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt posts a delivery once and records the outcome, scheduling a retry when it failed
+func (w *WebhookWorker) attempt(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery, now time.Time) error {
+	responseStatus, responseBody, sendErr := w.post(ctx, webhook, *delivery)
+
+	delivery.Attempts++
+	delivery.ResponseStatus = responseStatus
+	delivery.ResponseBody = responseBody
+	delivery.Error = ""
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	}
+
+	switch {
+	case sendErr == nil && responseStatus >= 200 && responseStatus < 300:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= w.MaxAttempts:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		next := now.Add(w.BaseBackoff << (delivery.Attempts - 1))
+		delivery.NextAttemptAt = &next
+	}
+
+	return w.DB.Save(delivery).Error
+}
+
+func (w *WebhookWorker) post(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery) (int, string, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TheGrid-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, payload))
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedResponse))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return resp.StatusCode, "", err
+	}
+	return resp.StatusCode, string(body), nil
+}
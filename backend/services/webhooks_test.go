@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"backend/models"
+	"backend/services"
+)
+
+func setupWebhookTest(t *testing.T, url string, events ...models.WebhookEvent) (*gorm.DB, models.Webhook) {
+	db, err := gorm.Open(sqlite.Open("file:webhooks?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	db.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{})
+	db.Exec("DELETE FROM webhook_deliveries")
+	db.Exec("DELETE FROM webhooks")
+
+	webhook := models.Webhook{ProjectID: 1, CreatorID: 1, URL: url, Secret: "s3cret", Active: true}
+	webhook.SetEvents(events)
+	db.Create(&webhook)
+	return db, webhook
+}
+
+func TestWebhookDeliverySigned(t *testing.T) {
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, services.SignWebhookPayload("s3cret", body), r.Header.Get(services.WebhookSignatureHeader))
+		assert.Equal(t, "task.created", r.Header.Get(services.WebhookEventHeader))
+		received.Store(body)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	db, _ := setupWebhookTest(t, server.URL, models.WebhookEventTaskCreated)
+	assert.NoError(t, services.EnqueueWebhookEvent(db, 1, models.WebhookEventTaskCreated, map[string]string{"title": "Review"}))
+	// events the webhook isn't subscribed to are not queued
+	assert.NoError(t, services.EnqueueWebhookEvent(db, 1, models.WebhookEventStatusChanged, nil))
+	// neither are events of other projects
+	assert.NoError(t, services.EnqueueWebhookEvent(db, 2, models.WebhookEventTaskCreated, nil))
+
+	worker := &services.WebhookWorker{DB: db, Client: server.Client(), MaxAttempts: 3, BaseBackoff: time.Minute}
+	assert.NoError(t, worker.DeliverDue(context.Background(), time.Now()))
+
+	var deliveries []models.WebhookDelivery
+	db.Find(&deliveries)
+	assert.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookDeliverySucceeded, deliveries[0].Status)
+	assert.Equal(t, 200, deliveries[0].ResponseStatus)
+	assert.Equal(t, "ok", deliveries[0].ResponseBody)
+
+	var payload services.WebhookPayload
+	assert.NoError(t, json.Unmarshal(received.Load().([]byte), &payload))
+	assert.Equal(t, models.WebhookEventTaskCreated, payload.Event)
+	assert.Equal(t, uint(1), payload.ProjectID)
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	db, _ := setupWebhookTest(t, server.URL, models.WebhookEventTaskCreated)
+	assert.NoError(t, services.EnqueueWebhookEvent(db, 1, models.WebhookEventTaskCreated, nil))
+
+	worker := &services.WebhookWorker{DB: db, Client: server.Client(), MaxAttempts: 3, BaseBackoff: time.Minute}
+	start := time.Now()
+
+	// first attempt fails and is retried after one backoff period
+	assert.NoError(t, worker.DeliverDue(context.Background(), start))
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 503, delivery.ResponseStatus)
+	assert.WithinDuration(t, start.Add(time.Minute), *delivery.NextAttemptAt, time.Second)
+
+	// nothing is attempted before the retry is due
+	assert.NoError(t, worker.DeliverDue(context.Background(), start.Add(30*time.Second)))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the backoff doubles
+	assert.NoError(t, worker.DeliverDue(context.Background(), start.Add(time.Minute)))
+	db.First(&delivery)
+	assert.WithinDuration(t, start.Add(3*time.Minute), *delivery.NextAttemptAt, time.Second)
+
+	// the delivery fails for good after the last attempt
+	assert.NoError(t, worker.DeliverDue(context.Background(), start.Add(3*time.Minute)))
+	delivery = models.WebhookDelivery{}
+	db.First(&delivery)
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWebhookRejectsPrivateAddresses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	db, _ := setupWebhookTest(t, server.URL, models.WebhookEventTaskCreated)
+	assert.NoError(t, services.EnqueueWebhookEvent(db, 1, models.WebhookEventTaskCreated, nil))
+
+	assert.NoError(t, services.NewWebhookWorker(db).DeliverDue(context.Background(), time.Now()))
+
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	assert.Contains(t, delivery.Error, "not allowed")
+	assert.Equal(t, int32(0), calls.Load())
+}
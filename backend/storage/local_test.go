@@ -0,0 +1,51 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/storage"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Put and Get", func(t *testing.T) {
+		err := store.Put(ctx, "avatars/1/128.jpg", strings.NewReader("image data"), 10, "image/jpeg")
+		assert.NoError(t, err)
+
+		reader, info, err := store.Get(ctx, "avatars/1/128.jpg")
+		assert.NoError(t, err)
+		defer reader.Close()
+
+		data, _ := io.ReadAll(reader)
+		assert.Equal(t, "image data", string(data))
+		assert.Equal(t, int64(10), info.Size)
+		assert.Equal(t, "image/jpeg", info.ContentType)
+	})
+
+	t.Run("Missing blob", func(t *testing.T) {
+		_, _, err := store.Get(ctx, "avatars/2/128.jpg")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, "avatars/1/128.jpg"))
+		_, _, err := store.Get(ctx, "avatars/1/128.jpg")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+
+		// deleting twice is not an error
+		assert.NoError(t, store.Delete(ctx, "avatars/1/128.jpg"))
+	})
+
+	t.Run("Path traversal is rejected", func(t *testing.T) {
+		err := store.Put(ctx, "../outside.txt", strings.NewReader("x"), 1, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMinIO is a minimal in-memory stand-in for an S3-compatible server.
+// It only supports path-style PUT, GET and DELETE of objects and verifies request signatures.
+type fakeMinIO struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	types     map[string]string
+	secretKey string
+}
+
+func (f *fakeMinIO) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.validSignature(r) {
+		http.Error(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		// like S3, check signed payloads against their hash
+		if hash := r.Header.Get("x-amz-content-sha256"); hash != "UNSIGNED-PAYLOAD" {
+			sum := sha256.Sum256(data)
+			if hash != hex.EncodeToString(sum[:]) {
+				http.Error(w, "<Error><Code>XAmzContentSHA256Mismatch</Code></Error>", http.StatusBadRequest)
+				return
+			}
+		}
+		f.objects[r.URL.Path] = data
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[r.URL.Path])
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// validSignature re-signs the incoming request and compares the Authorization headers
+func (f *fakeMinIO) validSignature(r *http.Request) bool {
+	signedAt, err := time.Parse("20060102T150405Z", r.Header.Get("x-amz-date"))
+	if err != nil {
+		return false
+	}
+	clone := r.Clone(context.Background())
+	clone.URL.Host = r.Host
+	signV4(clone, r.Header.Get("x-amz-content-sha256"), "minioadmin", f.secretKey, "us-east-1", "s3", signedAt)
+	return clone.Header.Get("Authorization") == r.Header.Get("Authorization")
+}
+
+func newFakeMinIO(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(&fakeMinIO{
+		objects:   make(map[string][]byte),
+		types:     make(map[string]string),
+		secretKey: "minioadmin-secret",
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSignV4KnownVector(t *testing.T) {
+	// "get-vanilla" case from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	signedAt := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	signV4(req, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", signedAt)
+
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
+
+func TestS3Store(t *testing.T) {
+	server := newFakeMinIO(t)
+	store, err := NewS3Store(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "grid",
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin-secret",
+	})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("Put and Get", func(t *testing.T) {
+		err := store.Put(ctx, "avatars/1/128.jpg", strings.NewReader("image data"), 10, "image/jpeg")
+		assert.NoError(t, err)
+
+		reader, info, err := store.Get(ctx, "avatars/1/128.jpg")
+		assert.NoError(t, err)
+		defer reader.Close()
+
+		data, _ := io.ReadAll(reader)
+		assert.Equal(t, "image data", string(data))
+		assert.Equal(t, "image/jpeg", info.ContentType)
+		assert.False(t, info.LastModified.IsZero())
+	})
+
+	t.Run("Put streams exactly size bytes", func(t *testing.T) {
+		// uploads aren't buffered to hash them, the reader is sent as it is read
+		err := store.Put(ctx, "projects/1/attachments/large", io.MultiReader(strings.NewReader(strings.Repeat("x", 1<<20)), strings.NewReader("trailing")), 1<<20, "")
+		assert.NoError(t, err)
+
+		reader, _, err := store.Get(ctx, "projects/1/attachments/large")
+		assert.NoError(t, err)
+		defer reader.Close()
+		data, _ := io.ReadAll(reader)
+		assert.Equal(t, strings.Repeat("x", 1<<20), string(data))
+
+		assert.NoError(t, store.Put(ctx, "projects/1/attachments/empty", strings.NewReader(""), 0, ""))
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		_, _, err := store.Get(ctx, "avatars/2/128.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, store.Delete(ctx, "avatars/1/128.jpg"))
+		_, _, err := store.Get(ctx, "avatars/1/128.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Wrong credentials are rejected", func(t *testing.T) {
+		badStore, _ := NewS3Store(S3Config{
+			Endpoint:  server.URL,
+			Bucket:    "grid",
+			AccessKey: "minioadmin",
+			SecretKey: "wrong-secret",
+		})
+		err := badStore.Put(ctx, "avatars/1/128.jpg", strings.NewReader("x"), 1, "")
+		assert.Error(t, err)
+	})
+}
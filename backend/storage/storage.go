@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// ErrNotFound is returned when a blob does not exist in the store
+var ErrNotFound = errors.New("blob not found")
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// BlobStore abstracts the backend used to persist uploaded files
+type BlobStore interface {
+	// Put stores the content read from r under key, replacing any existing blob
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key; callers must close the returned reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	// Delete removes the blob stored under key; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// global blob store instance
+var Store BlobStore
+
+// initialize the blob store from environment configuration
+func InitStorage() {
+	switch os.Getenv("STORAGE_BACKEND") {
+	case "s3":
+		store, err := NewS3Store(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    getEnv("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+		if err != nil {
+			log.Fatal("Failed to configure S3 storage: ", err)
+		}
+		Store = store
+	default:
+		store, err := NewLocalStore(getEnv("STORAGE_LOCAL_DIR", "uploads"))
+		if err != nil {
+			log.Fatal("Failed to configure local storage: ", err)
+		}
+		Store = store
+	}
+}
+
+// getEnv returns the value of an environment variable or a fallback
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+
 	verifier "github.com/AfterShip/email-verifier"
-	"golang.org/x/crypto/bcrypt"
     "github.com/gin-gonic/gin"
 )
 
@@ -10,18 +12,6 @@ func IsEmailValid(email string) bool {
 	return verifier.IsAddressValid(email)
 }
 
-// hash a plaintext password
-func HashPassword(password string) (string, error) {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(hashed), err
-}
-
-// compare a hashed password with a plaintext password
-func CheckPassword(hashedPassword, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
-}
-
 // InferUserID extracts the authenticated user's ID from the Gin context
 // Returns 0 if no user ID is found (indicating no authentication)
 func InferUserID(c *gin.Context) uint {
@@ -39,3 +29,12 @@ func InferUserID(c *gin.Context) uint {
 	
 	return id
 }
+
+// GenerateSecureToken returns a random hex encoded token of n bytes
+func GenerateSecureToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
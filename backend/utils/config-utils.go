@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnv returns the value of an environment variable or fallback when it is unset
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvInt64 returns an environment variable parsed as an integer, or fallback when it is unset or invalid
+func GetEnvInt64(key string, fallback int64) int64 {
+	value, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
@@ -4,4 +4,6 @@ package utils
 const (
     UserIDKey = "userID"
     UserEmailKey = "userEmail"
+    SessionIDKey = "sessionID"
+    TokenIssuedAtKey = "tokenIssuedAt"
 )
\ No newline at end of file
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine is a single line of a line-based diff
+type DiffLine struct {
+	Op   DiffOp `json:"op" example:"insert"`
+	Text string `json:"text"`
+}
+
+// MaxDiffChanges is the most lines a diff may insert and delete in total. The cost of a diff grows with the
+// number of changes, so diffs between unrelated texts are refused rather than computed.
+const MaxDiffChanges = 2000
+
+// ErrDiffTooLarge is returned when two texts differ by more than MaxDiffChanges lines
+var ErrDiffTooLarge = errors.New("texts differ too much to compare")
+
+// DiffLines computes a minimal line-based diff turning before into after using Myers' algorithm
+// in linear space. Returns ErrDiffTooLarge if it would take more than MaxDiffChanges insertions and deletions.
+func DiffLines(before, after string) ([]DiffLine, error) {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// the shortest edit script's length bounds the work, and searching for the first middle snake measures it
+	prefix, suffix := commonEnds(a, b)
+	changedA, changedB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(changedA) > 0 && len(changedB) > 0 {
+		if _, _, _, _, ok := middleSnake(changedA, changedB, MaxDiffChanges); !ok {
+			return nil, ErrDiffTooLarge
+		}
+	} else if len(changedA)+len(changedB) > MaxDiffChanges {
+		return nil, ErrDiffTooLarge
+	}
+	return diffMiddle(make([]DiffLine, 0, len(a)+len(b)), a, b), nil
+}
+
+// diffMiddle appends the diff of two line slices, splitting what changed around its middle snake
+func diffMiddle(diff []DiffLine, a, b []string) []DiffLine {
+	prefix, suffix := commonEnds(a, b)
+	for _, line := range a[:prefix] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+
+	changedA, changedB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	switch {
+	case len(changedA) == 0:
+		for _, line := range changedB {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+	case len(changedB) == 0:
+		for _, line := range changedA {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+	default:
+		x, y, u, v, _ := middleSnake(changedA, changedB, len(changedA)+len(changedB))
+		diff = diffMiddle(diff, changedA[:x], changedB[:y])
+		for _, line := range changedA[x:u] {
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+		}
+		diff = diffMiddle(diff, changedA[u:], changedB[v:])
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return diff
+}
+
+// middleSnake finds the run of equal lines (x, y) to (u, v) in the middle of a shortest edit script turning a
+// into b by searching forwards from the start and backwards from the end at once. It gives up once the script
+// would be longer than maxChanges. Neither a nor b may be empty.
+func middleSnake(a, b []string, maxChanges int) (x, y, u, v int, ok bool) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	rounds := min((n+m+1)/2, (maxChanges+1)/2)
+
+	// forward[k] and backward[k] hold the furthest x reached on diagonal k = x - y, counting x from the end of a
+	// for the backward search. Diagonals range over [-rounds, rounds] plus one on either side.
+	offset := rounds + 1
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+	for d := 0; d <= rounds; d++ {
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y = x - k
+			startX, startY := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			// the backward search has run d-1 rounds, reaching diagonals within that distance of its own start
+			if reverse := delta - k; odd && reverse >= -(d-1) && reverse <= d-1 && x+backward[offset+reverse] >= n {
+				if 2*d-1 > maxChanges {
+					return 0, 0, 0, 0, false
+				}
+				return startX, startY, x, y, true
+			}
+		}
+		for k := -d; k <= d; k += 2 {
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y = x - k
+			startX, startY := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			if reverse := delta - k; !odd && reverse >= -d && reverse <= d && x+forward[offset+reverse] >= n {
+				if 2*d > maxChanges {
+					return 0, 0, 0, 0, false
+				}
+				return n - x, m - y, n - startX, m - startY, true
+			}
+		}
+	}
+	return 0, 0, 0, 0, false
+}
+
+// commonEnds counts the lines a and b share at their start and, after those, at their end
+func commonEnds(a, b []string) (prefix, suffix int) {
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
+
+// splitLines splits text into lines, treating an empty string as no lines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,120 @@
+package utils_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	before := "intro\nold line\nshared\nend\n"
+	after := "intro\nshared\nnew line\nend\n"
+
+	assert.Equal(t, []utils.DiffLine{
+		{Op: utils.DiffEqual, Text: "intro"},
+		{Op: utils.DiffDelete, Text: "old line"},
+		{Op: utils.DiffEqual, Text: "shared"},
+		{Op: utils.DiffInsert, Text: "new line"},
+		{Op: utils.DiffEqual, Text: "end"},
+	}, mustDiff(t, before, after))
+}
+
+func TestDiffLinesFromEmpty(t *testing.T) {
+	assert.Equal(t, []utils.DiffLine{
+		{Op: utils.DiffInsert, Text: "first"},
+		{Op: utils.DiffInsert, Text: "second"},
+	}, mustDiff(t, "", "first\nsecond"))
+
+	// a trailing newline alone is not a change
+	assert.Equal(t, []utils.DiffLine{{Op: utils.DiffEqual, Text: "same"}}, mustDiff(t, "same", "same\n"))
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for i := 0; i < 500; i++ {
+		before, after := randomText(), randomText()
+		diff, err := utils.DiffLines(before, after)
+		assert.NoError(t, err)
+
+		// replaying the diff rebuilds both texts, and it keeps as many lines as their longest common subsequence
+		var rebuiltBefore, rebuiltAfter []string
+		equal := 0
+		for _, line := range diff {
+			if line.Op != utils.DiffInsert {
+				rebuiltBefore = append(rebuiltBefore, line.Text)
+			}
+			if line.Op != utils.DiffDelete {
+				rebuiltAfter = append(rebuiltAfter, line.Text)
+			}
+			if line.Op == utils.DiffEqual {
+				equal++
+			}
+		}
+		assert.Equal(t, before, strings.Join(rebuiltBefore, "\n"))
+		assert.Equal(t, after, strings.Join(rebuiltAfter, "\n"))
+		assert.Equal(t, longestCommonSubsequence(before, after), equal, "diff of %q and %q", before, after)
+	}
+}
+
+func TestDiffLinesTooLarge(t *testing.T) {
+	lines := func(prefix string, count int) string {
+		text := make([]string, count)
+		for i := range text {
+			text[i] = fmt.Sprintf("%s %d", prefix, i)
+		}
+		return strings.Join(text, "\n")
+	}
+
+	// long texts with few changes are still compared
+	diff, err := utils.DiffLines(lines("line", 50000), lines("line", 50000)+"\nappended")
+	assert.NoError(t, err)
+	assert.Len(t, diff, 50001)
+
+	_, err = utils.DiffLines(lines("before", 50000), lines("after", 50000))
+	assert.ErrorIs(t, err, utils.ErrDiffTooLarge)
+	_, err = utils.DiffLines("", lines("after", utils.MaxDiffChanges+1))
+	assert.ErrorIs(t, err, utils.ErrDiffTooLarge)
+}
+
+func longestCommonSubsequence(before, after string) int {
+	a, b := strings.Split(before, "\n"), strings.Split(after, "\n")
+	if before == "" {
+		a = nil
+	}
+	if after == "" {
+		b = nil
+	}
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+func mustDiff(t *testing.T, before, after string) []utils.DiffLine {
+	diff, err := utils.DiffLines(before, after)
+	assert.NoError(t, err)
+	return diff
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+
+	// register decoders for the accepted upload formats
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Square thumbnail sizes (in pixels) generated for every avatar upload
+var AvatarSizes = []int{64, 128, 256}
+
+// AvatarBlobKey returns the storage key of a single avatar thumbnail
+func AvatarBlobKey(prefix string, size int) string {
+	return fmt.Sprintf("%s/%d.jpg", prefix, size)
+}
+
+const (
+	// MaxAvatarUploadSize is the largest accepted avatar upload in bytes
+	MaxAvatarUploadSize = 5 << 20
+	// maxImageDimension bounds the decoded width/height to guard against decompression bombs
+	maxImageDimension = 6000
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrImageTooLarge    = errors.New("image dimensions are too large")
+)
+
+// accepted image content types, detected from file contents rather than the client's header
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// SniffImageType detects the content type of data and reports whether it is an accepted image format
+func SniffImageType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	return contentType, allowedImageTypes[contentType]
+}
+
+// GenerateAvatarThumbnails decodes an uploaded image, crops it to a centered square
+// and re-encodes it as JPEG in every size listed in AvatarSizes.
+// Re-encoding also strips any metadata (EXIF, comments) embedded in the original file.
+func GenerateAvatarThumbnails(data []byte) (map[int][]byte, error) {
+	if _, ok := SniffImageType(data); !ok {
+		return nil, ErrUnsupportedImage
+	}
+
+	// check dimensions before decoding the full image
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width > maxImageDimension || config.Height > maxImageDimension {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	square := centerSquare(src.Bounds())
+
+	thumbnails := make(map[int][]byte, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		// paint a white background so transparent images don't turn black in JPEG
+		draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, square, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+
+	return thumbnails, nil
+}
+
+// centerSquare returns the largest square centered within bounds
+func centerSquare(bounds image.Rectangle) image.Rectangle {
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x0, y0, x0+side, y0+side)
+}
@@ -0,0 +1,61 @@
+package utils_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestSniffImageType(t *testing.T) {
+	contentType, ok := utils.SniffImageType(encodeTestPNG(t, 4, 4))
+	assert.True(t, ok)
+	assert.Equal(t, "image/png", contentType)
+
+	_, ok = utils.SniffImageType([]byte("<html><body>not an image</body></html>"))
+	assert.False(t, ok, "HTML must not be accepted as an image")
+}
+
+func TestGenerateAvatarThumbnails(t *testing.T) {
+	thumbnails, err := utils.GenerateAvatarThumbnails(encodeTestPNG(t, 300, 200))
+	assert.NoError(t, err)
+	assert.Len(t, thumbnails, len(utils.AvatarSizes))
+
+	for _, size := range utils.AvatarSizes {
+		img, err := jpeg.Decode(bytes.NewReader(thumbnails[size]))
+		assert.NoError(t, err, "thumbnail should be a valid JPEG")
+		assert.Equal(t, size, img.Bounds().Dx())
+		assert.Equal(t, size, img.Bounds().Dy())
+	}
+}
+
+func TestGenerateAvatarThumbnailsRejectsInvalidInput(t *testing.T) {
+	_, err := utils.GenerateAvatarThumbnails([]byte("plain text"))
+	assert.ErrorIs(t, err, utils.ErrUnsupportedImage)
+
+	// a valid PNG header with a truncated body
+	truncated := encodeTestPNG(t, 50, 50)[:60]
+	_, err = utils.GenerateAvatarThumbnails(truncated)
+	assert.Error(t, err)
+
+	_, err = utils.GenerateAvatarThumbnails(encodeTestPNG(t, 6001, 1))
+	assert.ErrorIs(t, err, utils.ErrImageTooLarge)
+}
@@ -15,9 +15,14 @@ var jwtKey = []byte(getJWTKey())
 type Claims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
+	// login session the token belongs to; zero for tokens that are not tied to a session
+	SessionID uint `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// TokenTTL is how long issued tokens stay valid
+const TokenTTL = 24 * time.Hour
+
 // GetJWTKey returns the JWT signing key
 func GetJWTKey() []byte {
 	return jwtKey
@@ -35,15 +40,21 @@ func getJWTKey() string {
 
 // GenerateJWT creates a new JWT token for a user
 func GenerateJWT(userID uint, email string) (string, error) {
+	return GenerateSessionJWT(userID, email, 0)
+}
+
+// GenerateSessionJWT creates a new JWT token for a user that is revoked when the login session ends
+func GenerateSessionJWT(userID uint, email string, sessionID uint) (string, error) {
 	if userID == 0 || email == "" {
 		return "", fmt.Errorf("invalid input: userID and email are required")
 	}
 
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 1 day expiration
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenTTL)), // 1 day expiration
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// user generated content policy: allows formatting, links and images but strips scripts, styles and event handlers
+	htmlPolicy = bluemonday.UGCPolicy()
+)
+
+// RenderMarkdown converts user supplied markdown to sanitized HTML that is safe to embed in a page
+func RenderMarkdown(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return htmlPolicy.Sanitize(buf.String()), nil
+}
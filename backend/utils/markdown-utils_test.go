@@ -0,0 +1,25 @@
+package utils_test
+
+import (
+	"testing"
+
+	"backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	html, err := utils.RenderMarkdown("# Title\n\nSome **bold** text and a [link](https://example.com).")
+	assert.NoError(t, err)
+	assert.Contains(t, html, "<h1>Title</h1>")
+	assert.Contains(t, html, "<strong>bold</strong>")
+	assert.Contains(t, html, `href="https://example.com"`)
+}
+
+func TestRenderMarkdownSanitizesHTML(t *testing.T) {
+	html, err := utils.RenderMarkdown("<script>alert('xss')</script>\n\n[click](javascript:alert(1))\n\n<img src=x onerror=alert(1)>")
+	assert.NoError(t, err)
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "javascript:")
+	assert.NotContains(t, html, "onerror")
+}
@@ -0,0 +1,19 @@
+package utils_test
+
+import (
+	"testing"
+
+	"backend/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMentions(t *testing.T) {
+	text := "Thanks @Jane.Doe@ufl.edu! cc @bob@cs.example.org and @jane.doe@ufl.edu again."
+	assert.Equal(t, []string{"jane.doe@ufl.edu", "bob@cs.example.org"}, utils.ExtractMentions(text))
+}
+
+func TestExtractMentionsIgnoresPlainEmails(t *testing.T) {
+	// an address without a leading @ is not a mention
+	assert.Empty(t, utils.ExtractMentions("Write to jane@ufl.edu or @nobody"))
+}
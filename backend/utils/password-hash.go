@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported password hash algorithms, selected with PASSWORD_HASH_ALGORITHM
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var errInvalidPasswordHash = errors.New("invalid password hash")
+
+// PasswordHashParams are the cost parameters used for new password hashes
+type PasswordHashParams struct {
+	Algorithm string
+	// argon2id memory in KiB, passes over the memory and threads
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	BcryptCost  int
+}
+
+// CurrentPasswordHashParams returns the configured hash parameters. The argon2id defaults follow the OWASP recommendation.
+func CurrentPasswordHashParams() PasswordHashParams {
+	params := PasswordHashParams{
+		Algorithm:   strings.ToLower(GetEnv("PASSWORD_HASH_ALGORITHM", HashArgon2id)),
+		Memory:      uint32(GetEnvInt64("ARGON2_MEMORY_KIB", 19456)),
+		Iterations:  uint32(GetEnvInt64("ARGON2_ITERATIONS", 2)),
+		Parallelism: uint8(GetEnvInt64("ARGON2_PARALLELISM", 1)),
+		BcryptCost:  int(GetEnvInt64("BCRYPT_COST", int64(bcrypt.DefaultCost))),
+	}
+	if params.Algorithm != HashBcrypt {
+		params.Algorithm = HashArgon2id
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		params.Memory, params.Iterations, params.Parallelism = 19456, 2, 1
+	}
+	if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+		params.BcryptCost = bcrypt.DefaultCost
+	}
+	return params
+}
+
+// HashPassword hashes a plaintext password with the current parameters.
+// argon2id hashes use the PHC string format, e.g. $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>,
+// so the algorithm and parameters are stored alongside every hash.
+func HashPassword(password string) (string, error) {
+	params := CurrentPasswordHashParams()
+	if params.Algorithm == HashBcrypt {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), params.BcryptCost)
+		return string(hashed), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword compares a hashed password in any supported format with a plaintext password
+func CheckPassword(hashedPassword, password string) bool {
+	if !strings.HasPrefix(hashedPassword, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hashedPassword)
+	if err != nil {
+		return false
+	}
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, candidate) == 1
+}
+
+// PasswordNeedsRehash reports whether a hash was made with a different algorithm or different parameters than the current ones
+func PasswordNeedsRehash(hashedPassword string) bool {
+	current := CurrentPasswordHashParams()
+	if !strings.HasPrefix(hashedPassword, "$argon2id$") {
+		if current.Algorithm != HashBcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err != nil || cost != current.BcryptCost
+	}
+
+	if current.Algorithm != HashArgon2id {
+		return true
+	}
+	params, _, key, err := decodeArgon2Hash(hashedPassword)
+	return err != nil || len(key) != argon2KeyLength ||
+		params.Memory != current.Memory || params.Iterations != current.Iterations || params.Parallelism != current.Parallelism
+}
+
+// decodeArgon2Hash splits a PHC formatted argon2id hash into its parameters, salt and key
+func decodeArgon2Hash(hashedPassword string) (PasswordHashParams, []byte, []byte, error) {
+	params := PasswordHashParams{Algorithm: HashArgon2id}
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil || params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidPasswordHash
+	}
+	return params, salt, key, nil
+}
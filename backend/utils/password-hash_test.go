@@ -0,0 +1,60 @@
+package utils_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/utils"
+)
+
+func TestHashPasswordArgon2id(t *testing.T) {
+	hashed, err := utils.HashPassword("correct horse battery staple")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=19456,t=2,p=1$"), hashed)
+	assert.True(t, utils.CheckPassword(hashed, "correct horse battery staple"))
+	assert.False(t, utils.CheckPassword(hashed, "correct horse battery"))
+	assert.False(t, utils.PasswordNeedsRehash(hashed))
+
+	// salts are random
+	again, _ := utils.HashPassword("correct horse battery staple")
+	assert.NotEqual(t, hashed, again)
+}
+
+func TestHashPasswordBcrypt(t *testing.T) {
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	t.Setenv("BCRYPT_COST", "5")
+
+	hashed, err := utils.HashPassword("correct horse battery staple")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashed, "$2a$05$"), hashed)
+	assert.True(t, utils.CheckPassword(hashed, "correct horse battery staple"))
+	assert.False(t, utils.PasswordNeedsRehash(hashed))
+
+	t.Setenv("BCRYPT_COST", "6")
+	assert.True(t, utils.PasswordNeedsRehash(hashed))
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "argon2id")
+	assert.True(t, utils.PasswordNeedsRehash(hashed))
+	// older hashes keep verifying after the switch
+	assert.True(t, utils.CheckPassword(hashed, "correct horse battery staple"))
+}
+
+func TestPasswordNeedsRehashArgon2Params(t *testing.T) {
+	hashed, _ := utils.HashPassword("correct horse battery staple")
+
+	t.Setenv("ARGON2_ITERATIONS", "3")
+	assert.True(t, utils.PasswordNeedsRehash(hashed))
+	assert.True(t, utils.CheckPassword(hashed, "correct horse battery staple"))
+
+	upgraded, _ := utils.HashPassword("correct horse battery staple")
+	assert.Contains(t, upgraded, "m=19456,t=3,p=1")
+	assert.False(t, utils.PasswordNeedsRehash(upgraded))
+}
+
+func TestCheckPasswordMalformedHash(t *testing.T) {
+	for _, hashed := range []string{"", "plaintext", "$argon2id$v=19$m=0,t=0,p=0$c2FsdA$a2V5", "$argon2id$v=18$m=19456,t=2,p=1$c2FsdA$a2V5", "$argon2id$v=19$m=19456,t=2,p=1$!!$a2V5"} {
+		assert.False(t, utils.CheckPassword(hashed, "anything"), hashed)
+		assert.True(t, utils.PasswordNeedsRehash(hashed), hashed)
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// bcrypt only uses the first 72 bytes of a password
+const maxBcryptPasswordBytes = 72
+
+//go:embed data/breached-passwords.txt
+var builtinBreachedPasswords string
+
+var (
+	breachedPasswords     map[string]bool
+	loadBreachedPasswords sync.Once
+)
+
+// ErrBreachedPassword is returned for passwords found in the breached password list
+var ErrBreachedPassword = errors.New("this password has appeared in a data breach, choose a different one")
+
+// PasswordMinLength returns the minimum password length, configured with PASSWORD_MIN_LENGTH
+func PasswordMinLength() int {
+	return int(GetEnvInt64("PASSWORD_MIN_LENGTH", 8))
+}
+
+// PasswordMaxLength returns the maximum password length, configured with PASSWORD_MAX_LENGTH
+func PasswordMaxLength() int {
+	return int(GetEnvInt64("PASSWORD_MAX_LENGTH", 128))
+}
+
+// isBreachedPassword checks a password against the built-in list of common passwords and, when
+// BREACHED_PASSWORDS_FILE is set, a local file with one password per line
+func isBreachedPassword(password string) bool {
+	loadBreachedPasswords.Do(func() {
+		breachedPasswords = make(map[string]bool)
+		addPasswords := func(scanner *bufio.Scanner) {
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if line != "" && !strings.HasPrefix(line, "#") {
+					breachedPasswords[strings.ToLower(line)] = true
+				}
+			}
+		}
+		addPasswords(bufio.NewScanner(strings.NewReader(builtinBreachedPasswords)))
+
+		if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+			file, err := os.Open(path)
+			if err != nil {
+				log.Println("Failed to open breached passwords file:", err)
+				return
+			}
+			defer file.Close()
+			addPasswords(bufio.NewScanner(file))
+		}
+	})
+	return breachedPasswords[strings.ToLower(password)]
+}
+
+// ValidatePassword checks a new password against the password policy. The returned error is meant to be shown to the user.
+func ValidatePassword(password, email string) error {
+	if minLength := PasswordMinLength(); len([]rune(password)) < minLength {
+		return fmt.Errorf("password must be at least %d characters long", minLength)
+	}
+	if maxLength := PasswordMaxLength(); len([]rune(password)) > maxLength {
+		return fmt.Errorf("password must be at most %d characters long", maxLength)
+	}
+	if CurrentPasswordHashParams().Algorithm == HashBcrypt && len(password) > maxBcryptPasswordBytes {
+		return fmt.Errorf("password must be at most %d bytes long", maxBcryptPasswordBytes)
+	}
+	if email != "" && strings.EqualFold(password, email) {
+		return errors.New("password must not be your email address")
+	}
+	if isBreachedPassword(password) {
+		return ErrBreachedPassword
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/utils"
+)
+
+func TestValidatePassword(t *testing.T) {
+	// extra breached passwords are read once, before the first check
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	os.WriteFile(path, []byte("# leaked\ncorrect horse battery staple\n"), 0o600)
+	t.Setenv("BREACHED_PASSWORDS_FILE", path)
+
+	assert.NoError(t, utils.ValidatePassword("a long unusual passphrase", "jane@example.com"))
+
+	assert.ErrorContains(t, utils.ValidatePassword("short", ""), "at least 8 characters")
+	assert.ErrorIs(t, utils.ValidatePassword("Password123", ""), utils.ErrBreachedPassword)
+	assert.ErrorIs(t, utils.ValidatePassword("Correct Horse Battery Staple", ""), utils.ErrBreachedPassword)
+	assert.Error(t, utils.ValidatePassword("Jane@Example.com", "jane@example.com"))
+	assert.ErrorContains(t, utils.ValidatePassword(strings.Repeat("long ", 26), ""), "at most 128 characters")
+
+	// bcrypt ignores everything after 72 bytes, argon2id doesn't
+	assert.NoError(t, utils.ValidatePassword(strings.Repeat("long ", 15), ""))
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "bcrypt")
+	assert.ErrorContains(t, utils.ValidatePassword(strings.Repeat("long ", 15), ""), "at most 72 bytes")
+
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	assert.ErrorContains(t, utils.ValidatePassword("ten chars!", ""), "at least 12 characters")
+	t.Setenv("PASSWORD_MAX_LENGTH", "20")
+	assert.ErrorContains(t, utils.ValidatePassword("a long unusual passphrase", ""), "at most 20 characters")
+}
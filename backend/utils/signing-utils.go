@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// SignValue returns a URL safe token carrying value, signed for a single purpose with the JWT key.
+// Tokens don't expire; embed a timestamp in value when they should.
+func SignValue(purpose, value string) string {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(valueSignature(purpose, encoded))
+}
+
+// VerifySignedValue checks a token created by SignValue for the same purpose and returns its value
+func VerifySignedValue(purpose, token string) (string, bool) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return "", false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, valueSignature(purpose, encoded)) {
+		return "", false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	return string(value), true
+}
+
+func valueSignature(purpose, encoded string) []byte {
+	mac := hmac.New(sha256.New, GetJWTKey())
+	mac.Write([]byte(purpose + ":" + encoded))
+	return mac.Sum(nil)
+}
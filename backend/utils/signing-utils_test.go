@@ -0,0 +1,29 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend/utils"
+)
+
+func TestSignedValues(t *testing.T) {
+	token := utils.SignValue("unsubscribe", "42:mention")
+
+	value, ok := utils.VerifySignedValue("unsubscribe", token)
+	assert.True(t, ok)
+	assert.Equal(t, "42:mention", value)
+
+	// tokens are bound to their purpose
+	_, ok = utils.VerifySignedValue("reset", token)
+	assert.False(t, ok)
+
+	// tampering with the value invalidates the signature
+	forged := utils.SignValue("unsubscribe", "43:mention")
+	_, ok = utils.VerifySignedValue("unsubscribe", forged[:len(forged)-43]+token[len(token)-43:])
+	assert.False(t, ok)
+
+	_, ok = utils.VerifySignedValue("unsubscribe", "garbage")
+	assert.False(t, ok)
+}